@@ -0,0 +1,70 @@
+// requirements-merge 是按requirement合并requirements文件的git合并驱动
+//
+// 用法:
+//
+//	requirements-merge [-policy conflict|higher|ours|theirs] [-marker-size N] [-diff3] BASE OURS THEIRS
+//
+// 合并结果写回OURS。没有冲突时以0退出，有冲突时以1退出，出错时以2退出。
+//
+// 在.git/config中注册驱动，并在.gitattributes中为requirements文件启用:
+//
+//	[merge "requirements"]
+//	    name = requirements.txt three-way merge
+//	    driver = requirements-merge -policy higher -marker-size %L %O %A %B
+//
+//	requirements*.txt merge=requirements
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/scagogogo/python-requirements-parser/pkg/merge"
+)
+
+// policies -policy参数的取值
+var policies = map[string]merge.VersionPolicy{
+	"conflict": merge.VersionConflict,
+	"higher":   merge.VersionHigher,
+	"ours":     merge.VersionOurs,
+	"theirs":   merge.VersionTheirs,
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	flags := flag.NewFlagSet("requirements-merge", flag.ContinueOnError)
+	policy := flags.String("policy", "conflict", "双方修改同一个包的版本时的处理方式: conflict、higher、ours或theirs")
+	markerSize := flags.Int("marker-size", merge.DefaultMarkerSize, "冲突标记的长度（git的%L）")
+	diff3 := flags.Bool("diff3", false, "在冲突中写出base的内容")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	versionPolicy, ok := policies[*policy]
+	if !ok || flags.NArg() != 3 {
+		fmt.Fprintln(os.Stderr, "用法: requirements-merge [-policy conflict|higher|ours|theirs] [-marker-size N] [-diff3] BASE OURS THEIRS")
+		return 2
+	}
+
+	result, err := merge.MergeFiles(flags.Arg(0), flags.Arg(1), flags.Arg(2), &merge.Options{
+		VersionPolicy: versionPolicy,
+		MarkerSize:    *markerSize,
+		Diff3:         *diff3,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "requirements-merge:", err)
+		return 2
+	}
+
+	for _, c := range result.Conflicts {
+		fmt.Fprintf(os.Stderr, "requirements-merge: 冲突 %s (第%d行)\n", c.Package, c.Line)
+	}
+	if !result.Clean() {
+		return 1
+	}
+	return 0
+}
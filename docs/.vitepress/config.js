@@ -59,7 +59,13 @@ export default defineConfig({
             { text: 'Overview', link: '/api/' },
             { text: 'Parser', link: '/api/parser' },
             { text: 'Models', link: '/api/models' },
-            { text: 'Editors', link: '/api/editors' }
+            { text: 'Editors', link: '/api/editors' },
+            { text: 'Hashes', link: '/api/hashes' },
+            { text: 'Validator', link: '/api/validator' },
+            { text: 'Diff', link: '/api/diff' },
+            { text: 'Compare', link: '/api/compare' },
+            { text: 'Merge', link: '/api/merge' },
+            { text: 'Format', link: '/api/format' }
           ]
         }
       ],
@@ -0,0 +1,377 @@
+// Package compare 比较两组requirements的语义差异
+//
+// 与逐行的文本差异不同，compare按PEP 503规范化的包名匹配两边的依赖，
+// 报告新增、删除的包，以及每个包的版本约束、extras、环境标记、来源和哈希的变化。
+// 只改变格式的修改（空白、引号风格、子句顺序、包名大小写、注释、行的顺序等）不会被报告。
+//
+// 结果可以直接序列化为JSON，也可以用Markdown生成发布说明或PR评论。
+//
+// 示例:
+//
+//	result, err := compare.CompareFiles("old/requirements.txt", "requirements.txt")
+//	if err != nil {
+//	    // 处理错误
+//	}
+//	fmt.Print(result.Markdown())
+package compare
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/scagogogo/python-requirements-parser/pkg/models"
+)
+
+// Kind 包的变化类型
+type Kind string
+
+const (
+	// Added 只在新的一组中出现的包
+	Added Kind = "added"
+
+	// Removed 只在旧的一组中出现的包
+	Removed Kind = "removed"
+
+	// Changed 两边都有但语义不同的包
+	Changed Kind = "changed"
+)
+
+// ValueChange 一个值的变化，不存在的一侧为空字符串
+type ValueChange struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// SetChange 一个集合的变化
+type SetChange struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// PackageChange 一个包的变化
+//
+// 对于Added和Removed，只设置有值的字段，例如没有版本约束的新增包Version为nil。
+// 对于Changed，只设置发生变化的字段。
+type PackageChange struct {
+	// Name 规范化的包名；没有包名的URL或本地路径依赖为其URL或路径
+	Name string `json:"name"`
+
+	// Kind 变化类型
+	Kind Kind `json:"kind"`
+
+	// Version 版本约束的变化
+	Version *ValueChange `json:"version,omitempty"`
+
+	// Extras extras的变化，值为规范化的名称
+	Extras *SetChange `json:"extras,omitempty"`
+
+	// Markers 环境标记的变化
+	Markers *ValueChange `json:"markers,omitempty"`
+
+	// Source 来源的变化，来自包索引时为"index"，否则为URL或本地路径（可编辑安装带"-e "前缀）
+	Source *ValueChange `json:"source,omitempty"`
+
+	// Hashes 哈希的变化，值为小写的"算法:摘要"
+	Hashes *SetChange `json:"hashes,omitempty"`
+
+	// Before、After 两边的requirement，不存在的一侧为nil
+	Before *models.Requirement `json:"-"`
+	After  *models.Requirement `json:"-"`
+}
+
+// Result 两组requirements的比较结果
+type Result struct {
+	// Changes 按包名排序的变化，两组相同时为空
+	// 同一个包出现多次时，每一行的变化分别列出
+	Changes []PackageChange `json:"changes"`
+}
+
+// Equal 检查两组requirements在语义上是否相同
+func (r *Result) Equal() bool {
+	return len(r.Changes) == 0
+}
+
+// Filter 返回指定类型的变化
+func (r *Result) Filter(kind Kind) []PackageChange {
+	var changes []PackageChange
+	for _, change := range r.Changes {
+		if change.Kind == kind {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// Compare 比较两组解析后的requirements
+//
+// 只比较要安装的依赖，注释、空行、-r、-c和全局选项被忽略。
+// 同一个包出现多次时（如按环境标记拆分的多行），每一行分别匹配和比较，
+// 匹配规则见pair，此时结果中同一个包名可以对应多个变化。
+//
+// 参数:
+//   - before: 旧的一组requirements
+//   - after: 新的一组requirements
+//
+// 返回:
+//   - *Result: 按包名排序的变化
+//
+// 示例:
+//
+//	p := parser.New()
+//	before, _ := p.ParseString("Flask==1.0.0\nrequests\n")
+//	after, _ := p.ParseString("flask == 2.0.1\nhttpx\n")
+//	result := compare.Compare(before, after)
+//	// flask: changed, version ==1.0.0 -> ==2.0.1
+//	// httpx: added
+//	// requests: removed
+func Compare(before, after []*models.Requirement) *Result {
+	oldSet, newSet := index(before), index(after)
+
+	names := make([]string, 0, len(oldSet)+len(newSet))
+	for name := range oldSet {
+		names = append(names, name)
+	}
+	for name := range newSet {
+		if _, ok := oldSet[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	result := &Result{Changes: []PackageChange{}}
+	for _, name := range names {
+		for _, p := range pair(oldSet[name], newSet[name]) {
+			if change := comparePackage(name, p[0], p[1]); change != nil {
+				result.Changes = append(result.Changes, *change)
+			}
+		}
+	}
+	return result
+}
+
+// index 按包名建立依赖的索引，同一个包的多个requirement按出现顺序排列
+func index(reqs []*models.Requirement) map[string][]*models.Requirement {
+	set := make(map[string][]*models.Requirement)
+	for _, req := range reqs {
+		if req == nil || !req.IsInstallable() {
+			continue
+		}
+		name := key(req)
+		set[name] = append(set[name], req)
+	}
+	return set
+}
+
+// pair 将同一个包在两边的requirement一一配对，没有对应项的一侧为nil
+//
+// 先配对规范化后环境标记相同的requirement，其余的按出现顺序配对，
+// 因此按环境标记拆分的多行（如不同平台的pywin32）各自比较，
+// 只出现一次的包即使环境标记改变也会配对为Changed。
+// 结果按旧的一组的顺序排列，之后是只在新的一组中出现的requirement。
+func pair(before, after []*models.Requirement) [][2]*models.Requirement {
+	matched := make([]int, len(before))
+	for i := range matched {
+		matched[i] = -1
+	}
+	used := make([]bool, len(after))
+
+	for i, old := range before {
+		for j, req := range after {
+			if !used[j] && normalizedMarkers(old) == normalizedMarkers(req) {
+				matched[i], used[j] = j, true
+				break
+			}
+		}
+	}
+	next := 0
+	for i := range before {
+		if matched[i] != -1 {
+			continue
+		}
+		for next < len(after) && used[next] {
+			next++
+		}
+		if next < len(after) {
+			matched[i], used[next] = next, true
+		}
+	}
+
+	pairs := make([][2]*models.Requirement, 0, len(before)+len(after))
+	for i, old := range before {
+		var req *models.Requirement
+		if matched[i] != -1 {
+			req = after[matched[i]]
+		}
+		pairs = append(pairs, [2]*models.Requirement{old, req})
+	}
+	for j, req := range after {
+		if !used[j] {
+			pairs = append(pairs, [2]*models.Requirement{nil, req})
+		}
+	}
+	return pairs
+}
+
+// key 返回requirement的匹配键：规范化的包名，没有包名时为本地路径或URL
+func key(req *models.Requirement) string {
+	switch {
+	case req.Name != "":
+		return models.NormalizeName(req.Name)
+	case req.IsLocalPath && req.LocalPath != "":
+		return normalizePath(req.LocalPath)
+	}
+	return req.URL
+}
+
+// comparePackage 比较同一个包的两个requirement，没有语义变化时返回nil
+func comparePackage(name string, before, after *models.Requirement) *PackageChange {
+	change := &PackageChange{Name: name, Before: before, After: after}
+
+	switch {
+	case before == nil:
+		change.Kind = Added
+	case after == nil:
+		change.Kind = Removed
+	default:
+		change.Kind = Changed
+	}
+
+	if models.NormalizeSpecifier(version(before)) != models.NormalizeSpecifier(version(after)) {
+		change.Version = &ValueChange{Old: version(before), New: version(after)}
+	}
+	if normalizedMarkers(before) != normalizedMarkers(after) {
+		change.Markers = &ValueChange{Old: markers(before), New: markers(after)}
+	}
+	change.Extras = diffSets(extras(before), extras(after))
+	change.Hashes = diffSets(hashes(before), hashes(after))
+
+	oldSource, newSource := source(before), source(after)
+	if change.Kind == Changed {
+		if oldSource != newSource {
+			change.Source = &ValueChange{Old: displaySource(oldSource), New: displaySource(newSource)}
+		}
+	} else if oldSource != "" || newSource != "" {
+		change.Source = &ValueChange{Old: oldSource, New: newSource}
+	}
+
+	if change.Kind == Changed && change.Version == nil && change.Markers == nil &&
+		change.Extras == nil && change.Hashes == nil && change.Source == nil {
+		return nil
+	}
+	return change
+}
+
+// version 返回版本约束，去掉首尾空白
+func version(req *models.Requirement) string {
+	if req == nil {
+		return ""
+	}
+	return strings.TrimSpace(req.Version)
+}
+
+// markers 返回环境标记，去掉首尾空白
+func markers(req *models.Requirement) string {
+	if req == nil {
+		return ""
+	}
+	return strings.TrimSpace(req.Markers)
+}
+
+// normalizedMarkers 返回规范化的环境标记
+func normalizedMarkers(req *models.Requirement) string {
+	return models.NormalizeMarker(markers(req))
+}
+
+// extras 返回规范化的extras
+func extras(req *models.Requirement) []string {
+	if req == nil {
+		return nil
+	}
+	return normalizeAll(req.Extras, models.NormalizeName)
+}
+
+// hashes 返回小写的哈希
+func hashes(req *models.Requirement) []string {
+	if req == nil {
+		return nil
+	}
+	return normalizeAll(req.Hashes, strings.ToLower)
+}
+
+// source 返回依赖的来源，来自包索引时为空字符串
+func source(req *models.Requirement) string {
+	if req == nil {
+		return ""
+	}
+
+	var src string
+	switch {
+	case req.IsLocalPath && req.LocalPath != "":
+		src = normalizePath(req.LocalPath)
+	case req.IsVCS && req.VCSType != "" && !strings.HasPrefix(req.URL, req.VCSType+"+"):
+		src = req.VCSType + "+" + req.URL
+	case req.IsVCS || req.IsURL || req.IsDirectRef || req.IsLocalPath:
+		src = req.URL
+	}
+	if src != "" && req.IsEditable {
+		src = "-e " + src
+	}
+	return src
+}
+
+// displaySource 返回用于展示的来源，包索引显示为"index"
+func displaySource(src string) string {
+	if src == "" {
+		return "index"
+	}
+	return src
+}
+
+// normalizePath 规范化本地路径，统一使用"/"分隔符并清理多余的"."和"/"
+func normalizePath(p string) string {
+	return path.Clean(strings.ReplaceAll(p, "\\", "/"))
+}
+
+// normalizeAll 规范化每一项，返回排序去重后的结果
+func normalizeAll(items []string, normalize func(string) string) []string {
+	seen := make(map[string]bool, len(items))
+	var result []string
+	for _, item := range items {
+		item = normalize(strings.TrimSpace(item))
+		if item != "" && !seen[item] {
+			seen[item] = true
+			result = append(result, item)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// diffSets 比较两个已排序去重的集合，相同时返回nil
+func diffSets(before, after []string) *SetChange {
+	inBefore := make(map[string]bool, len(before))
+	for _, item := range before {
+		inBefore[item] = true
+	}
+	inAfter := make(map[string]bool, len(after))
+	for _, item := range after {
+		inAfter[item] = true
+	}
+
+	change := &SetChange{}
+	for _, item := range after {
+		if !inBefore[item] {
+			change.Added = append(change.Added, item)
+		}
+	}
+	for _, item := range before {
+		if !inAfter[item] {
+			change.Removed = append(change.Removed, item)
+		}
+	}
+	if len(change.Added) == 0 && len(change.Removed) == 0 {
+		return nil
+	}
+	return change
+}
@@ -0,0 +1,195 @@
+package compare
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/scagogogo/python-requirements-parser/pkg/parser"
+)
+
+// compareStrings 解析两段文本并比较
+func compareStrings(t *testing.T, before, after string) *Result {
+	t.Helper()
+	p := parser.New()
+	oldReqs, err := p.ParseString(before)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	newReqs, err := p.ParseString(after)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	return Compare(oldReqs, newReqs)
+}
+
+func TestCompare_FormattingOnly(t *testing.T) {
+	before := "# deps\nFlask_Login >= 0.6 , < 1.0\ndjango[rest,auth]==4.2 ; python_version>='3.8'\n"
+	after := "django[Auth,rest] == 4.2; python_version >= \"3.8\"  # web\n\nflask-login<1.0,>=0.6\n"
+
+	result := compareStrings(t, before, after)
+	if !result.Equal() {
+		t.Errorf("只有格式差异时期望没有变化，实际: %+v", result.Changes)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   PackageChange
+	}{
+		{
+			name:   "新增",
+			before: "",
+			after:  "httpx>=0.24\n",
+			want:   PackageChange{Name: "httpx", Kind: Added, Version: &ValueChange{New: ">=0.24"}},
+		},
+		{
+			name:   "删除",
+			before: "requests[socks]\n",
+			after:  "",
+			want:   PackageChange{Name: "requests", Kind: Removed, Extras: &SetChange{Removed: []string{"socks"}}},
+		},
+		{
+			name:   "版本变化",
+			before: "Flask==1.0.0\n",
+			after:  "flask==2.0.1\n",
+			want:   PackageChange{Name: "flask", Kind: Changed, Version: &ValueChange{Old: "==1.0.0", New: "==2.0.1"}},
+		},
+		{
+			name:   "extras变化",
+			before: "django[rest,auth]==4.2\n",
+			after:  "django[rest,Admin_Tools]==4.2\n",
+			want: PackageChange{Name: "django", Kind: Changed,
+				Extras: &SetChange{Added: []string{"admin-tools"}, Removed: []string{"auth"}}},
+		},
+		{
+			name:   "环境标记变化",
+			before: "pywin32 ; os_name == 'nt'\n",
+			after:  "pywin32 ; sys_platform == 'win32'\n",
+			want: PackageChange{Name: "pywin32", Kind: Changed,
+				Markers: &ValueChange{Old: "os_name == 'nt'", New: "sys_platform == 'win32'"}},
+		},
+		{
+			name:   "从包索引改为VCS",
+			before: "mylib==1.0\n",
+			after:  "git+https://github.com/org/mylib.git@v2#egg=mylib\n",
+			want: PackageChange{Name: "mylib", Kind: Changed,
+				Version: &ValueChange{Old: "==1.0"},
+				Source:  &ValueChange{Old: "index", New: "git+https://github.com/org/mylib.git@v2"}},
+		},
+		{
+			name:   "改为可编辑安装",
+			before: "./libs/core\n",
+			after:  "-e ./libs/core/\n",
+			want: PackageChange{Name: "libs/core", Kind: Changed,
+				Source: &ValueChange{Old: "libs/core", New: "-e libs/core"}},
+		},
+		{
+			name:   "哈希变化",
+			before: "six==1.16.0 --hash=sha256:bbbb --hash=sha256:aaaa\n",
+			after:  "six==1.16.0 \\\n    --hash=sha256:aaaa \\\n    --hash=sha256:cccc\n",
+			want: PackageChange{Name: "six", Kind: Changed,
+				Hashes: &SetChange{Added: []string{"sha256:cccc"}, Removed: []string{"sha256:bbbb"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := compareStrings(t, tt.before, tt.after)
+			if len(result.Changes) != 1 {
+				t.Fatalf("期望1个变化，实际%d个: %+v", len(result.Changes), result.Changes)
+			}
+			got := result.Changes[0]
+			got.Before, got.After = nil, nil
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("变化 = %+v\n期望 %+v", describe(got), describe(tt.want))
+			}
+		})
+	}
+}
+
+func TestCompare_OrderAndFilter(t *testing.T) {
+	result := compareStrings(t,
+		"zope.interface==5.0\nflask==1.0\nrequests\nflask==0.1\n",
+		"attrs\nflask==2.0\nzope-interface==5.0\n",
+	)
+
+	var names []string
+	for _, c := range result.Changes {
+		names = append(names, c.Name+":"+string(c.Kind))
+	}
+	want := []string{"attrs:added", "flask:changed", "flask:removed", "requests:removed"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("变化 = %v, 期望 %v", names, want)
+	}
+
+	// 重复的包按出现顺序配对，多出的一行报告为删除
+	if v := result.Changes[1].Version; v == nil || v.Old != "==1.0" {
+		t.Errorf("flask的版本变化 = %+v", v)
+	}
+	if got := result.Filter(Removed); len(got) != 2 || got[0].Name != "flask" || got[0].Before.Version != "==0.1" || got[1].Name != "requests" {
+		t.Errorf("Filter(Removed) = %+v", got)
+	}
+	if result.Changes[0].After == nil || result.Changes[0].Before != nil {
+		t.Error("新增的包应只设置After")
+	}
+}
+
+func TestCompare_MarkerSplitDuplicates(t *testing.T) {
+	before := "pywin32==300 ; sys_platform == 'win32'\n" +
+		"pywin32==300 ; sys_platform == 'cygwin'\n" +
+		"numpy==1.24 ; python_version < '3.9'\n" +
+		"numpy==1.26 ; python_version >= '3.9'\n"
+	after := "numpy==1.26 ; python_version>=\"3.9\"\n" +
+		"numpy==1.24 ; python_version < '3.9'\n" +
+		"pywin32==300 ; sys_platform == 'win32'\n" +
+		"pywin32==306 ; sys_platform == 'cygwin'\n" +
+		"pywin32==306 ; sys_platform == 'darwin'\n"
+
+	result := compareStrings(t, before, after)
+
+	// numpy的两行只是顺序和引号不同；pywin32按环境标记匹配，cygwin一行的版本变化和新增的darwin一行都被报告
+	var got []map[string]interface{}
+	for _, c := range result.Changes {
+		got = append(got, describe(c))
+	}
+	want := []map[string]interface{}{
+		{"name": "pywin32", "kind": Changed, "version": ValueChange{Old: "==300", New: "==306"}},
+		{"name": "pywin32", "kind": Added, "version": ValueChange{New: "==306"}, "markers": ValueChange{New: "sys_platform == 'darwin'"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("变化 = %v\n期望 %v", got, want)
+	}
+	if c := result.Changes[0]; c.Before.Markers != "sys_platform == 'cygwin'" || c.After.Markers != "sys_platform == 'cygwin'" {
+		t.Errorf("版本变化应属于cygwin一行: %v -> %v", c.Before, c.After)
+	}
+
+	// 只有一行时即使环境标记改变也配对为Changed
+	result = compareStrings(t, "pywin32 ; sys_platform == 'win32'\n", "pywin32 ; os_name == 'nt'\n")
+	if len(result.Changes) != 1 || result.Changes[0].Kind != Changed || result.Changes[0].Markers == nil {
+		t.Errorf("环境标记变化 = %+v", result.Changes)
+	}
+}
+
+// describe 返回便于阅读的变化描述
+func describe(c PackageChange) map[string]interface{} {
+	m := map[string]interface{}{"name": c.Name, "kind": c.Kind}
+	if c.Version != nil {
+		m["version"] = *c.Version
+	}
+	if c.Extras != nil {
+		m["extras"] = *c.Extras
+	}
+	if c.Markers != nil {
+		m["markers"] = *c.Markers
+	}
+	if c.Source != nil {
+		m["source"] = *c.Source
+	}
+	if c.Hashes != nil {
+		m["hashes"] = *c.Hashes
+	}
+	return m
+}
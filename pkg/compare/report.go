@@ -0,0 +1,146 @@
+package compare
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// JSON 将比较结果序列化为缩进的JSON
+//
+// 两组相同时输出{"changes": []}。
+//
+// 示例输出:
+//
+//	{
+//	  "changes": [
+//	    {
+//	      "name": "flask",
+//	      "kind": "changed",
+//	      "version": {
+//	        "old": "==1.0.0",
+//	        "new": "==2.0.1"
+//	      }
+//	    }
+//	  ]
+//	}
+func (r *Result) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+// Markdown 将比较结果格式化为Markdown表格，用于发布说明和PR评论
+//
+// 每个字段的变化占一行；新增和删除的包各占一行，显示其requirement（不含哈希）。
+// 同一个包有多个变化时，包名后附加新的环境标记以区分各行。
+// 哈希只显示增减的数量。两组相同时返回"No dependency changes.\n"。
+//
+// 示例输出:
+//
+//	| Package | Change | Before | After |
+//	|---------|--------|--------|-------|
+//	| flask | version | `==1.0.0` | `==2.0.1` |
+//	| httpx | added | | `httpx>=0.24` |
+func (r *Result) Markdown() string {
+	if r.Equal() {
+		return "No dependency changes.\n"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("| Package | Change | Before | After |\n")
+	sb.WriteString("|---------|--------|--------|-------|\n")
+	row := func(name, change, before, after string) {
+		sb.WriteString("|")
+		for _, cell := range []string{escape(name), change, before, after} {
+			if cell != "" {
+				sb.WriteString(" " + cell)
+			}
+			sb.WriteString(" |")
+		}
+		sb.WriteString("\n")
+	}
+
+	counts := make(map[string]int)
+	for _, c := range r.Changes {
+		counts[c.Name]++
+	}
+
+	for _, c := range r.Changes {
+		switch c.Kind {
+		case Added:
+			row(c.Name, string(Added), "", code(summary(c)))
+			continue
+		case Removed:
+			row(c.Name, string(Removed), code(summary(c)), "")
+			continue
+		}
+
+		// 同一个包有多个变化时（按环境标记拆分的多行），用环境标记区分
+		name := c.Name
+		if m := markers(c.After); counts[c.Name] > 1 && m != "" {
+			name += " ; " + m
+		}
+		if c.Version != nil {
+			row(name, "version", code(c.Version.Old), code(c.Version.New))
+		}
+		if c.Extras != nil {
+			row(name, "extras", codeList(c.Extras.Removed), codeList(c.Extras.Added))
+		}
+		if c.Markers != nil {
+			row(name, "markers", code(c.Markers.Old), code(c.Markers.New))
+		}
+		if c.Source != nil {
+			row(name, "source", code(c.Source.Old), code(c.Source.New))
+		}
+		if c.Hashes != nil {
+			row(name, "hashes", countText(len(c.Hashes.Removed), "removed"), countText(len(c.Hashes.Added), "added"))
+		}
+	}
+	return sb.String()
+}
+
+// summary 返回新增或删除的包的requirement文本，不含哈希、选项和注释
+func summary(c PackageChange) string {
+	req := c.After
+	if req == nil {
+		req = c.Before
+	}
+	if req == nil {
+		return c.Name
+	}
+
+	req = req.Clone()
+	req.Hashes = nil
+	req.RequirementOptions = nil
+	req.Comment = ""
+	return req.String()
+}
+
+// code 将值格式化为Markdown行内代码，空值返回空字符串
+func code(value string) string {
+	if value == "" {
+		return ""
+	}
+	return "`" + escape(value) + "`"
+}
+
+// codeList 将多个值格式化为逗号分隔的行内代码
+func codeList(values []string) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = code(value)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// countText 返回"N added"形式的数量说明，数量为0时返回空字符串
+func countText(n int, what string) string {
+	if n == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d %s", n, what)
+}
+
+// escape 转义表格单元格中的"|"
+func escape(value string) string {
+	return strings.ReplaceAll(value, "|", `\|`)
+}
@@ -0,0 +1,66 @@
+package compare
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestResult_Markdown(t *testing.T) {
+	result := compareStrings(t,
+		"flask==1.0.0\ndjango[auth]==4.2\nrequests==2.28.0 --hash=sha256:aaaa\nsix --hash=sha256:bbbb\n",
+		"flask==2.0.1 ; python_version >= '3.8'\ndjango[rest]==4.2\nhttpx>=0.24 --hash=sha256:cccc  # new\nsix --hash=sha256:dddd --hash=sha256:eeee\n",
+	)
+
+	want := "| Package | Change | Before | After |\n" +
+		"|---------|--------|--------|-------|\n" +
+		"| django | extras | `auth` | `rest` |\n" +
+		"| flask | version | `==1.0.0` | `==2.0.1` |\n" +
+		"| flask | markers | | `python_version >= '3.8'` |\n" +
+		"| httpx | added | | `httpx>=0.24` |\n" +
+		"| requests | removed | `requests==2.28.0` | |\n" +
+		"| six | hashes | 1 removed | 2 added |\n"
+	if got := result.Markdown(); got != want {
+		t.Errorf("Markdown() =\n%s\n期望\n%s", got, want)
+	}
+
+	// 按环境标记拆分的同一个包用环境标记区分
+	split := compareStrings(t,
+		"pywin32==300 ; sys_platform == 'win32'\npywin32==300 ; sys_platform == 'cygwin'\n",
+		"pywin32==305 ; sys_platform == 'win32'\npywin32==306 ; sys_platform == 'cygwin'\n",
+	)
+	want = "| Package | Change | Before | After |\n" +
+		"|---------|--------|--------|-------|\n" +
+		"| pywin32 ; sys_platform == 'win32' | version | `==300` | `==305` |\n" +
+		"| pywin32 ; sys_platform == 'cygwin' | version | `==300` | `==306` |\n"
+	if got := split.Markdown(); got != want {
+		t.Errorf("Markdown() =\n%s\n期望\n%s", got, want)
+	}
+
+	if got := compareStrings(t, "flask\n", "Flask\n").Markdown(); got != "No dependency changes.\n" {
+		t.Errorf("没有变化时Markdown() = %q", got)
+	}
+}
+
+func TestResult_JSON(t *testing.T) {
+	data, err := compareStrings(t, "flask==1.0.0\n", "flask==2.0.1\n").JSON()
+	if err != nil {
+		t.Fatalf("JSON失败: %v", err)
+	}
+
+	var decoded Result
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("解析JSON失败: %v", err)
+	}
+	if len(decoded.Changes) != 1 || decoded.Changes[0].Kind != Changed ||
+		decoded.Changes[0].Version == nil || decoded.Changes[0].Version.New != "==2.0.1" {
+		t.Errorf("JSON往返结果 = %s", data)
+	}
+
+	empty, err := compareStrings(t, "", "").JSON()
+	if err != nil {
+		t.Fatalf("JSON失败: %v", err)
+	}
+	if string(empty) != "{\n  \"changes\": []\n}" {
+		t.Errorf("没有变化时JSON() = %s", empty)
+	}
+}
@@ -0,0 +1,71 @@
+package compare
+
+import (
+	"fmt"
+
+	"github.com/scagogogo/python-requirements-parser/pkg/models"
+	"github.com/scagogogo/python-requirements-parser/pkg/parser"
+)
+
+// LoadFile 解析requirements文件及其通过"-r"引用的所有文件
+//
+// 结果按深度优先的顺序排列，与pip处理引用的顺序一致。每个文件只解析一次，
+// 因此循环引用不会导致无限递归。URL形式的引用不会被下载；
+// 本地引用的文件无法读取时返回错误，避免把缺失的文件误报为删除了其中所有的包。
+// "-c"约束文件只限制版本，不会被加载。
+//
+// 参数:
+//   - path: 根requirements文件的路径
+//
+// 返回:
+//   - []*models.Requirement: 引用树中所有文件的解析结果
+//   - error: 文件无法读取或解析时的错误
+func LoadFile(path string) ([]*models.Requirement, error) {
+	files, missing, err := parser.New().ParseTree(path)
+	if err != nil {
+		return nil, fmt.Errorf("解析文件 %s 失败: %w", path, err)
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("解析文件 %s 失败: %w", missing[0].File, missing[0])
+	}
+
+	// 引用文件的内容按pip的顺序放在引用它的"-r"行之后
+	included := make(map[*models.Requirement][]*models.Requirement)
+	for _, file := range files[1:] {
+		included[file.IncludedBy] = file.Requirements
+	}
+
+	var all []*models.Requirement
+	var flatten func(reqs []*models.Requirement)
+	flatten = func(reqs []*models.Requirement) {
+		for _, req := range reqs {
+			all = append(all, req)
+			flatten(included[req])
+		}
+	}
+	flatten(files[0].Requirements)
+	return all, nil
+}
+
+// CompareFiles 比较两个requirements文件及其引用树
+//
+// 两边分别由LoadFile加载后使用Compare比较，因此依赖在引用文件之间移动不算作变化。
+//
+// 参数:
+//   - before: 旧的requirements文件路径
+//   - after: 新的requirements文件路径
+//
+// 返回:
+//   - *Result: 按包名排序的变化
+//   - error: 文件无法读取或解析时的错误
+func CompareFiles(before, after string) (*Result, error) {
+	oldReqs, err := LoadFile(before)
+	if err != nil {
+		return nil, err
+	}
+	newReqs, err := LoadFile(after)
+	if err != nil {
+		return nil, err
+	}
+	return Compare(oldReqs, newReqs), nil
+}
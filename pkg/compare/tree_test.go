@@ -0,0 +1,57 @@
+package compare
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles 在临时目录中创建文件，返回目录路径
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestCompareFiles(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"old/requirements.txt": "-r base.txt\nflask==1.0.0\n",
+		"old/base.txt":         "requests==2.28.0\nsix\n-r requirements.txt\n",
+		"new/requirements.txt": "-r common/base.txt\n-c constraints.txt\nflask==2.0.1\n",
+		"new/common/base.txt":  "six\nrequests == 2.28.0\nhttpx\n",
+	})
+
+	result, err := CompareFiles(filepath.Join(dir, "old/requirements.txt"), filepath.Join(dir, "new/requirements.txt"))
+	if err != nil {
+		t.Fatalf("CompareFiles失败: %v", err)
+	}
+
+	var names []string
+	for _, c := range result.Changes {
+		names = append(names, c.Name+":"+string(c.Kind))
+	}
+	if got := strings.Join(names, " "); got != "flask:changed httpx:added" {
+		t.Errorf("变化 = %s", got)
+	}
+}
+
+func TestLoadFile_MissingInclude(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"requirements.txt": "-r missing.txt\nflask\n",
+	})
+
+	_, err := LoadFile(filepath.Join(dir, "requirements.txt"))
+	if err == nil || !strings.Contains(err.Error(), "missing.txt") {
+		t.Errorf("期望引用文件缺失的错误，实际: %v", err)
+	}
+}
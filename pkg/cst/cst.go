@@ -0,0 +1,379 @@
+// Package cst 提供requirements.txt文件的无损具体语法树（Concrete Syntax Tree）
+//
+// 与parser包输出的models.Requirement不同，CST保留了文件中的每一个字节：
+// 空白、行继续符、选项的原始写法、注释前的空格以及行终止符都作为节点保存，
+// 因此对任意输入都满足 Print(Parse(x)) == x。
+//
+// 编辑器可以直接修改节点（如版本约束、extras、环境标记、哈希和注释），
+// 未被修改的部分在输出时保持原样，从而实现最小化diff的编辑。
+//
+// 使用示例:
+//
+//	f := cst.Parse("flask==1.0.0  # Web framework\n")
+//	line := f.Lines[0]
+//	line.Specifier().Set("==2.0.1")
+//	fmt.Print(cst.Print(f)) // 输出: flask==2.0.1  # Web framework
+package cst
+
+import (
+	"strings"
+)
+
+// Kind 表示CST节点的类型
+type Kind int
+
+const (
+	// KindWhitespace 空格和制表符
+	KindWhitespace Kind = iota
+	// KindContinuation 行继续符（"\"及其后的换行）
+	KindContinuation
+	// KindName 包名，如"flask"
+	KindName
+	// KindExtras extras，如"[security,socks]"
+	KindExtras
+	// KindSpecifier 版本约束，如">=2.0,<3.0"
+	KindSpecifier
+	// KindAt 直接引用中的"@"
+	KindAt
+	// KindReference URL、VCS URL或本地路径
+	KindReference
+	// KindMarker 环境标记，包括开头的";"
+	KindMarker
+	// KindOption 选项，如"-r file.txt"、"--index-url URL"、"-e"
+	KindOption
+	// KindHash 哈希选项，如"--hash=sha256:abcd"
+	KindHash
+	// KindComment 注释，包括开头的"#"
+	KindComment
+	// KindUnknown 无法识别的内容，原样保留
+	KindUnknown
+)
+
+// String 返回节点类型的名称
+func (k Kind) String() string {
+	switch k {
+	case KindWhitespace:
+		return "Whitespace"
+	case KindContinuation:
+		return "Continuation"
+	case KindName:
+		return "Name"
+	case KindExtras:
+		return "Extras"
+	case KindSpecifier:
+		return "Specifier"
+	case KindAt:
+		return "At"
+	case KindReference:
+		return "Reference"
+	case KindMarker:
+		return "Marker"
+	case KindOption:
+		return "Option"
+	case KindHash:
+		return "Hash"
+	case KindComment:
+		return "Comment"
+	default:
+		return "Unknown"
+	}
+}
+
+// Node 是CST中所有节点的公共接口
+type Node interface {
+	// Kind 返回节点类型
+	Kind() Kind
+
+	// Text 返回节点在源文件中的原始文本
+	Text() string
+}
+
+// File 表示一个完整的requirements文件
+type File struct {
+	// Lines 文件中的逻辑行，以"\"结尾的物理行会与下一行合并为一个逻辑行
+	Lines []*Line
+}
+
+// Line 表示一个逻辑行
+type Line struct {
+	// Nodes 行内的节点，按源文本顺序排列
+	Nodes []Node
+
+	// Newline 行终止符："\n"、"\r\n"，文件最后一行没有换行时为""
+	Newline string
+}
+
+// Print 将CST还原为文本
+//
+// 对于未修改的CST，输出与Parse的输入完全一致。
+func Print(f *File) string {
+	return f.String()
+}
+
+// String 返回文件的完整文本
+func (f *File) String() string {
+	var sb strings.Builder
+	for _, line := range f.Lines {
+		line.writeTo(&sb)
+	}
+	return sb.String()
+}
+
+// InsertLine 在指定位置插入一个逻辑行
+func (f *File) InsertLine(index int, line *Line) {
+	if index < 0 {
+		index = 0
+	}
+	if index > len(f.Lines) {
+		index = len(f.Lines)
+	}
+	f.Lines = append(f.Lines, nil)
+	copy(f.Lines[index+1:], f.Lines[index:])
+	f.Lines[index] = line
+}
+
+// RemoveLine 移除指定位置的逻辑行
+func (f *File) RemoveLine(index int) {
+	if index < 0 || index >= len(f.Lines) {
+		return
+	}
+	f.Lines = append(f.Lines[:index], f.Lines[index+1:]...)
+}
+
+// LineNumber 返回第index个逻辑行在文件中的起始物理行号（从1开始）
+func (f *File) LineNumber(index int) int {
+	number := 1
+	for i := 0; i < index && i < len(f.Lines); i++ {
+		number += f.Lines[i].PhysicalLines()
+	}
+	return number
+}
+
+// String 返回行的完整文本，包括行终止符
+func (l *Line) String() string {
+	var sb strings.Builder
+	l.writeTo(&sb)
+	return sb.String()
+}
+
+// Text 返回行的内容，不包括最后的行终止符
+func (l *Line) Text() string {
+	var sb strings.Builder
+	for _, node := range l.Nodes {
+		sb.WriteString(node.Text())
+	}
+	return sb.String()
+}
+
+func (l *Line) writeTo(sb *strings.Builder) {
+	for _, node := range l.Nodes {
+		sb.WriteString(node.Text())
+	}
+	sb.WriteString(l.Newline)
+}
+
+// PhysicalLines 返回逻辑行包含的物理行数
+func (l *Line) PhysicalLines() int {
+	return strings.Count(l.Text(), "\n") + 1
+}
+
+// IsBlank 检查行是否只包含空白
+func (l *Line) IsBlank() bool {
+	for _, node := range l.Nodes {
+		if node.Kind() != KindWhitespace && node.Kind() != KindContinuation {
+			return false
+		}
+	}
+	return true
+}
+
+// IsComment 检查行是否只包含注释（以及空白）
+func (l *Line) IsComment() bool {
+	hasComment := false
+	for _, node := range l.Nodes {
+		switch node.Kind() {
+		case KindComment:
+			hasComment = true
+		case KindWhitespace, KindContinuation:
+		default:
+			return false
+		}
+	}
+	return hasComment
+}
+
+// Index 返回节点在行中的位置，不存在时返回-1
+func (l *Line) Index(node Node) int {
+	for i, n := range l.Nodes {
+		if n == node {
+			return i
+		}
+	}
+	return -1
+}
+
+// Offset 返回节点在行文本中的字节偏移，不存在时返回-1
+func (l *Line) Offset(node Node) int {
+	offset := 0
+	for _, n := range l.Nodes {
+		if n == node {
+			return offset
+		}
+		offset += len(n.Text())
+	}
+	return -1
+}
+
+// Insert 在指定位置插入节点
+func (l *Line) Insert(index int, nodes ...Node) {
+	if index < 0 {
+		index = 0
+	}
+	if index > len(l.Nodes) {
+		index = len(l.Nodes)
+	}
+	result := make([]Node, 0, len(l.Nodes)+len(nodes))
+	result = append(result, l.Nodes[:index]...)
+	result = append(result, nodes...)
+	result = append(result, l.Nodes[index:]...)
+	l.Nodes = result
+}
+
+// InsertAfter 在anchor节点之后插入节点，anchor不存在时追加到行尾
+func (l *Line) InsertAfter(anchor Node, nodes ...Node) {
+	index := l.Index(anchor)
+	if index == -1 {
+		l.Insert(len(l.Nodes), nodes...)
+		return
+	}
+	l.Insert(index+1, nodes...)
+}
+
+// InsertBefore 在anchor节点之前插入节点，anchor不存在时追加到行尾
+func (l *Line) InsertBefore(anchor Node, nodes ...Node) {
+	index := l.Index(anchor)
+	if index == -1 {
+		l.Insert(len(l.Nodes), nodes...)
+		return
+	}
+	l.Insert(index, nodes...)
+}
+
+// Remove 移除节点，返回是否找到该节点
+func (l *Line) Remove(node Node) bool {
+	index := l.Index(node)
+	if index == -1 {
+		return false
+	}
+	l.Nodes = append(l.Nodes[:index], l.Nodes[index+1:]...)
+	return true
+}
+
+// RemoveWithSpace 移除节点以及紧挨在它前面的空白和行继续符
+//
+// 用于删除行内的某个组成部分（如注释、环境标记或一个哈希）而不留下多余的空白。
+// 如果节点位于行首，则移除它后面的空白。
+func (l *Line) RemoveWithSpace(node Node) bool {
+	index := l.Index(node)
+	if index == -1 {
+		return false
+	}
+
+	start, end := index, index+1
+	for start > 0 && isTrivia(l.Nodes[start-1]) {
+		start--
+	}
+	if start == 0 {
+		for end < len(l.Nodes) && isTrivia(l.Nodes[end]) {
+			end++
+		}
+	}
+
+	l.Nodes = append(l.Nodes[:start], l.Nodes[end:]...)
+	return true
+}
+
+// First 返回第一个指定类型的节点，不存在时返回nil
+func (l *Line) First(kind Kind) Node {
+	for _, node := range l.Nodes {
+		if node.Kind() == kind {
+			return node
+		}
+	}
+	return nil
+}
+
+// Name 返回包名节点，不存在时返回nil
+func (l *Line) Name() *Name {
+	n, _ := l.First(KindName).(*Name)
+	return n
+}
+
+// Extras 返回extras节点，不存在时返回nil
+func (l *Line) Extras() *Extras {
+	n, _ := l.First(KindExtras).(*Extras)
+	return n
+}
+
+// Specifier 返回版本约束节点，不存在时返回nil
+func (l *Line) Specifier() *Specifier {
+	n, _ := l.First(KindSpecifier).(*Specifier)
+	return n
+}
+
+// Reference 返回URL或路径节点，不存在时返回nil
+func (l *Line) Reference() *Reference {
+	n, _ := l.First(KindReference).(*Reference)
+	return n
+}
+
+// Marker 返回环境标记节点，不存在时返回nil
+func (l *Line) Marker() *Marker {
+	n, _ := l.First(KindMarker).(*Marker)
+	return n
+}
+
+// Comment 返回注释节点，不存在时返回nil
+func (l *Line) Comment() *Comment {
+	n, _ := l.First(KindComment).(*Comment)
+	return n
+}
+
+// Options 返回行内所有选项节点（不包括哈希）
+func (l *Line) Options() []*Option {
+	var options []*Option
+	for _, node := range l.Nodes {
+		if opt, ok := node.(*Option); ok {
+			options = append(options, opt)
+		}
+	}
+	return options
+}
+
+// Option 返回第一个指定名称的选项节点，flag可以是"-r"或"--requirement"等任意写法
+func (l *Line) Option(flags ...string) *Option {
+	for _, opt := range l.Options() {
+		for _, flag := range flags {
+			if opt.Flag == flag {
+				return opt
+			}
+		}
+	}
+	return nil
+}
+
+// Hashes 返回行内所有哈希节点
+func (l *Line) Hashes() []*Hash {
+	var hashes []*Hash
+	for _, node := range l.Nodes {
+		if hash, ok := node.(*Hash); ok {
+			hashes = append(hashes, hash)
+		}
+	}
+	return hashes
+}
+
+// isTrivia 检查节点是否为空白或行继续符
+func isTrivia(node Node) bool {
+	return node.Kind() == KindWhitespace || node.Kind() == KindContinuation
+}
@@ -0,0 +1,160 @@
+package cst
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEditSpecifierAndComment(t *testing.T) {
+	f := Parse("flask >= 1.0  # web framework\nrequests==2.25.0\n")
+
+	f.Lines[0].Specifier().Set(">= 2.0")
+	f.Lines[0].Comment().SetContent("updated")
+
+	expected := "flask >= 2.0  # updated\nrequests==2.25.0\n"
+	if got := f.String(); got != expected {
+		t.Errorf("编辑后为%q，期望%q", got, expected)
+	}
+}
+
+func TestEditExtras(t *testing.T) {
+	line := ParseLine("uvicorn[standard, socks]>=0.15")
+	extras := line.Extras()
+	if got := extras.Items(); !reflect.DeepEqual(got, []string{"standard", "socks"}) {
+		t.Errorf("Items() = %q", got)
+	}
+
+	extras.SetItems([]string{"standard"})
+	if got := line.Text(); got != "uvicorn[standard]>=0.15" {
+		t.Errorf("SetItems后为%q", got)
+	}
+}
+
+func TestEditMarker(t *testing.T) {
+	line := ParseLine("pkg==1.0;python_version<'3.8'")
+	marker := line.Marker()
+	if got := marker.Expression(); got != "python_version<'3.8'" {
+		t.Errorf("Expression() = %q", got)
+	}
+
+	marker.SetExpression("python_version >= '3.8'")
+	if got := line.Text(); got != "pkg==1.0;python_version >= '3.8'" {
+		t.Errorf("SetExpression后为%q", got)
+	}
+}
+
+func TestEditHashes(t *testing.T) {
+	f := Parse("django==3.2 \\\n    --hash=sha256:aaaa \\\n    --hash=sha256:bbbb\n")
+	line := f.Lines[0]
+
+	hashes := line.Hashes()
+	if len(hashes) != 2 {
+		t.Fatalf("期望2个哈希，实际为%d", len(hashes))
+	}
+	if hashes[0].Algorithm() != "sha256" || hashes[0].Digest() != "aaaa" {
+		t.Errorf("哈希解析错误: %s %s", hashes[0].Algorithm(), hashes[0].Digest())
+	}
+
+	line.RemoveWithSpace(hashes[1])
+	if got := f.String(); got != "django==3.2 \\\n    --hash=sha256:aaaa\n" {
+		t.Errorf("移除哈希后为%q", got)
+	}
+
+	line.InsertAfter(hashes[0], NewWhitespace(" "), NewContinuation("\n"), NewWhitespace("    "), NewHash("sha256:cccc"))
+	if got := f.String(); got != "django==3.2 \\\n    --hash=sha256:aaaa \\\n    --hash=sha256:cccc\n" {
+		t.Errorf("添加哈希后为%q", got)
+	}
+}
+
+func TestRemoveWithSpace(t *testing.T) {
+	tests := []struct {
+		input    string
+		remove   func(*Line) Node
+		expected string
+	}{
+		{
+			input:    "flask==1.0  # comment",
+			remove:   func(l *Line) Node { return l.Comment() },
+			expected: "flask==1.0",
+		},
+		{
+			input:    "flask==1.0 ; python_version>'3' # c",
+			remove:   func(l *Line) Node { return l.Marker() },
+			expected: "flask==1.0 # c",
+		},
+		{
+			input:    "--hash=sha256:ab flask",
+			remove:   func(l *Line) Node { return l.Hashes()[0] },
+			expected: "flask",
+		},
+	}
+
+	for _, tt := range tests {
+		line := ParseLine(tt.input)
+		if !line.RemoveWithSpace(tt.remove(line)) {
+			t.Errorf("RemoveWithSpace(%q) 未找到节点", tt.input)
+		}
+		if got := line.Text(); got != tt.expected {
+			t.Errorf("RemoveWithSpace(%q) = %q，期望%q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestOptions(t *testing.T) {
+	line := ParseLine("--index-url=https://a.example/simple")
+	opt := line.Option("-i", "--index-url")
+	if opt == nil {
+		t.Fatalf("未找到--index-url选项")
+	}
+	if opt.Name() != "index-url" || opt.Value != "https://a.example/simple" {
+		t.Errorf("选项解析错误: %q %q", opt.Name(), opt.Value)
+	}
+
+	opt.SetValue("https://b.example/simple")
+	if got := line.Text(); got != "--index-url=https://b.example/simple" {
+		t.Errorf("SetValue后为%q", got)
+	}
+
+	flag := NewOption("--pre", "")
+	if flag.Text() != "--pre" {
+		t.Errorf("NewOption无值时为%q", flag.Text())
+	}
+	opt = NewOption("-r", "base.txt")
+	if opt.Text() != "-r base.txt" {
+		t.Errorf("NewOption有值时为%q", opt.Text())
+	}
+}
+
+func TestInsertAndRemoveLine(t *testing.T) {
+	f := Parse("flask==1.0\nrequests==2.0\n")
+
+	line := ParseLine("django>=3.2")
+	line.Newline = "\n"
+	f.InsertLine(1, line)
+	if got := f.String(); got != "flask==1.0\ndjango>=3.2\nrequests==2.0\n" {
+		t.Errorf("InsertLine后为%q", got)
+	}
+
+	f.RemoveLine(0)
+	if got := f.String(); got != "django>=3.2\nrequests==2.0\n" {
+		t.Errorf("RemoveLine后为%q", got)
+	}
+
+	f.RemoveLine(10)
+	if len(f.Lines) != 2 {
+		t.Errorf("越界的RemoveLine不应修改文件")
+	}
+}
+
+func TestCollapseContinuations(t *testing.T) {
+	line := ParseLine("flask>=1.0, \\\n    <2.0")
+	if got := line.Specifier().Value(); got != ">=1.0,  <2.0" {
+		t.Errorf("Value() = %q", got)
+	}
+}
+
+func TestKindString(t *testing.T) {
+	if KindHash.String() != "Hash" || KindUnknown.String() != "Unknown" || Kind(100).String() != "Unknown" {
+		t.Errorf("Kind.String()返回值错误")
+	}
+}
@@ -0,0 +1,322 @@
+package cst
+
+import (
+	"strings"
+)
+
+// Whitespace 空格和制表符
+type Whitespace struct {
+	Raw string
+}
+
+// NewWhitespace 创建空白节点
+func NewWhitespace(raw string) *Whitespace {
+	return &Whitespace{Raw: raw}
+}
+
+// Kind 返回KindWhitespace
+func (n *Whitespace) Kind() Kind { return KindWhitespace }
+
+// Text 返回原始文本
+func (n *Whitespace) Text() string { return n.Raw }
+
+// Continuation 行继续符，Raw为"\"加上其后的换行（"\n"或"\r\n"），
+// 位于文件末尾时只有"\"
+type Continuation struct {
+	Raw string
+}
+
+// NewContinuation 创建行继续符节点，newline为其后的换行符
+func NewContinuation(newline string) *Continuation {
+	return &Continuation{Raw: "\\" + newline}
+}
+
+// Kind 返回KindContinuation
+func (n *Continuation) Kind() Kind { return KindContinuation }
+
+// Text 返回原始文本
+func (n *Continuation) Text() string { return n.Raw }
+
+// Name 包名
+type Name struct {
+	Raw string
+}
+
+// NewName 创建包名节点
+func NewName(name string) *Name {
+	return &Name{Raw: name}
+}
+
+// Kind 返回KindName
+func (n *Name) Kind() Kind { return KindName }
+
+// Text 返回原始文本
+func (n *Name) Text() string { return n.Raw }
+
+// Extras 包名或路径后的extras，Raw包括方括号，如"[security, socks]"
+type Extras struct {
+	Raw string
+}
+
+// NewExtras 创建extras节点
+func NewExtras(items []string) *Extras {
+	return &Extras{Raw: "[" + strings.Join(items, ",") + "]"}
+}
+
+// Kind 返回KindExtras
+func (n *Extras) Kind() Kind { return KindExtras }
+
+// Text 返回原始文本
+func (n *Extras) Text() string { return n.Raw }
+
+// Items 返回extras列表
+func (n *Extras) Items() []string {
+	inner := strings.TrimSuffix(strings.TrimPrefix(n.Raw, "["), "]")
+	var items []string
+	for _, item := range strings.Split(inner, ",") {
+		if trimmed := strings.TrimSpace(item); trimmed != "" {
+			items = append(items, trimmed)
+		}
+	}
+	return items
+}
+
+// SetItems 设置extras列表，沿用原有的分隔风格（", "或","）
+func (n *Extras) SetItems(items []string) {
+	sep := ","
+	if strings.Contains(n.Raw, ", ") {
+		sep = ", "
+	}
+	n.Raw = "[" + strings.Join(items, sep) + "]"
+}
+
+// Specifier 版本约束，如">=2.0,<3.0"或"(>=1.0)"
+type Specifier struct {
+	Raw string
+}
+
+// NewSpecifier 创建版本约束节点
+func NewSpecifier(spec string) *Specifier {
+	return &Specifier{Raw: spec}
+}
+
+// Kind 返回KindSpecifier
+func (n *Specifier) Kind() Kind { return KindSpecifier }
+
+// Text 返回原始文本
+func (n *Specifier) Text() string { return n.Raw }
+
+// Value 返回版本约束，去掉了行继续符
+func (n *Specifier) Value() string {
+	return collapseContinuations(n.Raw)
+}
+
+// Set 设置版本约束
+func (n *Specifier) Set(spec string) {
+	n.Raw = spec
+}
+
+// At 直接引用中的"@"
+type At struct {
+	Raw string
+}
+
+// NewAt 创建"@"节点
+func NewAt() *At {
+	return &At{Raw: "@"}
+}
+
+// Kind 返回KindAt
+func (n *At) Kind() Kind { return KindAt }
+
+// Text 返回原始文本
+func (n *At) Text() string { return n.Raw }
+
+// Reference URL、VCS URL或本地路径
+type Reference struct {
+	Raw string
+}
+
+// NewReference 创建引用节点
+func NewReference(ref string) *Reference {
+	return &Reference{Raw: ref}
+}
+
+// Kind 返回KindReference
+func (n *Reference) Kind() Kind { return KindReference }
+
+// Text 返回原始文本
+func (n *Reference) Text() string { return n.Raw }
+
+// Set 设置URL或路径
+func (n *Reference) Set(ref string) {
+	n.Raw = ref
+}
+
+// Marker 环境标记，Raw包括开头的";"，如"; python_version >= '3.8'"
+type Marker struct {
+	Raw string
+}
+
+// NewMarker 创建环境标记节点，格式为"; expr"
+func NewMarker(expr string) *Marker {
+	return &Marker{Raw: "; " + expr}
+}
+
+// Kind 返回KindMarker
+func (n *Marker) Kind() Kind { return KindMarker }
+
+// Text 返回原始文本
+func (n *Marker) Text() string { return n.Raw }
+
+// Expression 返回环境标记表达式，不包括";"，去掉了行继续符
+func (n *Marker) Expression() string {
+	return strings.TrimSpace(collapseContinuations(strings.TrimPrefix(n.Raw, ";")))
+}
+
+// SetExpression 设置环境标记表达式，保留";"后原有的空白
+func (n *Marker) SetExpression(expr string) {
+	body := strings.TrimPrefix(n.Raw, ";")
+	space := body[:len(body)-len(strings.TrimLeft(body, " \t"))]
+	if strings.TrimSpace(body) == "" {
+		space = " "
+	}
+	n.Raw = ";" + space + expr
+}
+
+// Option 选项，如"-r file.txt"、"--index-url=URL"或"-e"
+//
+// Flag为选项名称（包括"-"或"--"），Separator为选项名称和值之间的原始文本
+// （"="、空白或行继续符），Value为选项值的原始文本（可能带引号）。
+// 对于"-e"/"--editable"，值作为后续的Reference或Name节点保存，Value为空。
+type Option struct {
+	Flag      string
+	Separator string
+	Value     string
+}
+
+// NewOption 创建选项节点，有值时使用空格分隔
+func NewOption(flag, value string) *Option {
+	if value == "" {
+		return &Option{Flag: flag}
+	}
+	return &Option{Flag: flag, Separator: " ", Value: value}
+}
+
+// Kind 返回KindOption
+func (n *Option) Kind() Kind { return KindOption }
+
+// Text 返回原始文本
+func (n *Option) Text() string { return n.Flag + n.Separator + n.Value }
+
+// Name 返回去掉"-"前缀的选项名称，如"index-url"、"r"
+func (n *Option) Name() string {
+	return strings.TrimLeft(n.Flag, "-")
+}
+
+// SetValue 设置选项值，原来没有分隔符时使用空格
+func (n *Option) SetValue(value string) {
+	if n.Separator == "" && value != "" {
+		n.Separator = " "
+	}
+	n.Value = value
+}
+
+// Hash 哈希选项，如"--hash=sha256:abcd"
+type Hash struct {
+	Flag      string
+	Separator string
+	Value     string
+}
+
+// NewHash 创建哈希节点，value形如"sha256:abcd"
+func NewHash(value string) *Hash {
+	return &Hash{Flag: "--hash", Separator: "=", Value: value}
+}
+
+// Kind 返回KindHash
+func (n *Hash) Kind() Kind { return KindHash }
+
+// Text 返回原始文本
+func (n *Hash) Text() string { return n.Flag + n.Separator + n.Value }
+
+// Algorithm 返回哈希算法，如"sha256"
+func (n *Hash) Algorithm() string {
+	if idx := strings.Index(n.Value, ":"); idx != -1 {
+		return n.Value[:idx]
+	}
+	return ""
+}
+
+// Digest 返回哈希摘要
+func (n *Hash) Digest() string {
+	if idx := strings.Index(n.Value, ":"); idx != -1 {
+		return n.Value[idx+1:]
+	}
+	return n.Value
+}
+
+// Comment 注释，Raw包括开头的"#"，如"# Web framework"
+type Comment struct {
+	Raw string
+}
+
+// NewComment 创建注释节点，格式为"# text"
+func NewComment(text string) *Comment {
+	if text == "" {
+		return &Comment{Raw: "#"}
+	}
+	return &Comment{Raw: "# " + text}
+}
+
+// Kind 返回KindComment
+func (n *Comment) Kind() Kind { return KindComment }
+
+// Text 返回原始文本
+func (n *Comment) Text() string { return n.Raw }
+
+// Content 返回注释内容，不包括"#"和首尾空白
+func (n *Comment) Content() string {
+	return strings.TrimSpace(strings.TrimPrefix(n.Raw, "#"))
+}
+
+// SetContent 设置注释内容，保留"#"后原有的空白
+func (n *Comment) SetContent(text string) {
+	body := strings.TrimPrefix(n.Raw, "#")
+	space := body[:len(body)-len(strings.TrimLeft(body, " \t"))]
+	if strings.TrimSpace(body) == "" {
+		space = " "
+	}
+	n.Raw = "#" + space + text
+}
+
+// Unknown 无法识别的内容
+type Unknown struct {
+	Raw string
+}
+
+// Kind 返回KindUnknown
+func (n *Unknown) Kind() Kind { return KindUnknown }
+
+// Text 返回原始文本
+func (n *Unknown) Text() string { return n.Raw }
+
+// collapseContinuations 将文本中的行继续符及其后的缩进替换为单个空格
+func collapseContinuations(s string) string {
+	if !strings.Contains(s, "\\\n") && !strings.Contains(s, "\\\r\n") {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if n := continuationLen(s, i); n > 0 {
+			i += n - 1
+			for i+1 < len(s) && (s[i+1] == ' ' || s[i+1] == '\t') {
+				i++
+			}
+			sb.WriteByte(' ')
+			continue
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
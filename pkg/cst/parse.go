@@ -0,0 +1,507 @@
+package cst
+
+import (
+	"strings"
+
+	"github.com/scagogogo/python-requirements-parser/pkg/parser"
+)
+
+// valueOptions 需要一个参数值的选项
+var valueOptions = map[string]bool{
+	"-r": true, "--requirement": true,
+	"-c": true, "--constraint": true,
+	"-i": true, "--index-url": true,
+	"--extra-index-url": true,
+	"-f":                true, "--find-links": true,
+	"--no-binary":       true,
+	"--only-binary":     true,
+	"--trusted-host":    true,
+	"--use-feature":     true,
+	"--global-option":   true,
+	"--install-option":  true,
+	"-C":                true,
+	"--config-settings": true,
+	"--hash":            true,
+}
+
+// archiveSuffixes 本地归档文件扩展名
+var archiveSuffixes = []string{".whl", ".tar.gz", ".tgz", ".tar.bz2", ".tbz", ".tar.xz", ".txz", ".tar", ".zip"}
+
+// Parse 将requirements文件文本解析为CST
+//
+// 解析不会失败：无法识别的内容保存为Unknown节点，因此对任意输入都满足
+// Print(Parse(text)) == text。与parser包一致，以"\"结尾的物理行会与
+// 下一行合并为一个逻辑行，但带注释的行不会继续。
+//
+// 示例:
+//
+//	f := cst.Parse("flask==1.0.0 \\\n    --hash=sha256:abcd\n")
+//	len(f.Lines)                 // 1
+//	len(f.Lines[0].Hashes())     // 1
+func Parse(text string) *File {
+	f := &File{}
+	var logical strings.Builder
+
+	for len(text) > 0 {
+		var line, newline string
+		if idx := strings.IndexByte(text, '\n'); idx == -1 {
+			line, text = text, ""
+		} else {
+			line, newline, text = text[:idx], "\n", text[idx+1:]
+			if strings.HasSuffix(line, "\r") {
+				line, newline = line[:len(line)-1], "\r\n"
+			}
+		}
+
+		// 以"\"结尾且后面还有内容的行与下一行合并，整行注释不会继续，与parser一致
+		if strings.HasSuffix(line, "\\") && text != "" && !parser.IsCommentLine(line) {
+			logical.WriteString(line)
+			logical.WriteString(newline)
+			continue
+		}
+
+		logical.WriteString(line)
+		f.Lines = append(f.Lines, &Line{Nodes: lexLine(logical.String()), Newline: newline})
+		logical.Reset()
+	}
+
+	return f
+}
+
+// ParseLine 将单个逻辑行解析为Line，返回的Line没有行终止符
+//
+// 用于构造要插入文件中的新行。
+//
+// 示例:
+//
+//	line := cst.ParseLine("requests>=2.25.0  # HTTP library")
+//	line.Newline = "\n"
+//	f.InsertLine(0, line)
+func ParseLine(text string) *Line {
+	return &Line{Nodes: lexLine(text)}
+}
+
+// lexer 逻辑行的词法分析器
+type lexer struct {
+	src   string
+	pos   int
+	nodes []Node
+}
+
+// lexLine 将逻辑行的文本切分为节点
+func lexLine(src string) []Node {
+	l := &lexer{src: src}
+	l.run()
+
+	// 保证无损：节点文本必须能完整还原输入
+	var sb strings.Builder
+	for _, node := range l.nodes {
+		sb.WriteString(node.Text())
+	}
+	if sb.String() != src {
+		return []Node{&Unknown{Raw: src}}
+	}
+
+	return l.nodes
+}
+
+func (l *lexer) emit(node Node) {
+	l.nodes = append(l.nodes, node)
+}
+
+// run 依次识别行内的各个组成部分
+func (l *lexer) run() {
+	head := true // 是否期待requirement的主体（包名或引用）
+	for l.pos < len(l.src) {
+		if l.trivia() {
+			continue
+		}
+
+		c := l.src[l.pos]
+		switch {
+		case c == '#' && l.atTokenStart():
+			l.emit(&Comment{Raw: l.src[l.pos:]})
+			l.pos = len(l.src)
+		case c == '-' && l.atTokenStart():
+			head = l.option()
+		case c == ';':
+			l.marker()
+			head = false
+		case head:
+			l.requirement()
+			head = false
+		default:
+			l.unknown()
+		}
+	}
+}
+
+// atTokenStart 检查当前位置是否位于行首或空白之后
+func (l *lexer) atTokenStart() bool {
+	if l.pos == 0 {
+		return true
+	}
+	prev := l.src[l.pos-1]
+	return isSpace(prev) || prev == '\n'
+}
+
+// trivia 识别空白或行继续符，返回是否识别到
+func (l *lexer) trivia() bool {
+	start := l.pos
+	for l.pos < len(l.src) && isSpace(l.src[l.pos]) {
+		l.pos++
+	}
+	if l.pos > start {
+		l.emit(&Whitespace{Raw: l.src[start:l.pos]})
+		return true
+	}
+
+	if n := continuationLen(l.src, l.pos); n > 0 {
+		l.emit(&Continuation{Raw: l.src[l.pos : l.pos+n]})
+		l.pos += n
+		return true
+	}
+
+	return false
+}
+
+// skipTrivia 返回从i开始跳过空白和行继续符后的位置
+func (l *lexer) skipTrivia(i int) int {
+	for i < len(l.src) {
+		if isSpace(l.src[i]) {
+			i++
+		} else if n := continuationLen(l.src, i); n > 0 {
+			i += n
+		} else {
+			break
+		}
+	}
+	return i
+}
+
+// skipSpaces 返回从i开始跳过空格和制表符后的位置
+func (l *lexer) skipSpaces(i int) int {
+	for i < len(l.src) && isSpace(l.src[i]) {
+		i++
+	}
+	return i
+}
+
+// scanWord 返回从i开始的单词的结束位置
+//
+// 单词在空白、行继续符处结束，stopAtSemicolon为true时也在分隔环境标记的";"处结束
+// （URL中的";"见parser.IsMarkerSeparator）；quotes为true时引号内的内容（包括空白）属于同一个单词。
+func (l *lexer) scanWord(i int, stopAtSemicolon, quotes bool) int {
+	for i < len(l.src) {
+		c := l.src[i]
+		if isSpace(c) || continuationLen(l.src, i) > 0 || (stopAtSemicolon && parser.IsMarkerSeparator(l.src, i)) {
+			break
+		}
+		if quotes && (c == '"' || c == '\'') {
+			if j := closingQuote(l.src, i); j != -1 {
+				i = j + 1
+				continue
+			}
+		}
+		i++
+	}
+	return i
+}
+
+// requirement 识别requirement主体：包名、extras、版本约束或直接引用，以及URL和路径
+func (l *lexer) requirement() {
+	start := l.pos
+	end := l.scanWord(start, true, false)
+
+	if !l.directRefAt(start) && isReference(l.src[start:end]) {
+		l.reference(end)
+		return
+	}
+
+	i := start
+	for i < len(l.src) && isNameChar(l.src[i]) {
+		i++
+	}
+	if i == start {
+		l.unknown()
+		return
+	}
+	l.emit(&Name{Raw: l.src[start:i]})
+	l.pos = i
+
+	// extras
+	j := l.skipSpaces(l.pos)
+	if j < len(l.src) && l.src[j] == '[' {
+		if k := strings.IndexAny(l.src[j:], "]\n"); k != -1 && l.src[j+k] == ']' {
+			l.emitSpace(l.pos, j)
+			l.emit(&Extras{Raw: l.src[j : j+k+1]})
+			l.pos = j + k + 1
+		}
+	}
+
+	// 直接引用或版本约束
+	j = l.skipSpaces(l.pos)
+	if j >= len(l.src) {
+		return
+	}
+	switch c := l.src[j]; {
+	case c == '@':
+		l.emitSpace(l.pos, j)
+		l.emit(&At{Raw: "@"})
+		l.pos = j + 1
+		for l.trivia() {
+		}
+		if end := l.scanWord(l.pos, true, false); end > l.pos {
+			l.reference(end)
+		}
+	case isSpecifierStart(c):
+		l.emitSpace(l.pos, j)
+		l.pos = j
+		l.specifier()
+	}
+}
+
+// emitSpace 输出[start, end)之间的空白
+func (l *lexer) emitSpace(start, end int) {
+	if end > start {
+		l.emit(&Whitespace{Raw: l.src[start:end]})
+	}
+}
+
+// directRefAt 检查从i开始是否为"name[extras] @"形式的直接引用
+func (l *lexer) directRefAt(i int) bool {
+	start := i
+	for i < len(l.src) && isNameChar(l.src[i]) {
+		i++
+	}
+	if i == start {
+		return false
+	}
+	i = l.skipSpaces(i)
+	if i < len(l.src) && l.src[i] == '[' {
+		k := strings.IndexAny(l.src[i:], "]\n")
+		if k == -1 || l.src[i+k] != ']' {
+			return false
+		}
+		i = l.skipSpaces(i + k + 1)
+	}
+	return i < len(l.src) && l.src[i] == '@'
+}
+
+// reference 识别URL或路径，本地路径末尾的[extras]作为单独的节点
+func (l *lexer) reference(end int) {
+	word := l.src[l.pos:end]
+	isRemoteURL := strings.Contains(word, "://") && !hasPrefixFold(word, "file:")
+	if !isRemoteURL && strings.HasSuffix(word, "]") {
+		if idx := strings.LastIndex(word, "["); idx > 0 {
+			l.emit(&Reference{Raw: word[:idx]})
+			l.emit(&Extras{Raw: word[idx:]})
+			l.pos = end
+			return
+		}
+	}
+	l.emit(&Reference{Raw: word})
+	l.pos = end
+}
+
+// specifier 识别版本约束，约束中可以包含空白（如">= 1.0, < 2"）
+func (l *lexer) specifier() {
+	start := l.pos
+	i := start
+	for i < len(l.src) {
+		c := l.src[i]
+		if c == ';' {
+			break
+		}
+		if isSpace(c) || continuationLen(l.src, i) > 0 {
+			j := l.skipTrivia(i)
+			if j >= len(l.src) || strings.IndexByte("#-;", l.src[j]) != -1 {
+				break
+			}
+			i = j
+			continue
+		}
+		i++
+	}
+	l.emit(&Specifier{Raw: l.src[start:i]})
+	l.pos = i
+}
+
+// marker 识别以";"开头的环境标记，引号内的内容不会结束标记
+func (l *lexer) marker() {
+	start := l.pos
+	i := start + 1
+	for i < len(l.src) {
+		c := l.src[i]
+		if c == '"' || c == '\'' {
+			if j := closingQuote(l.src, i); j != -1 {
+				i = j + 1
+				continue
+			}
+		}
+		if isSpace(c) || continuationLen(l.src, i) > 0 {
+			j := l.skipTrivia(i)
+			if j >= len(l.src) || l.src[j] == '#' || l.src[j] == '-' {
+				break
+			}
+			i = j
+			continue
+		}
+		i++
+	}
+	l.emit(&Marker{Raw: l.src[start:i]})
+	l.pos = i
+}
+
+// option 识别选项，返回之后是否期待requirement主体（仅"-e"/"--editable"之后为true）
+func (l *lexer) option() bool {
+	start := l.pos
+	i := start + 1
+	if i < len(l.src) && l.src[i] == '-' {
+		i++
+		for i < len(l.src) && isFlagChar(l.src[i]) {
+			i++
+		}
+	} else if i < len(l.src) && isLetter(l.src[i]) {
+		i++
+	}
+
+	flag := l.src[start:i]
+	if strings.Trim(flag, "-") == "" {
+		l.unknown()
+		return false
+	}
+
+	// 可编辑安装的目标作为requirement主体继续解析
+	if flag == "-e" || flag == "--editable" {
+		sep := ""
+		if i < len(l.src) && l.src[i] == '=' {
+			sep = "="
+			i++
+		}
+		l.emit(&Option{Flag: flag, Separator: sep})
+		l.pos = i
+		return true
+	}
+
+	var sep, value string
+	switch {
+	case i < len(l.src) && l.src[i] == '=':
+		end := l.scanWord(i+1, false, true)
+		sep, value = "=", l.src[i+1:end]
+		i = end
+	case i < len(l.src) && !isSpace(l.src[i]) && continuationLen(l.src, i) == 0:
+		// 值紧跟在选项后面，如"-rrequirements.txt"
+		end := l.scanWord(i, false, true)
+		value = l.src[i:end]
+		i = end
+	case valueOptions[flag]:
+		j := l.skipTrivia(i)
+		if j < len(l.src) && l.src[j] != '#' {
+			end := l.scanWord(j, false, true)
+			sep, value = l.src[i:j], l.src[j:end]
+			i = end
+		}
+	}
+
+	if flag == "--hash" {
+		l.emit(&Hash{Flag: flag, Separator: sep, Value: value})
+	} else {
+		l.emit(&Option{Flag: flag, Separator: sep, Value: value})
+	}
+	l.pos = i
+	return false
+}
+
+// unknown 将当前单词保存为Unknown节点
+func (l *lexer) unknown() {
+	end := l.scanWord(l.pos, false, true)
+	if end == l.pos {
+		end = l.pos + 1
+	}
+	l.emit(&Unknown{Raw: l.src[l.pos:end]})
+	l.pos = end
+}
+
+// isReference 检查requirement主体是否为URL或本地路径
+func isReference(word string) bool {
+	if word == "" {
+		return false
+	}
+	if strings.Contains(word, "://") || hasPrefixFold(word, "file:") || strings.ContainsAny(word, "/\\") {
+		return true
+	}
+
+	base := word
+	if strings.HasSuffix(base, "]") {
+		if idx := strings.LastIndex(base, "["); idx > 0 {
+			base = base[:idx]
+		}
+	}
+	if base == "." || base == ".." || strings.HasPrefix(base, "~") {
+		return true
+	}
+
+	lower := strings.ToLower(base)
+	for _, suffix := range archiveSuffixes {
+		if strings.HasSuffix(lower, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// continuationLen 返回位置i处行继续符的长度，不是行继续符时返回0
+func continuationLen(s string, i int) int {
+	if i >= len(s) || s[i] != '\\' {
+		return 0
+	}
+	switch {
+	case i+1 == len(s):
+		return 1
+	case s[i+1] == '\n':
+		return 2
+	case s[i+1] == '\r' && i+2 < len(s) && s[i+2] == '\n':
+		return 3
+	}
+	return 0
+}
+
+// closingQuote 返回与位置i的引号配对的引号位置，同一物理行内找不到时返回-1
+func closingQuote(s string, i int) int {
+	q := s[i]
+	for j := i + 1; j < len(s); j++ {
+		switch s[j] {
+		case q:
+			return j
+		case '\n':
+			return -1
+		}
+	}
+	return -1
+}
+
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\f' || c == '\v' || c == '\r'
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isNameChar(c byte) bool {
+	return isLetter(c) || (c >= '0' && c <= '9') || c == '.' || c == '_' || c == '-' ||
+		c == '$' || c == '{' || c == '}'
+}
+
+func isFlagChar(c byte) bool {
+	return isLetter(c) || (c >= '0' && c <= '9') || c == '-' || c == '_'
+}
+
+func isSpecifierStart(c byte) bool {
+	return c == '<' || c == '>' || c == '=' || c == '!' || c == '~' || c == '('
+}
@@ -0,0 +1,249 @@
+package cst
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// roundTripCorpus 覆盖各种写法的requirements文本
+var roundTripCorpus = []string{
+	"",
+	"\n",
+	"\r\n",
+	"flask",
+	"flask==2.0.1\n",
+	"flask==2.0.1\r\nrequests>=2.25.0\r\n",
+	"flask==2.0.1\nrequests>=2.25.0",
+	"  flask  >=  1.0 ,  < 2.0   # web framework  \n",
+	"uvicorn[standard,  socks] >= 0.15.0 ; python_version >= '3.8'  # ASGI\n",
+	"django==3.2.0 \\\n    --hash=sha256:abcd \\\n    --hash=sha256:ef01\n",
+	"django==3.2.0 \\\r\n    --hash sha256:abcd\r\n",
+	"flask \\\n",
+	"flask \\",
+	"pkg; extra == 'a#b' # real comment\n",
+	"pkg ; python_version < \"3\" and platform_system != 'Win dows'\n",
+	"-r other.txt\n--requirement=more.txt\n-c constraints.txt\n",
+	"-rrequirements.txt\n",
+	"--index-url https://pypi.example.com/simple  # mirror\n",
+	"--extra-index-url=https://extra.example.com\n",
+	"-e git+https://github.com/user/project.git#egg=project[dev]\n",
+	"--editable=./local/pkg[test]\n",
+	"-e .\n",
+	"./downloads/numpy-1.9.2-cp34-none-win32.whl\n",
+	"C:\\path\\to\\pkg.whl\n",
+	"file:///C:/pkgs/pkg.tar.gz\n",
+	"requests[security] @ https://example.com/requests.zip ; python_version>='3.6'\n",
+	"name @ file:///local/path\n",
+	"# just a comment\n#\n\t# indented\n",
+	"url#not-a-comment\n",
+	"this is not valid !!! [ \n",
+	"pkg==1.0 --global-option=\"--with-feature x\"\n",
+	"weird \"unterminated quote\n",
+	"'\n",
+	"\\\\\\\n",
+	"\r",
+	"a\rb\n",
+	"${VAR}/pkg\n",
+	"--no-binary :all:\n",
+	"-\n--\n---x\n",
+	"pkg[\n",
+	"pkg @\n",
+	"\xff\xfe bad utf8\n",
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	for _, input := range roundTripCorpus {
+		f := Parse(input)
+		if got := Print(f); got != input {
+			t.Errorf("Print(Parse(%q)) = %q", input, got)
+		}
+		for _, line := range f.Lines {
+			for _, node := range line.Nodes {
+				if node.Text() == "" {
+					t.Errorf("Parse(%q) 产生了空节点 %s", input, node.Kind())
+				}
+			}
+		}
+	}
+}
+
+func TestParseRandomRoundTrip(t *testing.T) {
+	alphabet := []string{
+		"flask", "==", ">=", "1.0", " ", "\t", "\\", "\n", "\r\n", "\r", "#", ";", "'", "\"",
+		"[", "]", ",", "@", "-e", "-r", "--hash=", "sha256:ab", "://", "/", ".", "~", "=", "x",
+	}
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 5000; i++ {
+		var sb strings.Builder
+		for j := rng.Intn(20); j >= 0; j-- {
+			sb.WriteString(alphabet[rng.Intn(len(alphabet))])
+		}
+		input := sb.String()
+		if got := Print(Parse(input)); got != input {
+			t.Fatalf("Print(Parse(%q)) = %q", input, got)
+		}
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	for _, input := range roundTripCorpus {
+		f.Add(input)
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		if got := Print(Parse(input)); got != input {
+			t.Fatalf("Print(Parse(%q)) = %q", input, got)
+		}
+	})
+}
+
+// kinds 返回行内非空白节点的类型和文本
+func kinds(line *Line) []string {
+	var result []string
+	for _, node := range line.Nodes {
+		if isTrivia(node) {
+			continue
+		}
+		result = append(result, node.Kind().String()+":"+node.Text())
+	}
+	return result
+}
+
+func TestParseLineNodes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{
+			input:    "flask==2.0.1",
+			expected: []string{"Name:flask", "Specifier:==2.0.1"},
+		},
+		{
+			input:    "flask >= 1.0, < 2.0  # web",
+			expected: []string{"Name:flask", "Specifier:>= 1.0, < 2.0", "Comment:# web"},
+		},
+		{
+			input:    "uvicorn [standard] ; python_version >= '3.8'",
+			expected: []string{"Name:uvicorn", "Extras:[standard]", "Marker:; python_version >= '3.8'"},
+		},
+		{
+			input:    "pkg;extra == 'a #b'  # c",
+			expected: []string{"Name:pkg", "Marker:;extra == 'a #b'", "Comment:# c"},
+		},
+		{
+			input:    "django==3.2 \\\n    --hash=sha256:abcd",
+			expected: []string{"Name:django", "Specifier:==3.2", "Hash:--hash=sha256:abcd"},
+		},
+		{
+			input:    "requests[socks] @ https://example.com/r.zip ; os_name == 'nt'",
+			expected: []string{"Name:requests", "Extras:[socks]", "At:@", "Reference:https://example.com/r.zip", "Marker:; os_name == 'nt'"},
+		},
+		{
+			input:    "https://example.com/a;b.whl ; os_name == 'nt'",
+			expected: []string{"Reference:https://example.com/a;b.whl", "Marker:; os_name == 'nt'"},
+		},
+		{
+			input:    "pkg ; os_name == 'nt' --no-deps",
+			expected: []string{"Name:pkg", "Marker:; os_name == 'nt'", "Option:--no-deps"},
+		},
+		{
+			input:    "-e ./local/pkg[dev]",
+			expected: []string{"Option:-e", "Reference:./local/pkg", "Extras:[dev]"},
+		},
+		{
+			input:    "-e git+https://github.com/u/p.git#egg=p[dev]",
+			expected: []string{"Option:-e", "Reference:git+https://github.com/u/p.git#egg=p[dev]"},
+		},
+		{
+			input:    "--index-url https://pypi.org/simple",
+			expected: []string{"Option:--index-url https://pypi.org/simple"},
+		},
+		{
+			input:    "-r base.txt # base",
+			expected: []string{"Option:-r base.txt", "Comment:# base"},
+		},
+		{
+			input:    "C:\\pkgs\\pkg.whl",
+			expected: []string{"Reference:C:\\pkgs\\pkg.whl"},
+		},
+		{
+			input:    "pkg-1.0.tar.gz",
+			expected: []string{"Reference:pkg-1.0.tar.gz"},
+		},
+		{
+			input:    "https://example.com/p.zip#egg=p",
+			expected: []string{"Reference:https://example.com/p.zip#egg=p"},
+		},
+		{
+			input:    "  # only comment",
+			expected: []string{"Comment:# only comment"},
+		},
+		{
+			input:    "[oops",
+			expected: []string{"Unknown:[oops"},
+		},
+	}
+
+	for _, tt := range tests {
+		line := ParseLine(tt.input)
+		if got := kinds(line); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("ParseLine(%q) = %q, 期望 %q", tt.input, got, tt.expected)
+		}
+		if line.Text() != tt.input {
+			t.Errorf("ParseLine(%q).Text() = %q", tt.input, line.Text())
+		}
+	}
+}
+
+func TestParseLogicalLines(t *testing.T) {
+	input := "# header\r\nflask==1.0 \\\r\n  --hash=sha256:ab\r\n\r\nrequests \\"
+	f := Parse(input)
+
+	if len(f.Lines) != 4 {
+		t.Fatalf("期望4个逻辑行，实际为%d", len(f.Lines))
+	}
+
+	expectedNewlines := []string{"\r\n", "\r\n", "\r\n", ""}
+	for i, line := range f.Lines {
+		if line.Newline != expectedNewlines[i] {
+			t.Errorf("第%d行的换行符为%q，期望%q", i, line.Newline, expectedNewlines[i])
+		}
+	}
+
+	expectedNumbers := []int{1, 2, 4, 5}
+	for i, expected := range expectedNumbers {
+		if got := f.LineNumber(i); got != expected {
+			t.Errorf("LineNumber(%d) = %d，期望%d", i, got, expected)
+		}
+	}
+
+	if !f.Lines[0].IsComment() || !f.Lines[2].IsBlank() {
+		t.Errorf("注释行或空行识别错误")
+	}
+	if f.Lines[3].Name() == nil || f.Lines[3].Name().Text() != "requests" {
+		t.Errorf("最后一行的包名识别错误: %q", kinds(f.Lines[3]))
+	}
+}
+
+func TestParseCommentedContinuation(t *testing.T) {
+	// 与pip一致：行内注释后的"\"合并下一行，整行注释后的"\"不合并
+	input := "flask==1.0 # c \\\nrequests\n# note \\\nsix\n"
+	f := Parse(input)
+
+	if len(f.Lines) != 3 {
+		t.Fatalf("期望3个逻辑行，实际为%d", len(f.Lines))
+	}
+	if f.Lines[0].Name() == nil || f.Lines[0].Comment() == nil || f.Lines[0].Comment().Text() != "# c \\\nrequests" {
+		t.Errorf("第1行的注释应包含下一行: %q", kinds(f.Lines[0]))
+	}
+	if !f.Lines[1].IsComment() {
+		t.Errorf("第2行应为注释行: %q", kinds(f.Lines[1]))
+	}
+	if f.Lines[2].Name() == nil || f.Lines[2].Name().Text() != "six" {
+		t.Errorf("第3行的包名识别错误: %q", kinds(f.Lines[2]))
+	}
+	if Print(f) != input {
+		t.Errorf("Print(Parse(input)) = %q", Print(f))
+	}
+}
@@ -0,0 +1,250 @@
+// Package diff 生成统一格式（unified）的文本差异
+//
+// Diff比较两段文本，返回结构化的差异块列表，Result.String输出与"diff -u"相同格式的文本，
+// 可以直接交给patch、git apply或代码审查工具使用。
+//
+// 示例:
+//
+//	result := diff.Diff("flask==1.0.0\n", "flask==2.0.1\n", &diff.Options{
+//		FromFile: "a/requirements.txt",
+//		ToFile:   "b/requirements.txt",
+//		Context:  3,
+//	})
+//	fmt.Print(result)
+//	// --- a/requirements.txt
+//	// +++ b/requirements.txt
+//	// @@ -1 +1 @@
+//	// -flask==1.0.0
+//	// +flask==2.0.1
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultContext 默认的上下文行数，与"diff -u"一致
+const DefaultContext = 3
+
+// Options 生成差异的选项
+type Options struct {
+	// FromFile 原文件在"---"头中的名称
+	FromFile string
+
+	// ToFile 新文件在"+++"头中的名称
+	ToFile string
+
+	// Context 每个差异块前后保留的未修改行数，0表示不保留
+	Context int
+}
+
+// DefaultOptions 返回默认选项：文件名为"a"和"b"，上下文为DefaultContext行
+func DefaultOptions() *Options {
+	return &Options{FromFile: "a", ToFile: "b", Context: DefaultContext}
+}
+
+// LineKind 差异中一行的类型
+type LineKind byte
+
+const (
+	// Equal 两边相同的上下文行
+	Equal LineKind = ' '
+
+	// Delete 只在原文本中出现的行
+	Delete LineKind = '-'
+
+	// Insert 只在新文本中出现的行
+	Insert LineKind = '+'
+)
+
+// Line 差异块中的一行
+type Line struct {
+	// Kind 行的类型
+	Kind LineKind
+
+	// Text 行的内容，不包括"\n"（CRLF文件中保留"\r"）
+	Text string
+
+	// NoNewline 该行是文本的最后一行且不以换行结尾
+	NoNewline bool
+}
+
+// Hunk 一个差异块
+//
+// 行号从1开始。范围为空（Count为0）时，Line与"diff -u"一致，为范围之前一行的行号。
+type Hunk struct {
+	// FromLine、FromCount 差异块在原文本中的起始行号和行数
+	FromLine, FromCount int
+
+	// ToLine、ToCount 差异块在新文本中的起始行号和行数
+	ToLine, ToCount int
+
+	// Lines 差异块中的行，包括上下文行
+	Lines []Line
+}
+
+// Header 返回差异块的头，如"@@ -1,3 +1,4 @@"
+func (h Hunk) Header() string {
+	return fmt.Sprintf("@@ -%s +%s @@", formatRange(h.FromLine, h.FromCount), formatRange(h.ToLine, h.ToCount))
+}
+
+// formatRange 按"diff -u"的规则格式化范围：只有一行时省略行数
+func formatRange(line, count int) string {
+	if count == 1 {
+		return fmt.Sprintf("%d", line)
+	}
+	return fmt.Sprintf("%d,%d", line, count)
+}
+
+// Result 两段文本的差异
+type Result struct {
+	// FromFile、ToFile 文件头中的名称
+	FromFile, ToFile string
+
+	// Hunks 差异块，按行号排列；两段文本相同时为空
+	Hunks []Hunk
+}
+
+// Equal 检查两段文本是否相同
+func (r *Result) Equal() bool {
+	return len(r.Hunks) == 0
+}
+
+// String 返回统一格式的差异文本，两段文本相同时返回空字符串
+//
+// 不以换行结尾的最后一行之后输出"\ No newline at end of file"。
+func (r *Result) String() string {
+	if r.Equal() {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", r.FromFile, r.ToFile)
+	for _, hunk := range r.Hunks {
+		sb.WriteString(hunk.Header())
+		sb.WriteByte('\n')
+		for _, line := range hunk.Lines {
+			sb.WriteByte(byte(line.Kind))
+			sb.WriteString(line.Text)
+			sb.WriteByte('\n')
+			if line.NoNewline {
+				sb.WriteString("\\ No newline at end of file\n")
+			}
+		}
+	}
+	return sb.String()
+}
+
+// Diff 比较两段文本，返回按行计算的差异
+//
+// 参数:
+//   - before: 原文本
+//   - after: 新文本
+//   - opts: 选项，为nil时使用DefaultOptions
+//
+// 返回:
+//   - *Result: 差异块列表，可以用String输出统一格式的文本
+func Diff(before, after string, opts *Options) *Result {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	context := opts.Context
+	if context < 0 {
+		context = 0
+	}
+
+	result := &Result{FromFile: opts.FromFile, ToFile: opts.ToFile}
+	if before == after {
+		return result
+	}
+
+	a, b := splitLines(before), splitLines(after)
+	result.Hunks = buildHunks(a, b, diffLines(a, b), context)
+	return result
+}
+
+// splitLines 将文本切分为行，每行保留末尾的"\n"
+func splitLines(text string) []string {
+	var lines []string
+	for len(text) > 0 {
+		idx := strings.IndexByte(text, '\n')
+		if idx == -1 {
+			lines = append(lines, text)
+			break
+		}
+		lines = append(lines, text[:idx+1])
+		text = text[idx+1:]
+	}
+	return lines
+}
+
+// newLine 根据带"\n"的原始行创建Line
+func newLine(kind LineKind, raw string) Line {
+	text := strings.TrimSuffix(raw, "\n")
+	return Line{Kind: kind, Text: text, NoNewline: len(text) == len(raw)}
+}
+
+// buildHunks 将编辑脚本按上下文行数分组为差异块
+func buildHunks(a, b []string, ops []edit, context int) []Hunk {
+	var hunks []Hunk
+	for start := 0; start < len(ops); {
+		// 跳过开头的相同行，找到下一处修改
+		for start < len(ops) && ops[start].kind == Equal {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+
+		// 修改之间相同的行不超过2*context时合并为一个差异块
+		end := start
+		for end < len(ops) {
+			if ops[end].kind != Equal {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == Equal {
+				run++
+			}
+			if run == len(ops) || run-end > 2*context {
+				break
+			}
+			end = run
+		}
+
+		first := start - context
+		if first < 0 {
+			first = 0
+		}
+		last := end + context
+		if last > len(ops) {
+			last = len(ops)
+		}
+
+		hunk := Hunk{FromLine: ops[first].a + 1, ToLine: ops[first].b + 1}
+		for _, op := range ops[first:last] {
+			switch op.kind {
+			case Equal:
+				hunk.Lines = append(hunk.Lines, newLine(Equal, a[op.a]))
+				hunk.FromCount++
+				hunk.ToCount++
+			case Delete:
+				hunk.Lines = append(hunk.Lines, newLine(Delete, a[op.a]))
+				hunk.FromCount++
+			case Insert:
+				hunk.Lines = append(hunk.Lines, newLine(Insert, b[op.b]))
+				hunk.ToCount++
+			}
+		}
+		if hunk.FromCount == 0 {
+			hunk.FromLine--
+		}
+		if hunk.ToCount == 0 {
+			hunk.ToLine--
+		}
+		hunks = append(hunks, hunk)
+		start = last
+	}
+	return hunks
+}
@@ -0,0 +1,202 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name    string
+		before  string
+		after   string
+		context int
+		want    string
+	}{
+		{
+			name:    "相同文本",
+			before:  "flask==1.0.0\n",
+			after:   "flask==1.0.0\n",
+			context: 3,
+			want:    "",
+		},
+		{
+			name:    "修改一行",
+			before:  "flask==1.0.0\nrequests\n",
+			after:   "flask==2.0.1\nrequests\n",
+			context: 3,
+			want:    "--- a\n+++ b\n@@ -1,2 +1,2 @@\n-flask==1.0.0\n+flask==2.0.1\n requests\n",
+		},
+		{
+			name:    "无上下文",
+			before:  "a\nb\nc\n",
+			after:   "a\nB\nc\n",
+			context: 0,
+			want:    "--- a\n+++ b\n@@ -2 +2 @@\n-b\n+B\n",
+		},
+		{
+			name:    "在末尾添加",
+			before:  "a\nb\n",
+			after:   "a\nb\nc\n",
+			context: 1,
+			want:    "--- a\n+++ b\n@@ -2 +2,2 @@\n b\n+c\n",
+		},
+		{
+			name:    "从空文本添加",
+			before:  "",
+			after:   "a\n",
+			context: 3,
+			want:    "--- a\n+++ b\n@@ -0,0 +1 @@\n+a\n",
+		},
+		{
+			name:    "删除全部",
+			before:  "a\nb\n",
+			after:   "",
+			context: 3,
+			want:    "--- a\n+++ b\n@@ -1,2 +0,0 @@\n-a\n-b\n",
+		},
+		{
+			name:    "无上下文时删除的范围",
+			before:  "a\nb\nc\n",
+			after:   "a\nc\n",
+			context: 0,
+			want:    "--- a\n+++ b\n@@ -2 +1,0 @@\n-b\n",
+		},
+		{
+			name:    "末尾没有换行",
+			before:  "a\nb",
+			after:   "a\nb\n",
+			context: 3,
+			want:    "--- a\n+++ b\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+		{
+			name:    "删除排在插入之前",
+			before:  "a\nx\ny\nb\n",
+			after:   "a\nX\nY\nb\n",
+			context: 0,
+			want:    "--- a\n+++ b\n@@ -2,2 +2,2 @@\n-x\n-y\n+X\n+Y\n",
+		},
+		{
+			name:    "CRLF行保留回车",
+			before:  "a\r\nb\r\n",
+			after:   "a\r\nc\r\n",
+			context: 0,
+			want:    "--- a\n+++ b\n@@ -2 +2 @@\n-b\r\n+c\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Diff(tt.before, tt.after, &Options{FromFile: "a", ToFile: "b", Context: tt.context}).String()
+			if got != tt.want {
+				t.Errorf("Diff() =\n%q\n期望\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiff_Hunks(t *testing.T) {
+	var before, after []string
+	for i := 1; i <= 20; i++ {
+		line := "pkg" + string(rune('a'+i-1))
+		before = append(before, line)
+		after = append(after, line)
+	}
+	after[1] = "changed-b"
+	after[17] = "changed-r"
+
+	result := Diff(strings.Join(before, "\n")+"\n", strings.Join(after, "\n")+"\n", nil)
+	if len(result.Hunks) != 2 {
+		t.Fatalf("期望2个差异块，实际%d个:\n%s", len(result.Hunks), result)
+	}
+
+	first := result.Hunks[0]
+	if first.Header() != "@@ -1,5 +1,5 @@" {
+		t.Errorf("第一个差异块头 = %q", first.Header())
+	}
+	second := result.Hunks[1]
+	if second.Header() != "@@ -15,6 +15,6 @@" {
+		t.Errorf("第二个差异块头 = %q", second.Header())
+	}
+
+	var kinds string
+	for _, line := range first.Lines {
+		kinds += string(line.Kind)
+	}
+	if kinds != " -+   " {
+		t.Errorf("第一个差异块的行类型 = %q", kinds)
+	}
+
+	// 上下文足够大时两处修改合并为一个差异块
+	merged := Diff(strings.Join(before, "\n")+"\n", strings.Join(after, "\n")+"\n", &Options{Context: 8})
+	if len(merged.Hunks) != 1 {
+		t.Errorf("上下文为8时期望1个差异块，实际%d个", len(merged.Hunks))
+	}
+}
+
+func TestDiff_DefaultOptions(t *testing.T) {
+	result := Diff("a\n", "b\n", nil)
+	if result.FromFile != "a" || result.ToFile != "b" {
+		t.Errorf("默认文件名 = %q, %q", result.FromFile, result.ToFile)
+	}
+	if result.Equal() {
+		t.Error("Equal() = true，期望false")
+	}
+	if !Diff("a\n", "a\n", nil).Equal() {
+		t.Error("相同文本的Equal() = false")
+	}
+}
+
+func TestDiff_MatchesLCS(t *testing.T) {
+	// 编辑脚本应用到原文本后得到新文本，且修改行数最少
+	tests := []struct{ before, after string }{
+		{"a\nb\nc\na\nb\nb\na\n", "c\nb\na\nb\na\nc\n"},
+		{"x\ny\nz\n", "z\ny\nx\n"},
+		{"1\n2\n3\n4\n5\n", "1\n3\n5\n6\n"},
+	}
+	for _, tt := range tests {
+		a, b := splitLines(tt.before), splitLines(tt.after)
+		ops := diffLines(a, b)
+
+		var rebuilt strings.Builder
+		changes := 0
+		for _, op := range ops {
+			switch op.kind {
+			case Equal:
+				rebuilt.WriteString(a[op.a])
+			case Insert:
+				rebuilt.WriteString(b[op.b])
+				changes++
+			case Delete:
+				changes++
+			}
+		}
+		if rebuilt.String() != tt.after {
+			t.Errorf("编辑脚本结果 = %q, 期望 %q", rebuilt.String(), tt.after)
+		}
+		if want := len(a) + len(b) - 2*lcs(a, b); changes != want {
+			t.Errorf("%q -> %q: 修改%d行, 最少%d行", tt.before, tt.after, changes, want)
+		}
+	}
+}
+
+// lcs 用动态规划计算最长公共子序列的长度
+func lcs(a, b []string) int {
+	dp := make([][]int, len(a)+1)
+	for i := range dp {
+		dp[i] = make([]int, len(b)+1)
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			switch {
+			case a[i-1] == b[j-1]:
+				dp[i][j] = dp[i-1][j-1] + 1
+			case dp[i-1][j] > dp[i][j-1]:
+				dp[i][j] = dp[i-1][j]
+			default:
+				dp[i][j] = dp[i][j-1]
+			}
+		}
+	}
+	return dp[len(a)][len(b)]
+}
@@ -0,0 +1,140 @@
+package diff
+
+// edit 编辑脚本中的一步
+//
+// a、b为该步之前两段文本中已处理的行数，即Equal和Delete对应a中的第a行（从0开始），
+// Equal和Insert对应b中的第b行。
+type edit struct {
+	kind LineKind
+	a, b int
+}
+
+// diffLines 使用Myers算法计算把a变为b的最短编辑脚本
+//
+// 先去掉两端相同的行，中间部分的时间复杂度为O((N+M)D)，D为不同的行数。
+// 删除总是排在同一位置的插入之前，与"diff -u"的输出一致。
+func diffLines(a, b []string) []edit {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []edit
+	for i := 0; i < prefix; i++ {
+		ops = append(ops, edit{Equal, i, i})
+	}
+	for _, op := range myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]) {
+		ops = append(ops, edit{op.kind, op.a + prefix, op.b + prefix})
+	}
+	for i := suffix; i > 0; i-- {
+		ops = append(ops, edit{Equal, len(a) - i, len(b) - i})
+	}
+	return ops
+}
+
+// myers 返回把a变为b的最短编辑脚本
+func myers(a, b []string) []edit {
+	n, m := len(a), len(b)
+	max := n + m
+	if max == 0 {
+		return nil
+	}
+
+	// v[k+offset]为对角线k上能到达的最远的x；trace保存每一步之前的v，用于回溯
+	offset := max
+	v := make([]int, 2*max+2)
+	var trace [][]int
+
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[k-1+offset] < v[k+1+offset]) {
+				x = v[k+1+offset] // 插入
+			} else {
+				x = v[k-1+offset] + 1 // 删除
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[k+offset] = x
+			if x >= n && y >= m {
+				return backtrack(trace, n, m, offset)
+			}
+		}
+	}
+	return nil
+}
+
+// backtrack 根据每一步的v回溯出编辑脚本
+func backtrack(trace [][]int, n, m, offset int) []edit {
+	var ops []edit
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[k-1+offset] < v[k+1+offset]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[prevK+offset]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, edit{Equal, x, y})
+		}
+		if x == prevX {
+			y--
+			ops = append(ops, edit{Insert, x, y})
+		} else {
+			x--
+			ops = append(ops, edit{Delete, x, y})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		ops = append(ops, edit{Equal, x, y})
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return reorder(ops)
+}
+
+// reorder 把连续修改中的删除移到插入之前
+func reorder(ops []edit) []edit {
+	for start := 0; start < len(ops); {
+		if ops[start].kind == Equal {
+			start++
+			continue
+		}
+		end := start
+		for end < len(ops) && ops[end].kind != Equal {
+			end++
+		}
+		var dels, ins []edit
+		for _, op := range ops[start:end] {
+			if op.kind == Delete {
+				dels = append(dels, op)
+			} else {
+				ins = append(ins, op)
+			}
+		}
+		copy(ops[start:], dels)
+		copy(ops[start+len(dels):], ins)
+		start = end
+	}
+	return ops
+}
@@ -0,0 +1,46 @@
+package editor
+
+import (
+	"github.com/scagogogo/python-requirements-parser/pkg/diff"
+)
+
+// Original 返回文档解析时的文本（不含BOM）
+//
+// 之后的编辑、撤销和事务回滚都不会改变该文本。
+func (d *Document) Original() string {
+	return d.original
+}
+
+// Diff 返回从解析时的文本到当前文本的统一格式差异
+//
+// 使用diff.DefaultOptions：文件名为"a"和"b"，上下文为3行。文档未修改时结果为空。
+//
+// 示例:
+//
+//	doc.SetVersion("flask", "==2.0.1")
+//	fmt.Print(doc.Diff())
+//	// --- a
+//	// +++ b
+//	// @@ -1 +1 @@
+//	// -flask==1.0.0
+//	// +flask==2.0.1
+func (d *Document) Diff() *diff.Result {
+	return d.DiffWithOptions(nil)
+}
+
+// DiffWithOptions 使用指定的文件名和上下文行数返回从解析时的文本到当前文本的差异
+//
+// opts为nil时与Diff相同。
+func (d *Document) DiffWithOptions(opts *diff.Options) *diff.Result {
+	return diff.Diff(d.original, d.file.String(), opts)
+}
+
+// Diff 返回从解析时的文本到当前文本的统一格式差异，参见Document.Diff
+func (doc *PositionAwareDocument) Diff() *diff.Result {
+	return doc.doc.Diff()
+}
+
+// Diff 返回从解析时的文本到当前文本的统一格式差异，参见Document.Diff
+func (doc *RequirementsDocument) Diff() *diff.Result {
+	return doc.doc.Diff()
+}
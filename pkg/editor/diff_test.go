@@ -0,0 +1,77 @@
+package editor
+
+import (
+	"testing"
+
+	"github.com/scagogogo/python-requirements-parser/pkg/diff"
+)
+
+func TestDocumentDiff(t *testing.T) {
+	content := "\ufeff# deps\nflask==1.0.0\nrequests>=2.0\n"
+
+	doc, err := ParseDocument(content)
+	if err != nil {
+		t.Fatalf("ParseDocument失败: %v", err)
+	}
+	if !doc.Diff().Equal() {
+		t.Errorf("未修改的文档Diff() = %q", doc.Diff())
+	}
+	if doc.Original() != "# deps\nflask==1.0.0\nrequests>=2.0\n" {
+		t.Errorf("Original() = %q", doc.Original())
+	}
+
+	if err := doc.SetVersion("flask", "==2.0.1"); err != nil {
+		t.Fatalf("SetVersion失败: %v", err)
+	}
+	want := "--- a\n+++ b\n@@ -1,3 +1,3 @@\n # deps\n-flask==1.0.0\n+flask==2.0.1\n requests>=2.0\n"
+	if got := doc.Diff().String(); got != want {
+		t.Errorf("Diff() =\n%q\n期望\n%q", got, want)
+	}
+
+	opts := &diff.Options{FromFile: "a/requirements.txt", ToFile: "b/requirements.txt", Context: 0}
+	want = "--- a/requirements.txt\n+++ b/requirements.txt\n@@ -2 +2 @@\n-flask==1.0.0\n+flask==2.0.1\n"
+	if got := doc.DiffWithOptions(opts).String(); got != want {
+		t.Errorf("DiffWithOptions() =\n%q\n期望\n%q", got, want)
+	}
+
+	// 撤销后与原文本相同
+	if err := doc.Undo(); err != nil {
+		t.Fatalf("Undo失败: %v", err)
+	}
+	if !doc.Diff().Equal() {
+		t.Errorf("撤销后Diff() = %q", doc.Diff())
+	}
+}
+
+func TestEditorDiff(t *testing.T) {
+	content := "flask==1.0.0\nrequests>=2.0\n"
+	want := "--- a\n+++ b\n@@ -1,2 +1,2 @@\n-flask==1.0.0\n+flask==2.0.1\n requests>=2.0\n"
+
+	t.Run("PositionAwareEditor", func(t *testing.T) {
+		e := NewPositionAwareEditor()
+		doc, err := e.ParseRequirementsFile(content)
+		if err != nil {
+			t.Fatalf("解析失败: %v", err)
+		}
+		if err := e.UpdatePackageVersion(doc, "flask", "==2.0.1"); err != nil {
+			t.Fatalf("UpdatePackageVersion失败: %v", err)
+		}
+		if got := doc.Diff().String(); got != want {
+			t.Errorf("Diff() =\n%q\n期望\n%q", got, want)
+		}
+	})
+
+	t.Run("VersionEditorV2", func(t *testing.T) {
+		v := NewVersionEditorV2()
+		doc, err := v.ParseRequirementsFile(content)
+		if err != nil {
+			t.Fatalf("解析失败: %v", err)
+		}
+		if err := v.UpdatePackageVersion(doc, "flask", "==2.0.1"); err != nil {
+			t.Fatalf("UpdatePackageVersion失败: %v", err)
+		}
+		if got := doc.Diff().String(); got != want {
+			t.Errorf("Diff() =\n%q\n期望\n%q", got, want)
+		}
+	})
+}
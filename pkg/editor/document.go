@@ -0,0 +1,807 @@
+package editor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/scagogogo/python-requirements-parser/pkg/cst"
+	"github.com/scagogogo/python-requirements-parser/pkg/models"
+	"github.com/scagogogo/python-requirements-parser/pkg/parser"
+)
+
+// Document 保留原始格式的可编辑requirements文档
+//
+// Document基于cst包的无损语法树：每个逻辑行对应一个cst.Line和一个models.Requirement。
+// 所有编辑操作都直接修改语法树中受影响的节点，未修改的行和同一行中未修改的部分
+// （空白、行继续符、选项写法、注释等）在输出时保持原样，因此编辑产生最小化的diff。
+// 每次编辑后，对应的Requirement会根据修改后的行重新解析并原地更新，
+// 调用方持有的*models.Requirement指针始终有效。
+//
+// VersionEditorV2和PositionAwareEditor都是Document的包装。
+//
+// 使用示例:
+//
+//	doc, err := editor.ParseDocument("flask==1.0.0  # Web framework\nrequests\n")
+//	if err != nil {
+//	    // 处理错误
+//	}
+//	doc.SetVersion("flask", "==2.0.1")
+//	doc.SetMarkers("requests", "python_version >= '3.8'")
+//	fmt.Print(doc.String())
+//	// flask==2.0.1  # Web framework
+//	// requests ; python_version >= '3.8'
+type Document struct {
+	file     *cst.File
+	reqs     []*models.Requirement
+	encoding parser.Encoding
+	parser   *parser.Parser
+
+	// baseDir 文档所在目录，本地路径引用相对于该目录解析，为空时相对于当前工作目录
+	baseDir string
+
+	// original 解析时的文本（不含BOM），用于Diff
+	original string
+
+	// history 编辑记录和撤销、重做栈
+	history history
+}
+
+// ParseDocument 解析已解码的requirements文件内容
+//
+// 开头的UTF-8 BOM会被记录并在String中还原。
+func ParseDocument(content string) (*Document, error) {
+	text, enc := decodeText(content)
+	return newDocument(parser.New(), text, enc, "")
+}
+
+// ParseDocumentBytes 解析requirements文件的原始字节
+//
+// 编码按照pip的规则由BOM或PEP 263编码声明检测，Bytes会按原编码和BOM状态写回。
+func ParseDocumentBytes(data []byte) (*Document, error) {
+	text, enc, err := parser.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("解码requirements文件失败: %w", err)
+	}
+	return newDocument(parser.New(), text, enc, "")
+}
+
+// ParseDocumentFile 读取并解析requirements文件
+//
+// 与ParseDocumentBytes相同，但本地路径引用（如"-e ../lib"）的ResolvedPath
+// 相对于文件所在目录解析，编辑后重新解析的行同样如此。
+//
+// 示例:
+//
+//	doc, err := editor.ParseDocumentFile("requirements/dev.txt")
+//	if err != nil {
+//	    // 处理错误
+//	}
+//	os.WriteFile("requirements/dev.txt", []byte(doc.String()), 0644)
+func ParseDocumentFile(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	text, enc, err := parser.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("解码requirements文件失败: %w", err)
+	}
+
+	baseDir := filepath.Dir(path)
+	if absPath, err := filepath.Abs(path); err == nil {
+		baseDir = filepath.Dir(absPath)
+	}
+	return newDocument(parser.New(), text, enc, baseDir)
+}
+
+// newDocument 使用指定的parser解析已解码的文本
+//
+// baseDir为文档所在目录，用于解析本地路径引用，为空时相对于当前工作目录解析。
+func newDocument(p *parser.Parser, text string, enc parser.Encoding, baseDir string) (*Document, error) {
+	doc := &Document{
+		file:     cst.Parse(text),
+		encoding: enc,
+		parser:   p,
+		baseDir:  baseDir,
+		original: text,
+	}
+
+	doc.reqs = make([]*models.Requirement, len(doc.file.Lines))
+	for i, line := range doc.file.Lines {
+		req, err := doc.parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("解析requirements文件失败: %w", err)
+		}
+		doc.reqs[i] = req
+	}
+
+	return doc, nil
+}
+
+// parseLine 将一个逻辑行解析为requirement
+func (d *Document) parseLine(line *cst.Line) (*models.Requirement, error) {
+	text := line.Text()
+	reqs, err := d.parser.ParseStringInDir(text, d.baseDir)
+	if err != nil {
+		return nil, err
+	}
+	switch len(reqs) {
+	case 0:
+		return &models.Requirement{Kind: models.KindBlank, OriginalLine: text, IsEmpty: true}, nil
+	case 1:
+		return reqs[0], nil
+	}
+	return nil, fmt.Errorf("无效的逻辑行: %q", text)
+}
+
+// Requirements 返回文档中的所有逻辑行对应的requirement，按出现顺序排列
+//
+// 返回的切片是副本，但其中的*models.Requirement与文档共享，不应直接修改，
+// 而应使用Document的编辑方法。
+func (d *Document) Requirements() []*models.Requirement {
+	return append([]*models.Requirement(nil), d.reqs...)
+}
+
+// Len 返回文档中逻辑行的数量
+func (d *Document) Len() int {
+	return len(d.reqs)
+}
+
+// Index 返回与给定包名、本地路径或URL匹配的第一个requirement的位置，不存在时返回-1
+//
+// 包名按PEP 503规范化后比较，因此"Flask_Login"与"flask-login"匹配。
+func (d *Document) Index(name string) int {
+	for i, req := range d.reqs {
+		if matchesPackage(req, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Find 返回与给定包名、本地路径或URL匹配的第一个requirement，不存在时返回nil
+func (d *Document) Find(name string) *models.Requirement {
+	if i := d.Index(name); i != -1 {
+		return d.reqs[i]
+	}
+	return nil
+}
+
+// LineNumber 返回requirement在当前文档中的起始物理行号（从1开始），不存在时返回0
+func (d *Document) LineNumber(req *models.Requirement) int {
+	for i, r := range d.reqs {
+		if r == req {
+			return d.file.LineNumber(i)
+		}
+	}
+	return 0
+}
+
+// LineEnding 返回requirement所在逻辑行的行终止符
+//
+// 最后一行没有换行时以及requirement不存在时返回文档中占多数的行终止符。
+func (d *Document) LineEnding(req *models.Requirement) string {
+	for i, r := range d.reqs {
+		if r == req && d.file.Lines[i].Newline != "" {
+			return d.file.Lines[i].Newline
+		}
+	}
+	return d.DefaultLineEnding()
+}
+
+// DefaultLineEnding 返回文档中占多数的行终止符（"\n"或"\r\n"），用于新增的行
+func (d *Document) DefaultLineEnding() string {
+	endings := make([]string, len(d.file.Lines))
+	for i, line := range d.file.Lines {
+		endings[i] = line.Newline
+	}
+	return dominantLineEnding(endings)
+}
+
+// HasFinalNewline 返回文档是否以换行结尾
+func (d *Document) HasFinalNewline() bool {
+	return len(d.file.Lines) > 0 && d.file.Lines[len(d.file.Lines)-1].Newline != ""
+}
+
+// Encoding 返回文档原始的编码和BOM状态
+func (d *Document) Encoding() parser.Encoding {
+	return d.encoding
+}
+
+// String 返回文档的完整文本
+//
+// 对于带BOM的UTF-8文档，结果开头包含BOM，与Bytes的输出一致。
+func (d *Document) String() string {
+	return textWithBOM(d.file.String(), d.encoding)
+}
+
+// Bytes 将文档按原始编码和BOM状态编码为字节
+func (d *Document) Bytes() ([]byte, error) {
+	return parser.Encode(d.file.String(), d.encoding)
+}
+
+// SetVersion 设置包的版本约束，version为空时移除版本约束
+//
+// 已有版本约束时只替换约束本身；没有版本约束时插入到包名和extras之后、
+// 环境标记、选项和注释之前。只有普通包支持版本约束。
+//
+// 示例:
+//
+//	doc.SetVersion("flask", "==2.0.1")   // "flask[async] ; os_name == 'nt'" -> "flask[async]==2.0.1 ; os_name == 'nt'"
+//	doc.SetVersion("flask", "")          // 移除版本约束
+func (d *Document) SetVersion(name, version string) error {
+	defer d.record(OpUpdateVersion, name, version)()
+	if version != "" {
+		if err := validateVersionSpecifier(version); err != nil {
+			return err
+		}
+	}
+
+	i, err := d.lookup(name)
+	if err != nil {
+		return err
+	}
+	return d.setVersion(i, version)
+}
+
+// setVersion 设置第i行的版本约束，version已经过验证
+func (d *Document) setVersion(i int, version string) error {
+	name := d.reqs[i].Name
+	if d.reqs[i].EffectiveKind() != models.KindPackage {
+		return fmt.Errorf("本地路径或URL依赖不支持版本约束: %s", name)
+	}
+
+	if err := checkSingleLine("版本约束", version); err != nil {
+		return err
+	}
+
+	return d.edit(i, func(line *cst.Line) error {
+		spec := line.Specifier()
+		switch {
+		case version == "":
+			if spec != nil {
+				line.RemoveWithSpace(spec)
+			}
+		case spec != nil:
+			spec.Set(version)
+		default:
+			// 注意不能把可能为nil的*Extras直接赋给cst.Node，否则得到非nil的接口值
+			var anchor cst.Node
+			if extras := line.Extras(); extras != nil {
+				anchor = extras
+			} else if node := line.Name(); node != nil {
+				anchor = node
+			} else {
+				return fmt.Errorf("无法为 %s 设置版本约束", name)
+			}
+			line.InsertAfter(anchor, cst.NewSpecifier(version))
+		}
+		return nil
+	}, func(req *models.Requirement) error {
+		if models.NormalizeSpecifier(req.Version) != models.NormalizeSpecifier(version) {
+			return fmt.Errorf("无效的版本约束: %q", version)
+		}
+		return nil
+	})
+}
+
+// SetExtras 设置requirement的extras，extras为空时移除extras
+//
+// 已有extras时沿用原有的分隔风格；VCS和URL依赖的extras位于#egg=片段中，
+// 此时整行按规范格式重写。
+func (d *Document) SetExtras(name string, extras []string) error {
+	defer d.record(OpSetExtras, name, strings.Join(extras, ","))()
+	i, err := d.lookup(name)
+	if err != nil {
+		return err
+	}
+
+	for _, extra := range extras {
+		if err := checkSingleLine("extras", extra); err != nil {
+			return err
+		}
+	}
+
+	kind := d.reqs[i].EffectiveKind()
+	return d.edit(i, func(line *cst.Line) error {
+		node := line.Extras()
+		switch {
+		case len(extras) == 0 && node != nil:
+			line.Remove(node)
+		case len(extras) == 0:
+		case node != nil:
+			node.SetItems(extras)
+		case kind == models.KindPackage || kind == models.KindDirectRef:
+			if line.Name() == nil {
+				return fmt.Errorf("无法为 %s 设置extras", name)
+			}
+			line.InsertAfter(line.Name(), cst.NewExtras(extras))
+		case kind == models.KindLocalPath && line.Reference() != nil:
+			line.InsertAfter(line.Reference(), cst.NewExtras(extras))
+		default:
+			d.rewrite(i, func(req *models.Requirement) {
+				req.Extras = extras
+			})
+		}
+		return nil
+	}, func(req *models.Requirement) error {
+		if len(req.Extras) != len(extras) {
+			return fmt.Errorf("无效的extras: %q", extras)
+		}
+		for j, extra := range extras {
+			if req.Extras[j] != strings.TrimSpace(extra) {
+				return fmt.Errorf("无效的extras: %q", extras)
+			}
+		}
+		return nil
+	})
+}
+
+// SetMarkers 设置requirement的环境标记，markers为空时移除环境标记
+//
+// 新的环境标记以" ; "分隔插入到包规格之后、选项和注释之前。
+func (d *Document) SetMarkers(name, markers string) error {
+	defer d.record(OpSetMarkers, name, markers)()
+	i, err := d.lookup(name)
+	if err != nil {
+		return err
+	}
+
+	if err := checkSingleLine("环境标记", markers); err != nil {
+		return err
+	}
+
+	return d.edit(i, func(line *cst.Line) error {
+		node := line.Marker()
+		switch {
+		case markers == "":
+			if node != nil {
+				line.RemoveWithSpace(node)
+			}
+		case node != nil:
+			node.SetExpression(markers)
+		default:
+			index, ok := specEnd(line)
+			if !ok {
+				return fmt.Errorf("无法为 %s 设置环境标记", name)
+			}
+			line.Insert(index, cst.NewWhitespace(" "), cst.NewMarker(markers))
+		}
+		return nil
+	}, func(req *models.Requirement) error {
+		if models.NormalizeMarker(req.Markers) != models.NormalizeMarker(markers) {
+			return fmt.Errorf("无效的环境标记: %q", markers)
+		}
+		return nil
+	})
+}
+
+// SetHashes 替换requirement的全部哈希，hashes为空时移除所有哈希
+//
+// 新的哈希沿用原有哈希的排列方式：原来每个哈希位于单独的续行中
+// （pip-compile的格式）时，新的哈希同样各占一个续行并使用相同的缩进。
+// 原来没有哈希时，如果文档中的其他行使用多行格式，则同样使用多行格式。
+//
+// 示例:
+//
+//	doc.SetHashes("django", []string{"sha256:aaaa", "sha256:bbbb"})
+//	// django==3.2.0 \
+//	//     --hash=sha256:aaaa \
+//	//     --hash=sha256:bbbb
+func (d *Document) SetHashes(name string, hashes []string) error {
+	i, err := d.lookup(name)
+	if err != nil {
+		return err
+	}
+	return d.SetHashesAt(i, hashes)
+}
+
+// SetHashesAt 替换第index个requirement的全部哈希，其余与SetHashes相同
+//
+// 同一个包按环境标记拆分为多行时（如"numpy==1.0 ; python_version<'3.8'"和
+// "numpy==1.1 ; python_version>='3.8'"），SetHashes只能找到第一行，
+// 此时使用Requirements()中的位置指定要修改的行。
+func (d *Document) SetHashesAt(index int, hashes []string) error {
+	if index < 0 || index >= len(d.reqs) {
+		return fmt.Errorf("位置超出范围: %d", index)
+	}
+	name := d.reqs[index].Key()
+	defer d.record(OpSetHashes, name, strings.Join(hashes, " "))()
+	if !d.reqs[index].IsInstallable() {
+		return fmt.Errorf("%s 不是可安装的依赖", name)
+	}
+	for _, hash := range hashes {
+		if err := validateHash(hash); err != nil {
+			return err
+		}
+	}
+	return d.setHashes(index, hashes)
+}
+
+// setHashes 替换第i行的全部哈希，hashes已经过校验
+func (d *Document) setHashes(i int, hashes []string) error {
+	separator := d.hashSeparator()
+	return d.edit(i, func(line *cst.Line) error {
+		existing := line.Hashes()
+
+		// 确定插入位置和每个哈希前的分隔符
+		var index int
+		if len(existing) > 0 {
+			index = line.Index(existing[0])
+			start := index
+			for start > 0 && isTriviaNode(line.Nodes[start-1]) {
+				start--
+			}
+			separator = ""
+			for _, node := range line.Nodes[start:index] {
+				separator += node.Text()
+			}
+			index = start
+			for _, hash := range existing {
+				line.RemoveWithSpace(hash)
+			}
+		} else {
+			var ok bool
+			if index, ok = contentEnd(line); !ok {
+				return fmt.Errorf("无法为 %s 设置哈希", d.reqs[i].Name)
+			}
+		}
+
+		var nodes []cst.Node
+		for _, hash := range hashes {
+			nodes = append(nodes, cst.ParseLine(separator).Nodes...)
+			nodes = append(nodes, cst.NewHash(hash))
+		}
+		line.Insert(index, nodes...)
+		return nil
+	}, nil)
+}
+
+// hashSeparator 返回新增哈希前使用的分隔符
+//
+// 文档中已有多行格式的哈希时返回相同的续行写法（如" \\\n    "），否则返回单个空格。
+func (d *Document) hashSeparator() string {
+	for _, line := range d.file.Lines {
+		for _, hash := range line.Hashes() {
+			index := line.Index(hash)
+			start := index
+			for start > 0 && isTriviaNode(line.Nodes[start-1]) {
+				start--
+			}
+			var sb strings.Builder
+			continued := false
+			for _, node := range line.Nodes[start:index] {
+				continued = continued || node.Kind() == cst.KindContinuation
+				sb.WriteString(node.Text())
+			}
+			if continued {
+				return sb.String()
+			}
+		}
+	}
+	return " "
+}
+
+// validateHash 检查哈希是否为解析器接受的"算法:十六进制摘要"格式，如"sha256:ab12"
+func validateHash(hash string) error {
+	if !parser.ValidHash(hash) {
+		return fmt.Errorf("无效的哈希: %s", hash)
+	}
+	return nil
+}
+
+// SetComment 设置requirement的行内注释，comment为空时移除注释
+//
+// 已有注释时只替换注释内容，保留"#"及其后的空白；没有注释时追加到行尾，
+// "#"前的空白沿用文档中已有行内注释的写法（默认为两个空格）。
+//
+// 示例:
+//
+//	doc.SetComment("flask", "pinned for CVE-2023-30861")  // "flask==2.3.2" -> "flask==2.3.2  # pinned for CVE-2023-30861"
+//	doc.SetComment("flask", "")                           // 移除注释
+func (d *Document) SetComment(name, comment string) error {
+	defer d.record(OpSetComment, name, comment)()
+	if err := checkSingleLine("注释", comment); err != nil {
+		return err
+	}
+
+	i, err := d.lookup(name)
+	if err != nil {
+		return err
+	}
+
+	separator := d.commentSeparator()
+	return d.edit(i, func(line *cst.Line) error {
+		node := line.Comment()
+		switch {
+		case comment == "":
+			if node != nil {
+				line.RemoveWithSpace(node)
+			}
+		case node != nil:
+			node.SetContent(comment)
+		default:
+			// 行尾已有空白时直接使用，否则插入分隔空白
+			end := len(line.Nodes)
+			for end > 0 && line.Nodes[end-1].Kind() == cst.KindWhitespace {
+				end--
+			}
+			if end < len(line.Nodes) {
+				line.Insert(len(line.Nodes), cst.NewComment(comment))
+			} else {
+				line.Insert(end, cst.NewWhitespace(separator), cst.NewComment(comment))
+			}
+		}
+		return nil
+	}, nil)
+}
+
+// commentSeparator 返回新增行内注释前使用的空白
+//
+// 使用文档中第一个行内注释前的空白，没有行内注释时返回两个空格。
+func (d *Document) commentSeparator() string {
+	for _, line := range d.file.Lines {
+		node := line.Comment()
+		if node == nil || line.IsComment() {
+			continue
+		}
+		if index := line.Index(node); line.Nodes[index-1].Kind() == cst.KindWhitespace {
+			return line.Nodes[index-1].Text()
+		}
+	}
+	return "  "
+}
+
+// SetOption 设置requirement的选项，如"global-option"、"config-settings"
+//
+// 已有该选项时只替换选项值，否则以"--option=value"的形式插入到环境标记之后、
+// 哈希和注释之前。
+func (d *Document) SetOption(name, option, value string) error {
+	defer d.record(OpSetOption, name, option+"="+value)()
+	option = strings.TrimLeft(option, "-")
+	if option == "" || option == "hash" {
+		return fmt.Errorf("无效的选项: %s", option)
+	}
+	if err := checkSingleLine("选项值", value); err != nil {
+		return err
+	}
+
+	i, err := d.lookup(name)
+	if err != nil {
+		return err
+	}
+	if !d.reqs[i].IsInstallable() {
+		return fmt.Errorf("%s 不是可安装的依赖", name)
+	}
+
+	return d.edit(i, func(line *cst.Line) error {
+		if node := line.Option("--" + option); node != nil {
+			node.SetValue(value)
+			return nil
+		}
+		index, ok := contentEnd(line)
+		if !ok {
+			return fmt.Errorf("无法为 %s 设置选项", name)
+		}
+		node := &cst.Option{Flag: "--" + option}
+		if value != "" {
+			node.Separator, node.Value = "=", value
+		}
+		line.Insert(index, cst.NewWhitespace(" "), node)
+		return nil
+	}, func(req *models.Requirement) error {
+		want := value
+		if want == "" {
+			want = "true"
+		}
+		if got, ok := req.RequirementOptions[option]; !ok || got != want {
+			return fmt.Errorf("无效的选项值: %q", value)
+		}
+		return nil
+	})
+}
+
+// RemoveOption 移除requirement的选项，选项不存在时不做任何修改
+func (d *Document) RemoveOption(name, option string) error {
+	defer d.record(OpRemoveOption, name, option)()
+	i, err := d.lookup(name)
+	if err != nil {
+		return err
+	}
+
+	return d.edit(i, func(line *cst.Line) error {
+		if node := line.Option("--" + strings.TrimLeft(option, "-")); node != nil {
+			line.RemoveWithSpace(node)
+		}
+		return nil
+	}, nil)
+}
+
+// Add 在文档末尾添加一行，返回解析得到的requirement
+//
+// text为一个逻辑行的内容，如"requests>=2.25.0  # HTTP library"。
+// 新行使用文档中占多数的行终止符，并保持文档是否以换行结尾的状态。
+func (d *Document) Add(text string) (*models.Requirement, error) {
+	return d.Insert(len(d.reqs), text)
+}
+
+// Insert 在指定位置（从0开始的逻辑行序号）插入一行，返回解析得到的requirement
+func (d *Document) Insert(index int, text string) (*models.Requirement, error) {
+	defer d.record(OpAdd, "", text)()
+	if index < 0 || index > len(d.reqs) {
+		return nil, fmt.Errorf("插入位置超出范围: %d", index)
+	}
+	if f := cst.Parse(text); len(f.Lines) != 1 || f.Lines[0].Newline != "" {
+		return nil, fmt.Errorf("只能插入单个逻辑行: %q", text)
+	}
+
+	line := cst.ParseLine(text)
+	req, err := d.parseLine(line)
+	if err != nil {
+		return nil, err
+	}
+
+	d.insertLine(index, line, req)
+	return req, nil
+}
+
+// insertLine 在指定位置插入行，并维护行终止符和文件结尾换行的状态
+func (d *Document) insertLine(index int, line *cst.Line, req *models.Requirement) {
+	line.Newline = d.DefaultLineEnding()
+	if index == len(d.file.Lines) && !d.HasFinalNewline() {
+		// 文件原来不以换行结尾：新行成为最后一行，前一行补上换行
+		line.Newline = ""
+		if index > 0 {
+			d.file.Lines[index-1].Newline = d.DefaultLineEnding()
+		}
+	}
+
+	d.file.InsertLine(index, line)
+	d.reqs = append(d.reqs, nil)
+	copy(d.reqs[index+1:], d.reqs[index:])
+	d.reqs[index] = req
+}
+
+// Remove 移除与给定包名、本地路径或URL匹配的第一个requirement所在的行
+func (d *Document) Remove(name string) error {
+	defer d.record(OpRemove, name, "")()
+	i, err := d.lookup(name)
+	if err != nil {
+		return err
+	}
+	d.removeLine(i)
+	return nil
+}
+
+// removeLine 移除指定位置的行，并维护文件结尾换行的状态
+func (d *Document) removeLine(index int) (*cst.Line, *models.Requirement) {
+	line, req := d.file.Lines[index], d.reqs[index]
+	last := index == len(d.file.Lines)-1
+
+	d.file.RemoveLine(index)
+	d.reqs = append(d.reqs[:index], d.reqs[index+1:]...)
+
+	// 移除的最后一行没有换行时，新的最后一行同样不以换行结尾
+	if last && line.Newline == "" && index > 0 {
+		d.file.Lines[index-1].Newline = ""
+	}
+	return line, req
+}
+
+// Move 将requirement所在的行移动到指定位置（移动后的逻辑行序号，从0开始）
+func (d *Document) Move(name string, index int) error {
+	defer d.record(OpMove, name, strconv.Itoa(index))()
+	i, err := d.lookup(name)
+	if err != nil {
+		return err
+	}
+	if index < 0 || index >= len(d.reqs) {
+		return fmt.Errorf("移动位置超出范围: %d", index)
+	}
+	if index == i {
+		return nil
+	}
+
+	line, req := d.removeLine(i)
+	d.insertLine(index, line, req)
+	return nil
+}
+
+// lookup 返回匹配的requirement的位置，不存在时返回错误
+func (d *Document) lookup(name string) (int, error) {
+	i := d.Index(name)
+	if i == -1 {
+		return -1, fmt.Errorf("在requirements中未找到包: %s", name)
+	}
+	return i, nil
+}
+
+// edit 修改第index行，重新解析后原地更新requirement，保持指针不变
+//
+// mutate修改该行的一个副本。新的值中可能含有"#"、不配对的括号等改变行结构的内容，
+// 因此重新解析后由check检查修改的部分是否与期望一致（check可以为nil）。
+// mutate或check返回错误、或者修改后的行不再是单个requirement时，原来的行保持不变，
+// 文档与Requirements()保持一致，也不会产生编辑记录。
+func (d *Document) edit(index int, mutate func(line *cst.Line) error, check func(req *models.Requirement) error) error {
+	original := d.file.Lines[index]
+	line := cst.ParseLine(original.Text())
+	line.Newline = original.Newline
+	d.file.Lines[index] = line
+
+	err := mutate(line)
+	var req *models.Requirement
+	if err == nil {
+		req, err = d.parseLine(d.file.Lines[index])
+	}
+	if err == nil && check != nil {
+		err = check(req)
+	}
+	if err != nil {
+		d.file.Lines[index] = original
+		return err
+	}
+
+	// 按新的文本重新切分节点，后续的编辑看到的语法树与文本一致
+	line = cst.ParseLine(d.file.Lines[index].Text())
+	line.Newline = original.Newline
+	d.file.Lines[index] = line
+	*d.reqs[index] = *req
+	return nil
+}
+
+// rewrite 修改requirement后按规范格式重写整行，行终止符保持不变
+//
+// 用于语法树无法局部修改的情况，如VCS URL中#egg=片段里的extras。只能在edit的mutate中调用。
+func (d *Document) rewrite(index int, mutate func(req *models.Requirement)) {
+	req := d.reqs[index].Clone()
+	mutate(req)
+
+	line := cst.ParseLine(req.String())
+	line.Newline = d.file.Lines[index].Newline
+	d.file.Lines[index] = line
+}
+
+// checkSingleLine 检查要写入行中的值不包含换行
+func checkSingleLine(what, value string) error {
+	if strings.ContainsAny(value, "\r\n") {
+		return fmt.Errorf("%s不能包含换行: %q", what, value)
+	}
+	return nil
+}
+
+// specEnd 返回行中包规格（包名、extras、版本约束或URL）之后的位置
+func specEnd(line *cst.Line) (int, bool) {
+	end := -1
+	for i, node := range line.Nodes {
+		switch node.Kind() {
+		case cst.KindName, cst.KindExtras, cst.KindSpecifier, cst.KindAt, cst.KindReference:
+			end = i + 1
+		}
+	}
+	return end, end != -1
+}
+
+// contentEnd 返回行中包规格、环境标记和选项之后的位置，即哈希和注释之前
+func contentEnd(line *cst.Line) (int, bool) {
+	end, ok := specEnd(line)
+	if !ok {
+		return -1, false
+	}
+	for i := end; i < len(line.Nodes); i++ {
+		switch line.Nodes[i].Kind() {
+		case cst.KindMarker, cst.KindOption:
+			end = i + 1
+		case cst.KindHash, cst.KindComment:
+			return end, true
+		}
+	}
+	return end, true
+}
+
+// isTriviaNode 检查节点是否为空白或行继续符
+func isTriviaNode(node cst.Node) bool {
+	return node.Kind() == cst.KindWhitespace || node.Kind() == cst.KindContinuation
+}
@@ -0,0 +1,408 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDocument_RoundTrip(t *testing.T) {
+	inputs := []string{
+		"",
+		"flask==1.0.0",
+		"\ufeff# comment\r\nflask  >= 1.0 ;python_version<'3.8'   # web\r\n",
+		"django==3.2.0 \\\n    --hash=sha256:aaaa \\\n    --hash=sha256:bbbb\n\n-r base.txt\n",
+		"--index-url https://pypi.example.com/simple\n-e git+https://github.com/user/project.git#egg=project\n",
+	}
+
+	for _, input := range inputs {
+		doc, err := ParseDocument(input)
+		if err != nil {
+			t.Fatalf("ParseDocument(%q)失败: %v", input, err)
+		}
+		if got := doc.String(); got != input {
+			t.Errorf("String() = %q, 期望 %q", got, input)
+		}
+	}
+}
+
+func TestDocument_Edits(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		edit     func(doc *Document) error
+		expected string
+	}{
+		{
+			name:     "Replace version",
+			content:  "flask  ==  1.0.0   # web\nrequests\n",
+			edit:     func(doc *Document) error { return doc.SetVersion("flask", "==2.0.1") },
+			expected: "flask  ==2.0.1   # web\nrequests\n",
+		},
+		{
+			name:     "Insert version after extras and before markers",
+			content:  "flask[async] ; python_version >= '3.8'  # web\n",
+			edit:     func(doc *Document) error { return doc.SetVersion("flask", "==2.0.1") },
+			expected: "flask[async]==2.0.1 ; python_version >= '3.8'  # web\n",
+		},
+		{
+			name:     "Insert version after name and before options",
+			content:  "numpy --global-option=x ; os_name == 'nt'\n",
+			edit:     func(doc *Document) error { return doc.SetVersion("numpy", ">=1.21") },
+			expected: "numpy>=1.21 --global-option=x ; os_name == 'nt'\n",
+		},
+		{
+			name:     "Remove version",
+			content:  "flask >= 1.0 ; os_name == 'nt'\n",
+			edit:     func(doc *Document) error { return doc.SetVersion("flask", "") },
+			expected: "flask ; os_name == 'nt'\n",
+		},
+		{
+			name:     "Normalized name lookup",
+			content:  "Flask_Login==0.5\n",
+			edit:     func(doc *Document) error { return doc.SetVersion("flask-login", "==0.6.2") },
+			expected: "Flask_Login==0.6.2\n",
+		},
+		{
+			name:     "Set extras keeps separator style",
+			content:  "django[rest, auth]>=3.2\n",
+			edit:     func(doc *Document) error { return doc.SetExtras("django", []string{"rest", "auth", "admin"}) },
+			expected: "django[rest, auth, admin]>=3.2\n",
+		},
+		{
+			name:     "Add extras to local path",
+			content:  "./libs/core  # local\n",
+			edit:     func(doc *Document) error { return doc.SetExtras("./libs/core", []string{"fast"}) },
+			expected: "./libs/core[fast]  # local\n",
+		},
+		{
+			name:     "Add extras to VCS egg",
+			content:  "git+https://github.com/user/project.git#egg=project\n",
+			edit:     func(doc *Document) error { return doc.SetExtras("project", []string{"dev"}) },
+			expected: "git+https://github.com/user/project.git#egg=project[dev]\n",
+		},
+		{
+			name:     "Remove extras",
+			content:  "requests[socks]>=2.0\n",
+			edit:     func(doc *Document) error { return doc.SetExtras("requests", nil) },
+			expected: "requests>=2.0\n",
+		},
+		{
+			name:     "Insert markers before options and comment",
+			content:  "numpy==1.21.0 --global-option=x  # math\n",
+			edit:     func(doc *Document) error { return doc.SetMarkers("numpy", "python_version >= '3.8'") },
+			expected: "numpy==1.21.0 ; python_version >= '3.8' --global-option=x  # math\n",
+		},
+		{
+			name:     "Replace markers keeps spacing",
+			content:  "pywin32 ;platform_system=='Windows'\n",
+			edit:     func(doc *Document) error { return doc.SetMarkers("pywin32", "os_name == 'nt'") },
+			expected: "pywin32 ;os_name == 'nt'\n",
+		},
+		{
+			name:     "Remove markers",
+			content:  "pywin32==305 ; platform_system == 'Windows'  # win\n",
+			edit:     func(doc *Document) error { return doc.SetMarkers("pywin32", "") },
+			expected: "pywin32==305  # win\n",
+		},
+		{
+			name:     "Replace multiline hashes",
+			content:  "django==3.2.0 \\\n    --hash=sha256:aaaa \\\n    --hash=sha256:bbbb\nflask==2.0.1\n",
+			edit:     func(doc *Document) error { return doc.SetHashes("django", []string{"sha256:cccc"}) },
+			expected: "django==3.2.0 \\\n    --hash=sha256:cccc\nflask==2.0.1\n",
+		},
+		{
+			name:     "New hashes follow the file's multiline layout",
+			content:  "django==3.2.0 \\\n  --hash=sha256:aaaa\nflask==2.0.1  # web\n",
+			edit:     func(doc *Document) error { return doc.SetHashes("flask", []string{"sha256:bbbb", "sha256:cccc"}) },
+			expected: "django==3.2.0 \\\n  --hash=sha256:aaaa\nflask==2.0.1 \\\n  --hash=sha256:bbbb \\\n  --hash=sha256:cccc  # web\n",
+		},
+		{
+			name:     "Single-line hashes",
+			content:  "flask==2.0.1\n",
+			edit:     func(doc *Document) error { return doc.SetHashes("flask", []string{"sha256:aaaa"}) },
+			expected: "flask==2.0.1 --hash=sha256:aaaa\n",
+		},
+		{
+			name:     "Hashes by index",
+			content:  "numpy==1.0 ; python_version<'3.8'\nnumpy==1.1 ; python_version>='3.8'\n",
+			edit:     func(doc *Document) error { return doc.SetHashesAt(1, []string{"sha256:aaaa"}) },
+			expected: "numpy==1.0 ; python_version<'3.8'\nnumpy==1.1 ; python_version>='3.8' --hash=sha256:aaaa\n",
+		},
+		{
+			name:     "Remove hashes",
+			content:  "django==3.2.0 \\\n    --hash=sha256:aaaa \\\n    --hash=sha256:bbbb\n",
+			edit:     func(doc *Document) error { return doc.SetHashes("django", nil) },
+			expected: "django==3.2.0\n",
+		},
+		{
+			name:     "Replace comment keeps spacing",
+			content:  "flask==2.0.1 #web\n",
+			edit:     func(doc *Document) error { return doc.SetComment("flask", "web framework") },
+			expected: "flask==2.0.1 #web framework\n",
+		},
+		{
+			name:     "New comment follows the file's spacing",
+			content:  "django==3.2.0 # web\nflask==2.0.1\n",
+			edit:     func(doc *Document) error { return doc.SetComment("flask", "micro") },
+			expected: "django==3.2.0 # web\nflask==2.0.1 # micro\n",
+		},
+		{
+			name:     "New comment after multiline hashes",
+			content:  "django==3.2.0 \\\n    --hash=sha256:aaaa\n",
+			edit:     func(doc *Document) error { return doc.SetComment("django", "web") },
+			expected: "django==3.2.0 \\\n    --hash=sha256:aaaa  # web\n",
+		},
+		{
+			name:     "New comment reuses trailing whitespace",
+			content:  "flask==2.0.1   \r\n",
+			edit:     func(doc *Document) error { return doc.SetComment("flask", "web") },
+			expected: "flask==2.0.1   # web\r\n",
+		},
+		{
+			name:     "Remove comment",
+			content:  "flask==2.0.1 ; os_name == 'nt'  # web\n",
+			edit:     func(doc *Document) error { return doc.SetComment("flask", "") },
+			expected: "flask==2.0.1 ; os_name == 'nt'\n",
+		},
+		{
+			name:    "Set and replace options",
+			content: "numpy==1.21.0 --hash=sha256:aaaa\n",
+			edit: func(doc *Document) error {
+				if err := doc.SetOption("numpy", "global-option", "x"); err != nil {
+					return err
+				}
+				return doc.SetOption("numpy", "--global-option", "y")
+			},
+			expected: "numpy==1.21.0 --global-option=y --hash=sha256:aaaa\n",
+		},
+		{
+			name:     "Remove option",
+			content:  "numpy==1.21.0 --install-option=\"--prefix=/usr\" # math\n",
+			edit:     func(doc *Document) error { return doc.RemoveOption("numpy", "install-option") },
+			expected: "numpy==1.21.0 # math\n",
+		},
+		{
+			name:    "Add keeps missing final newline",
+			content: "flask==2.0.1\r\nrequests",
+			edit: func(doc *Document) error {
+				_, err := doc.Add("django>=3.2  # web")
+				return err
+			},
+			expected: "flask==2.0.1\r\nrequests\r\ndjango>=3.2  # web",
+		},
+		{
+			name:    "Insert at beginning",
+			content: "flask==2.0.1\n",
+			edit: func(doc *Document) error {
+				_, err := doc.Insert(0, "# web")
+				return err
+			},
+			expected: "# web\nflask==2.0.1\n",
+		},
+		{
+			name:     "Remove last line without final newline",
+			content:  "flask==2.0.1\nrequests",
+			edit:     func(doc *Document) error { return doc.Remove("requests") },
+			expected: "flask==2.0.1",
+		},
+		{
+			name:     "Remove continuation line",
+			content:  "django==3.2.0 \\\n    --hash=sha256:aaaa\nflask==2.0.1\n",
+			edit:     func(doc *Document) error { return doc.Remove("django") },
+			expected: "flask==2.0.1\n",
+		},
+		{
+			name:     "Move to end",
+			content:  "requests\nflask\ndjango",
+			edit:     func(doc *Document) error { return doc.Move("requests", 2) },
+			expected: "flask\ndjango\nrequests",
+		},
+		{
+			name:     "Move to beginning",
+			content:  "requests\nflask\ndjango",
+			edit:     func(doc *Document) error { return doc.Move("django", 0) },
+			expected: "django\nrequests\nflask",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseDocument(tt.content)
+			if err != nil {
+				t.Fatalf("ParseDocument失败: %v", err)
+			}
+			if err := tt.edit(doc); err != nil {
+				t.Fatalf("编辑失败: %v", err)
+			}
+			if got := doc.String(); got != tt.expected {
+				t.Errorf("结果 = %q, 期望 %q", got, tt.expected)
+			}
+
+			// 编辑后的模型应与重新解析的结果一致
+			reparsed, _ := ParseDocument(doc.String())
+			got, want := doc.Requirements(), reparsed.Requirements()
+			if len(got) != len(want) {
+				t.Fatalf("requirement数量 = %d, 重新解析得到 %d", len(got), len(want))
+			}
+			for i := range got {
+				if !got[i].Equal(want[i]) {
+					t.Errorf("requirements[%d] = %+v, 重新解析得到 %+v", i, got[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDocument_PointersStayValid(t *testing.T) {
+	doc, _ := ParseDocument("flask==1.0.0\nrequests\n")
+	flask := doc.Find("flask")
+
+	if err := doc.SetVersion("flask", "==2.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := doc.Insert(0, "django"); err != nil {
+		t.Fatal(err)
+	}
+
+	if flask.Version != "==2.0.1" || flask.OriginalLine != "flask==2.0.1" {
+		t.Errorf("编辑后requirement未原地更新: %+v", flask)
+	}
+	if doc.Find("flask") != flask || doc.LineNumber(flask) != 2 {
+		t.Errorf("Find或LineNumber结果不正确: %d", doc.LineNumber(flask))
+	}
+}
+
+func TestDocument_Errors(t *testing.T) {
+	doc, _ := ParseDocument("flask==1.0.0\nhttps://example.com/pkg.whl\n# comment\n")
+
+	tests := []struct {
+		name    string
+		edit    func() error
+		wantErr string
+	}{
+		{"Missing package", func() error { return doc.SetVersion("django", "==1.0") }, "未找到包"},
+		{"Invalid version", func() error { return doc.SetVersion("flask", "2.0") }, "无效的版本约束"},
+		{"Version on URL", func() error { return doc.SetVersion("https://example.com/pkg.whl", "==1.0") }, "不支持版本约束"},
+		{"Hash option", func() error { return doc.SetOption("flask", "hash", "sha256:aaaa") }, "无效的选项"},
+		{"Invalid hash", func() error { return doc.SetHashes("flask", []string{"sha256:XYZ"}) }, "无效的哈希"},
+		{"Multiline comment", func() error { return doc.SetComment("flask", "a\nb") }, "换行"},
+		{"Multiple lines", func() error { _, err := doc.Add("a\nb"); return err }, "单个逻辑行"},
+		{"Insert out of range", func() error { _, err := doc.Insert(10, "a"); return err }, "超出范围"},
+		{"Move out of range", func() error { return doc.Move("flask", 3) }, "超出范围"},
+		{"SetHashesAt out of range", func() error { return doc.SetHashesAt(5, nil) }, "超出范围"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.edit()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("错误 = %v, 期望包含 %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if got := doc.String(); got != "flask==1.0.0\nhttps://example.com/pkg.whl\n# comment\n" {
+		t.Errorf("失败的编辑不应修改文档: %q", got)
+	}
+}
+
+func TestDocument_InvalidValues(t *testing.T) {
+	content := "flask[async]==1.0.0 --global-option=x  # web\nrequests\n"
+
+	tests := []struct {
+		name    string
+		edit    func(doc *Document) error
+		wantErr string
+	}{
+		{"Marker with newline", func(doc *Document) error { return doc.SetMarkers("flask", "os_name=='nt'\nevil==1") }, "换行"},
+		{"Marker with comment", func(doc *Document) error { return doc.SetMarkers("flask", "os_name=='nt' # x") }, "无效的环境标记"},
+		{"Version with newline", func(doc *Document) error { return doc.SetVersion("flask", "==2.0\nsix") }, "换行"},
+		{"Version with comment", func(doc *Document) error { return doc.SetVersion("flask", "==2.0 # x") }, "无效的版本约束"},
+		{"Option with comment", func(doc *Document) error { return doc.SetOption("flask", "global-option", "a # b") }, "无效的选项值"},
+		{"Option with newline", func(doc *Document) error { return doc.SetOption("requests", "global-option", "a\nb") }, "换行"},
+		{"Extras with bracket", func(doc *Document) error { return doc.SetExtras("flask", []string{"a]b"}) }, "无效的extras"},
+		{"New extras with bracket", func(doc *Document) error { return doc.SetExtras("requests", []string{"a]b"}) }, "无效的extras"},
+		{"Editor version with newline", func(doc *Document) error {
+			pae := NewPositionAwareEditor()
+			return pae.UpdatePackageVersion(&PositionAwareDocument{doc: doc}, "flask", "==2.0\nsix")
+		}, "无效的版本约束"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, _ := ParseDocument(content)
+			err := tt.edit(doc)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("错误 = %v, 期望包含 %q", err, tt.wantErr)
+			}
+			if got := doc.String(); got != content {
+				t.Errorf("失败的编辑不应修改文档: %q", got)
+			}
+			flask := doc.Find("flask")
+			if flask.Version != "==1.0.0" || flask.Markers != "" || len(flask.Extras) != 1 || flask.RequirementOptions["global-option"] != "x" {
+				t.Errorf("失败的编辑不应修改requirement: %+v", flask)
+			}
+			if len(doc.Journal()) != 0 || doc.CanUndo() {
+				t.Errorf("失败的编辑不应产生记录: %+v", doc.Journal())
+			}
+		})
+	}
+}
+
+func TestDocument_Bytes(t *testing.T) {
+	content := "# -*- coding: latin-1 -*-\nflask==1.0.0 # caf\xe9\n"
+	doc, err := ParseDocumentBytes([]byte(content))
+	if err != nil {
+		t.Fatalf("ParseDocumentBytes失败: %v", err)
+	}
+	if err := doc.SetVersion("flask", "==2.0.1"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes失败: %v", err)
+	}
+	if string(data) != "# -*- coding: latin-1 -*-\nflask==2.0.1 # caf\xe9\n" {
+		t.Errorf("Bytes() = %q", data)
+	}
+}
+
+func TestParseDocumentFile_ResolvesPathsAgainstFile(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "requirements")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "dev.txt")
+	if err := os.WriteFile(path, []byte("-e ../lib\nflask==1.0.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := ParseDocumentFile(path)
+	if err != nil {
+		t.Fatalf("ParseDocumentFile()失败: %v", err)
+	}
+	lib := filepath.Join(root, "lib")
+	if got := doc.Requirements()[0].ResolvedPath; got != lib {
+		t.Errorf("解析后ResolvedPath = %q, want %q", got, lib)
+	}
+
+	// 编辑后重新解析的行仍然相对于文件所在目录
+	if err := doc.SetComment("../lib", "local checkout"); err != nil {
+		t.Fatalf("SetComment()失败: %v", err)
+	}
+	if got := doc.Requirements()[0].ResolvedPath; got != lib {
+		t.Errorf("编辑后ResolvedPath = %q, want %q", got, lib)
+	}
+
+	req, err := doc.Add("./vendor/pkg.whl")
+	if err != nil {
+		t.Fatalf("Add()失败: %v", err)
+	}
+	if want := filepath.Join(dir, "vendor", "pkg.whl"); req.ResolvedPath != want {
+		t.Errorf("新增行的ResolvedPath = %q, want %q", req.ResolvedPath, want)
+	}
+}
@@ -0,0 +1,29 @@
+package editor
+
+import (
+	"strings"
+
+	"github.com/scagogogo/python-requirements-parser/pkg/parser"
+)
+
+// utf8BOM 字符串形式的UTF-8 BOM
+const utf8BOM = "\ufeff"
+
+// decodeText 去掉字符串开头的BOM，返回文本和对应的编码信息
+//
+// 字符串内容已经是解码后的文本，因此只识别UTF-8 BOM，不处理编码声明。
+func decodeText(content string) (string, parser.Encoding) {
+	text := strings.TrimPrefix(content, utf8BOM)
+	return text, parser.Encoding{Name: parser.EncodingUTF8, BOM: text != content}
+}
+
+// textWithBOM 按文档的编码信息还原字符串形式的输出
+//
+// 对于带BOM的UTF-8文档，字符串输出会保留开头的BOM，
+// 使SerializeToString的结果与SerializeToBytes完全一致；其他编码只返回文本本身。
+func textWithBOM(text string, enc parser.Encoding) string {
+	if enc.BOM && (enc.Name == "" || enc.Name == parser.EncodingUTF8) {
+		return utf8BOM + text
+	}
+	return text
+}
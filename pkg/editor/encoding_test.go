@@ -0,0 +1,101 @@
+package editor
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/scagogogo/python-requirements-parser/pkg/parser"
+)
+
+func TestPositionAwareEditor_PreservesEncoding(t *testing.T) {
+	editor := NewPositionAwareEditor()
+
+	original, err := parser.Encode("flask==1.0.0 # 框架\r\nrequests>=2.25.0\r\n", parser.Encoding{Name: parser.EncodingUTF16LE, BOM: true})
+	if err != nil {
+		t.Fatalf("编码失败: %v", err)
+	}
+
+	doc, err := editor.ParseRequirementsBytes(original)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+
+	if enc := doc.Encoding(); enc.Name != parser.EncodingUTF16LE || !enc.BOM {
+		t.Errorf("Encoding() = %+v, want utf-16-le with BOM", enc)
+	}
+
+	if err := editor.UpdatePackageVersion(doc, "flask", "==2.0.1"); err != nil {
+		t.Fatalf("更新版本失败: %v", err)
+	}
+
+	data, err := editor.SerializeToBytes(doc)
+	if err != nil {
+		t.Fatalf("序列化失败: %v", err)
+	}
+
+	want, _ := parser.Encode("flask==2.0.1 # 框架\r\nrequests>=2.25.0\r\n", parser.Encoding{Name: parser.EncodingUTF16LE, BOM: true})
+	if !bytes.Equal(data, want) {
+		t.Errorf("SerializeToBytes() = %q, want %q", data, want)
+	}
+}
+
+func TestPositionAwareEditor_PreservesUTF8BOM(t *testing.T) {
+	editor := NewPositionAwareEditor()
+
+	doc, err := editor.ParseRequirementsFile("\ufeffflask==1.0.0\nrequests>=2.25.0")
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+
+	flask, err := editor.GetPackageInfo(doc, "flask")
+	if err != nil {
+		t.Fatalf("BOM不应影响第一行的包名: %v", err)
+	}
+	if flask.PositionInfo == nil || flask.PositionInfo.VersionStartColumn != 5 {
+		t.Errorf("版本位置不正确: %+v", flask.PositionInfo)
+	}
+
+	if err := editor.UpdatePackageVersion(doc, "flask", "==2.0.1"); err != nil {
+		t.Fatalf("更新版本失败: %v", err)
+	}
+
+	if got, want := editor.SerializeToString(doc), "\ufeffflask==2.0.1\nrequests>=2.25.0"; got != want {
+		t.Errorf("SerializeToString() = %q, want %q", got, want)
+	}
+}
+
+func TestVersionEditorV2_PreservesEncoding(t *testing.T) {
+	editor := NewVersionEditorV2()
+
+	original := []byte("# -*- coding: latin-1 -*-\nflask==1.0.0 # caf\xe9")
+	doc, err := editor.ParseRequirementsBytes(original)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+
+	if err := editor.UpdatePackageVersion(doc, "flask", "==2.0.1"); err != nil {
+		t.Fatalf("更新版本失败: %v", err)
+	}
+
+	data, err := editor.SerializeToBytes(doc)
+	if err != nil {
+		t.Fatalf("序列化失败: %v", err)
+	}
+
+	want := []byte("# -*- coding: latin-1 -*-\nflask==2.0.1 # caf\xe9")
+	if !bytes.Equal(data, want) {
+		t.Errorf("SerializeToBytes() = %q, want %q", data, want)
+	}
+
+	doc, err = editor.ParseRequirementsBytes([]byte("\xef\xbb\xbfflask==1.0.0"))
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	data, err = editor.SerializeToBytes(doc)
+	if err != nil {
+		t.Fatalf("序列化失败: %v", err)
+	}
+	if !bytes.Equal(data, []byte("\xef\xbb\xbfflask==1.0.0")) {
+		t.Errorf("UTF-8 BOM未被保留: %q", data)
+	}
+}
@@ -0,0 +1,125 @@
+package editor
+
+import (
+	"fmt"
+
+	"github.com/scagogogo/python-requirements-parser/pkg/hashes"
+	"github.com/scagogogo/python-requirements-parser/pkg/models"
+)
+
+// HashPolicy 修改带哈希的requirement的版本时的处理方式
+//
+// 哈希对应特定版本的发行文件，只修改版本而保留原有哈希会使文件在
+// --require-hashes模式下无法安装。
+type HashPolicy int
+
+const (
+	// HashPolicyFail 返回错误，不修改文档（默认）
+	HashPolicyFail HashPolicy = iota
+
+	// HashPolicyDrop 修改版本并移除原有的哈希
+	HashPolicyDrop
+
+	// HashPolicyProvider 修改版本并用HashProvider提供的哈希替换原有的哈希
+	HashPolicyProvider
+)
+
+// String 返回策略的名称
+func (p HashPolicy) String() string {
+	switch p {
+	case HashPolicyFail:
+		return "fail"
+	case HashPolicyDrop:
+		return "drop"
+	case HashPolicyProvider:
+		return "provider"
+	}
+	return fmt.Sprintf("HashPolicy(%d)", int(p))
+}
+
+// HashProvider 为指定版本的包提供哈希
+type HashProvider interface {
+	// Hashes 返回包name的version版本所有发行文件的哈希，格式为"算法:摘要"，
+	// 如"sha256:ab12..."。找不到时返回错误。
+	Hashes(name, version string) ([]string, error)
+}
+
+// setVersionHashed 按哈希策略修改第i行的版本约束
+//
+// requirement没有哈希或版本约束没有变化时直接修改版本。
+// 需要获取新哈希时，所有检查都在修改文档之前完成，失败时文档保持不变。
+func (d *Document) setVersionHashed(i int, version string, policy HashPolicy, provider HashProvider) error {
+	defer d.record(OpUpdateVersion, d.reqs[i].Name, version)()
+	req := d.reqs[i]
+	if len(req.Hashes) == 0 || models.NormalizeSpecifier(req.Version) == models.NormalizeSpecifier(version) {
+		return d.setVersion(i, version)
+	}
+
+	var newHashes []string
+	switch policy {
+	case HashPolicyFail:
+		return fmt.Errorf("%s 带有哈希，修改版本会使原有哈希失效（可以使用HashPolicyDrop或HashPolicyProvider）", req.Name)
+	case HashPolicyDrop:
+	case HashPolicyProvider:
+		if provider == nil {
+			return fmt.Errorf("HashPolicyProvider需要设置HashProvider")
+		}
+		pin, ok := models.PinnedVersion(version)
+		if !ok {
+			return fmt.Errorf("%s 带有哈希，只能固定到精确版本（==）: %s", req.Name, version)
+		}
+		var err error
+		if newHashes, err = provider.Hashes(req.Name, pin); err != nil {
+			return fmt.Errorf("获取 %s==%s 的哈希失败: %w", req.Name, pin, err)
+		}
+		if len(newHashes) == 0 {
+			return fmt.Errorf("没有找到 %s==%s 的哈希", req.Name, pin)
+		}
+		for _, hash := range newHashes {
+			if err := validateHash(hash); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("无效的哈希策略: %s", policy)
+	}
+
+	if err := d.setVersion(i, version); err != nil {
+		return err
+	}
+	return d.setHashes(i, newHashes)
+}
+
+// WheelhouseHashProvider 根据本地目录中的发行文件计算哈希
+//
+// 目录通常由"pip download -d wheelhouse"或"pip wheel -w wheelhouse"生成。
+// 文件名符合wheel（name-version-...whl）或源码包（name-version.tar.gz、.zip等）
+// 命名规则、且包名（按PEP 503规范化）和版本匹配的文件都会参与计算，
+// 返回按字典序排列的sha256哈希。
+//
+// 示例:
+//
+//	e := editor.NewPositionAwareEditor()
+//	e.HashPolicy = editor.HashPolicyProvider
+//	e.HashProvider = editor.NewWheelhouseHashProvider("wheelhouse")
+type WheelhouseHashProvider struct {
+	// Dir 存放发行文件的目录
+	Dir string
+}
+
+// NewWheelhouseHashProvider 创建从dir目录读取发行文件的HashProvider
+func NewWheelhouseHashProvider(dir string) *WheelhouseHashProvider {
+	return &WheelhouseHashProvider{Dir: dir}
+}
+
+// Hashes 返回目录中name的version版本所有发行文件的sha256哈希
+//
+// 每次调用都会重新扫描目录。需要缓存哈希或使用其他算法时，
+// 可以直接把hashes.Store用作HashProvider。
+func (w *WheelhouseHashProvider) Hashes(name, version string) ([]string, error) {
+	store, err := hashes.NewStore(w.Dir)
+	if err != nil {
+		return nil, err
+	}
+	return store.Hashes(name, version)
+}
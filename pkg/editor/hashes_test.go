@@ -0,0 +1,189 @@
+package editor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// mapHashProvider 使用固定映射的HashProvider，键为"name==version"
+type mapHashProvider map[string][]string
+
+func (m mapHashProvider) Hashes(name, version string) ([]string, error) {
+	if hashes, ok := m[name+"=="+version]; ok {
+		return hashes, nil
+	}
+	return nil, fmt.Errorf("未知的版本: %s==%s", name, version)
+}
+
+func TestHashPolicy(t *testing.T) {
+	content := "requests==2.30.0 \\\n" +
+		"    --hash=sha256:aaaa \\\n" +
+		"    --hash=sha256:bbbb\n" +
+		"    # via -r requirements.in\n" +
+		"flask==2.3.2\n"
+	provider := mapHashProvider{
+		"requests==2.31.0": {"sha256:cccc", "sha256:dddd"},
+		"requests==2.32.0": {"md5:XYZ"},
+	}
+
+	tests := []struct {
+		name     string
+		policy   HashPolicy
+		provider HashProvider
+		version  string
+		expected string
+		wantErr  string
+	}{
+		{
+			name:    "Fail by default",
+			policy:  HashPolicyFail,
+			version: "==2.31.0",
+			wantErr: "带有哈希",
+		},
+		{
+			name:     "Unchanged version is allowed",
+			policy:   HashPolicyFail,
+			version:  "== 2.30.0",
+			expected: strings.Replace(content, "==2.30.0", "== 2.30.0", 1),
+		},
+		{
+			name:     "Drop stale hashes",
+			policy:   HashPolicyDrop,
+			version:  "==2.31.0",
+			expected: "requests==2.31.0\n    # via -r requirements.in\nflask==2.3.2\n",
+		},
+		{
+			name:     "Replace hashes from provider",
+			policy:   HashPolicyProvider,
+			provider: provider,
+			version:  "==2.31.0",
+			expected: strings.NewReplacer("2.30.0", "2.31.0", "aaaa", "cccc", "bbbb", "dddd").Replace(content),
+		},
+		{
+			name:     "Provider requires exact version",
+			policy:   HashPolicyProvider,
+			provider: provider,
+			version:  ">=2.31.0",
+			wantErr:  "精确版本",
+		},
+		{
+			name:     "Provider error",
+			policy:   HashPolicyProvider,
+			provider: provider,
+			version:  "==2.29.0",
+			wantErr:  "未知的版本",
+		},
+		{
+			name:     "Invalid hash from provider",
+			policy:   HashPolicyProvider,
+			provider: provider,
+			version:  "==2.32.0",
+			wantErr:  "无效的哈希",
+		},
+		{
+			name:    "Missing provider",
+			policy:  HashPolicyProvider,
+			version: "==2.31.0",
+			wantErr: "HashProvider",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			editor := NewPositionAwareEditor()
+			editor.HashPolicy = tt.policy
+			editor.HashProvider = tt.provider
+
+			doc, err := editor.ParseRequirementsFile(content)
+			if err != nil {
+				t.Fatalf("解析失败: %v", err)
+			}
+			err = editor.UpdatePackageVersion(doc, "requests", tt.version)
+
+			result := editor.SerializeToString(doc)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("错误 = %v, 期望包含 %q", err, tt.wantErr)
+				}
+				if result != content {
+					t.Errorf("失败的更新不应修改文档:\n%s", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("更新失败: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("结果:\n%s\n期望:\n%s", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHashPolicy_VersionEditorV2(t *testing.T) {
+	content := "django==3.1.0 --hash=sha256:aaaa\nflask==1.0.0\n"
+
+	editor := NewVersionEditorV2()
+	doc, _ := editor.ParseRequirementsFile(content)
+	if err := editor.UpdatePackageVersion(doc, "django", "==3.2.0"); err == nil {
+		t.Errorf("默认策略下更新带哈希的包应返回错误")
+	}
+
+	editor.HashPolicy = HashPolicyProvider
+	editor.HashProvider = mapHashProvider{"django==3.2.0": {"sha256:bbbb"}}
+	if err := editor.BatchUpdateVersions(doc, map[string]string{"django": "==3.2.0", "flask": "==2.0.1"}); err != nil {
+		t.Fatalf("批量更新失败: %v", err)
+	}
+	if result := editor.SerializeToString(doc); result != "django==3.2.0 --hash=sha256:bbbb\nflask==2.0.1\n" {
+		t.Errorf("结果 = %q", result)
+	}
+}
+
+func TestWheelhouseHashProvider(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"Django-4.2.1-py3-none-any.whl":              "wheel",
+		"django-4.2.1.tar.gz":                        "sdist",
+		"Django-4.2.0-py3-none-any.whl":              "old",
+		"python_dateutil-2.8.2-py2.py3-none-any.whl": "dateutil",
+		"README.txt": "ignored",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sum := func(data string) string {
+		h := sha256.Sum256([]byte(data))
+		return "sha256:" + hex.EncodeToString(h[:])
+	}
+
+	provider := NewWheelhouseHashProvider(dir)
+
+	hashes, err := provider.Hashes("django", "4.2.1")
+	if err != nil {
+		t.Fatalf("Hashes失败: %v", err)
+	}
+	want := []string{sum("wheel"), sum("sdist")}
+	if want[0] > want[1] {
+		want[0], want[1] = want[1], want[0]
+	}
+	if strings.Join(hashes, ",") != strings.Join(want, ",") {
+		t.Errorf("hashes = %v, 期望 %v", hashes, want)
+	}
+
+	if hashes, err := provider.Hashes("Python-DateUtil", "2.8.2"); err != nil || len(hashes) != 1 || hashes[0] != sum("dateutil") {
+		t.Errorf("规范化包名匹配失败: %v, %v", hashes, err)
+	}
+	if _, err := provider.Hashes("django", "5.0"); err == nil {
+		t.Errorf("找不到发行文件时应返回错误")
+	}
+	if _, err := NewWheelhouseHashProvider(filepath.Join(dir, "missing")).Hashes("django", "4.2.1"); err == nil {
+		t.Errorf("目录不存在时应返回错误")
+	}
+}
@@ -0,0 +1,332 @@
+package editor
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/scagogogo/python-requirements-parser/pkg/cst"
+	"github.com/scagogogo/python-requirements-parser/pkg/models"
+)
+
+// 事务之外的编辑操作类型，用于编辑记录
+const (
+	// OpSetExtras 设置extras
+	OpSetExtras OperationKind = "set-extras"
+
+	// OpSetHashes 设置哈希
+	OpSetHashes OperationKind = "set-hashes"
+
+	// OpSetComment 设置行内注释
+	OpSetComment OperationKind = "set-comment"
+
+	// OpSetOption 设置requirement选项
+	OpSetOption OperationKind = "set-option"
+
+	// OpRemoveOption 移除requirement选项
+	OpRemoveOption OperationKind = "remove-option"
+
+	// OpMove 移动一行
+	OpMove OperationKind = "move"
+
+	// OpTransaction 一个已提交的事务，Value为其中各操作的描述
+	OpTransaction OperationKind = "transaction"
+)
+
+// Change 编辑记录中的一项：一次已应用的编辑及其文本变化
+//
+// 文本变化用一个区间表示：编辑前文本中从Offset开始的Before被替换为After。
+// 偏移量以字节计，不包括BOM。
+type Change struct {
+	// Seq 序号，从1开始，按编辑的先后递增
+	Seq int `json:"seq"`
+
+	// Operation 操作类型，如"update-version"
+	Operation OperationKind `json:"operation"`
+
+	// Package 操作的包名、本地路径或URL
+	Package string `json:"package,omitempty"`
+
+	// Value 操作的参数，如新的版本约束
+	Value string `json:"value,omitempty"`
+
+	// Offset 变化在编辑前文本中的起始字节偏移
+	Offset int `json:"offset"`
+
+	// Line 变化起始位置所在的行号（从1开始）
+	Line int `json:"line"`
+
+	// Before 编辑前的文本
+	Before string `json:"before"`
+
+	// After 编辑后的文本
+	After string `json:"after"`
+
+	// Author 编辑者，见Document.SetAuthor
+	Author string `json:"author,omitempty"`
+
+	// Reason 编辑原因，见Document.Annotate
+	Reason string `json:"reason,omitempty"`
+
+	// Time 编辑时间
+	Time time.Time `json:"time"`
+}
+
+// history 文档的编辑记录和撤销、重做栈
+type history struct {
+	// done 已应用的修改，按先后顺序排列
+	done []Change
+
+	// undone 已撤销、可以重做的修改，最后撤销的在末尾
+	undone []Change
+
+	// seq 最后分配的序号
+	seq int
+
+	// depth 正在执行的编辑操作的嵌套层数，只记录最外层的操作
+	depth int
+
+	// author 新修改的编辑者
+	author string
+
+	// now 返回当前时间，测试时可以替换
+	now func() time.Time
+}
+
+// record 开始记录一次编辑操作，返回的函数在操作结束时调用
+//
+// 嵌套的操作（如Add调用Insert、事务中的各个操作）合并为最外层的一项记录。
+// 操作没有改变文本时（包括失败后回滚）不产生记录。
+//
+// 用法:
+//
+//	defer d.record(OpUpdateVersion, name, version)()
+func (d *Document) record(kind OperationKind, name, value string) func() {
+	h := &d.history
+	h.depth++
+	if h.depth > 1 {
+		return func() { h.depth-- }
+	}
+
+	before := d.file.String()
+	return func() {
+		h.depth--
+		after := d.file.String()
+		if after == before {
+			return
+		}
+
+		offset, removed, inserted := textChange(before, after)
+		now := time.Now
+		if h.now != nil {
+			now = h.now
+		}
+		h.seq++
+		h.done = append(h.done, Change{
+			Seq:       h.seq,
+			Operation: kind,
+			Package:   name,
+			Value:     value,
+			Offset:    offset,
+			Line:      strings.Count(before[:offset], "\n") + 1,
+			Before:    removed,
+			After:     inserted,
+			Author:    h.author,
+			Time:      now(),
+		})
+		h.undone = nil
+	}
+}
+
+// textChange 返回从before到after的最小变化区间：起始偏移、被替换的文本和替换后的文本
+//
+// 区间的边界落在UTF-8字符的边界上。
+func textChange(before, after string) (int, string, string) {
+	prefix := 0
+	for prefix < len(before) && prefix < len(after) && before[prefix] == after[prefix] {
+		prefix++
+	}
+	for prefix > 0 && (!runeStart(before, prefix) || !runeStart(after, prefix)) {
+		prefix--
+	}
+
+	suffix := 0
+	for suffix < len(before)-prefix && suffix < len(after)-prefix &&
+		before[len(before)-1-suffix] == after[len(after)-1-suffix] {
+		suffix++
+	}
+	for suffix > 0 && (!runeStart(before, len(before)-suffix) || !runeStart(after, len(after)-suffix)) {
+		suffix--
+	}
+
+	return prefix, before[prefix : len(before)-suffix], after[prefix : len(after)-suffix]
+}
+
+// runeStart 检查s中的位置i是否为字符的起始位置（或文本末尾）
+func runeStart(s string, i int) bool {
+	return i >= len(s) || utf8.RuneStart(s[i])
+}
+
+// SetAuthor 设置之后的修改在编辑记录中的编辑者
+func (d *Document) SetAuthor(author string) {
+	d.history.author = author
+}
+
+// Annotate 为最近一次修改添加原因，如"修复CVE-2023-30861"
+//
+// 没有修改时返回错误。
+func (d *Document) Annotate(reason string) error {
+	done := d.history.done
+	if len(done) == 0 {
+		return fmt.Errorf("没有可以添加说明的修改")
+	}
+	done[len(done)-1].Reason = reason
+	return nil
+}
+
+// Journal 返回当前生效的修改，按先后顺序排列
+//
+// 撤销的修改不在其中，重做后重新出现。
+func (d *Document) Journal() []Change {
+	return append([]Change(nil), d.history.done...)
+}
+
+// MarshalJournal 将编辑记录编码为JSON数组，用于审计日志
+//
+// 示例输出:
+//
+//	[{"seq":1,"operation":"update-version","package":"flask","value":"==2.3.2",
+//	  "offset":7,"line":1,"before":"1.0.0","after":"2.3.2",
+//	  "author":"alice","reason":"CVE-2023-30861","time":"2024-05-01T10:00:00Z"}]
+func (d *Document) MarshalJournal() ([]byte, error) {
+	journal := d.history.done
+	if journal == nil {
+		journal = []Change{}
+	}
+	return json.Marshal(journal)
+}
+
+// CanUndo 检查是否有可以撤销的修改
+func (d *Document) CanUndo() bool {
+	return len(d.history.done) > 0
+}
+
+// CanRedo 检查是否有可以重做的修改
+func (d *Document) CanRedo() bool {
+	return len(d.history.undone) > 0
+}
+
+// Undo 撤销最近一次修改
+//
+// 撤销后可以用Redo重做；撤销后进行新的修改会清空重做栈。
+// 未受影响的行的*models.Requirement指针保持不变。
+func (d *Document) Undo() error {
+	h := &d.history
+	if len(h.done) == 0 {
+		return fmt.Errorf("没有可以撤销的修改")
+	}
+	change := h.done[len(h.done)-1]
+	if err := d.splice(change.Offset, change.After, change.Before); err != nil {
+		return err
+	}
+	h.done = h.done[:len(h.done)-1]
+	h.undone = append(h.undone, change)
+	return nil
+}
+
+// Redo 重做最近一次撤销的修改
+func (d *Document) Redo() error {
+	h := &d.history
+	if len(h.undone) == 0 {
+		return fmt.Errorf("没有可以重做的修改")
+	}
+	change := h.undone[len(h.undone)-1]
+	if err := d.splice(change.Offset, change.Before, change.After); err != nil {
+		return err
+	}
+	h.undone = h.undone[:len(h.undone)-1]
+	h.done = append(h.done, change)
+	return nil
+}
+
+// splice 将文本中从offset开始的from替换为to，并同步语法树和requirement
+func (d *Document) splice(offset int, from, to string) error {
+	text := d.file.String()
+	if offset+len(from) > len(text) || text[offset:offset+len(from)] != from {
+		return fmt.Errorf("文档与编辑记录不一致，无法应用修改")
+	}
+	return d.reload(text[:offset] + to + text[offset+len(from):])
+}
+
+// reload 用新的文本替换文档内容
+//
+// 开头和结尾未变化的逻辑行沿用原来的语法树和requirement。中间变化的部分中，
+// 与原有某一行文本相同的行（如移动的行）沿用该行的requirement；其余的行重新解析，
+// 剩余的新旧行数相同时按顺序在原来的requirement上原地更新，保持指针不变。
+func (d *Document) reload(text string) error {
+	file := cst.Parse(text)
+	oldLines, newLines := d.file.Lines, file.Lines
+
+	prefix := 0
+	for prefix < len(oldLines) && prefix < len(newLines) && oldLines[prefix].String() == newLines[prefix].String() {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(oldLines)-prefix && suffix < len(newLines)-prefix &&
+		oldLines[len(oldLines)-1-suffix].String() == newLines[len(newLines)-1-suffix].String() {
+		suffix++
+	}
+	oldEnd, newEnd := len(oldLines)-suffix, len(newLines)-suffix
+
+	// 文本相同的行沿用原来的行和requirement
+	available := make(map[string][]int)
+	for i := prefix; i < oldEnd; i++ {
+		key := oldLines[i].String()
+		available[key] = append(available[key], i)
+	}
+	lines := make([]*cst.Line, len(newLines))
+	reqs := make([]*models.Requirement, len(newLines))
+	used := make(map[int]bool)
+	var pending []int
+	for i := prefix; i < newEnd; i++ {
+		key := newLines[i].String()
+		if candidates := available[key]; len(candidates) > 0 {
+			lines[i], reqs[i] = oldLines[candidates[0]], d.reqs[candidates[0]]
+			used[candidates[0]] = true
+			available[key] = candidates[1:]
+			continue
+		}
+		pending = append(pending, i)
+	}
+
+	// 其余的行重新解析
+	var unused []int
+	for i := prefix; i < oldEnd; i++ {
+		if !used[i] {
+			unused = append(unused, i)
+		}
+	}
+	for n, i := range pending {
+		req, err := d.parseLine(newLines[i])
+		if err != nil {
+			return err
+		}
+		if len(pending) == len(unused) {
+			old := d.reqs[unused[n]]
+			*old = *req
+			req = old
+		}
+		lines[i], reqs[i] = newLines[i], req
+	}
+
+	copy(lines, oldLines[:prefix])
+	copy(reqs, d.reqs[:prefix])
+	copy(lines[newEnd:], oldLines[oldEnd:])
+	copy(reqs[newEnd:], d.reqs[oldEnd:])
+
+	d.file.Lines = lines
+	d.reqs = reqs
+	return nil
+}
@@ -0,0 +1,215 @@
+package editor
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// fixedClock 让编辑记录使用固定的时间
+func fixedClock(doc *Document) time.Time {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	doc.history.now = func() time.Time { return now }
+	return now
+}
+
+func TestDocument_Journal(t *testing.T) {
+	doc, _ := ParseDocument("# deps\nflask==1.0.0\nsix==1.15.0\n")
+	now := fixedClock(doc)
+
+	doc.SetAuthor("alice")
+	if err := doc.SetVersion("flask", "==2.3.2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Annotate("CVE-2023-30861"); err != nil {
+		t.Fatal(err)
+	}
+	doc.SetAuthor("bob")
+	if _, err := doc.Add("requests==2.31.0"); err != nil {
+		t.Fatal(err)
+	}
+	// 失败和没有改变文本的操作不产生记录
+	doc.SetVersion("missing", "==1.0")
+	doc.SetMarkers("six", "")
+
+	journal := doc.Journal()
+	if len(journal) != 2 {
+		t.Fatalf("记录数量 = %d: %+v", len(journal), journal)
+	}
+
+	expected := []Change{
+		{Seq: 1, Operation: OpUpdateVersion, Package: "flask", Value: "==2.3.2", Offset: 14,
+			Line: 2, Before: "1.0.0", After: "2.3.2", Author: "alice", Reason: "CVE-2023-30861", Time: now},
+		{Seq: 2, Operation: OpAdd, Value: "requests==2.31.0", Offset: 32,
+			Line: 4, Before: "", After: "requests==2.31.0\n", Author: "bob", Time: now},
+	}
+	for i, want := range expected {
+		if journal[i] != want {
+			t.Errorf("journal[%d] = %+v\n期望 %+v", i, journal[i], want)
+		}
+	}
+
+	data, err := doc.MarshalJournal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded []map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil || len(decoded) != 2 {
+		t.Fatalf("MarshalJournal = %s, %v", data, err)
+	}
+	if decoded[0]["reason"] != "CVE-2023-30861" || decoded[0]["time"] != "2024-05-01T10:00:00Z" || decoded[1]["package"] != nil {
+		t.Errorf("JSON = %s", data)
+	}
+
+	empty, _ := ParseDocument("flask\n")
+	if data, _ := empty.MarshalJournal(); string(data) != "[]" {
+		t.Errorf("空记录 = %s", data)
+	}
+	if err := empty.Annotate("x"); err == nil {
+		t.Errorf("没有修改时Annotate应返回错误")
+	}
+}
+
+func TestDocument_UndoRedo(t *testing.T) {
+	content := "# deps\nflask==1.0.0  # web\nsix==1.15.0\nrequests==2.0.0"
+	doc, _ := ParseDocument(content)
+	flask := doc.Find("flask")
+
+	if doc.CanUndo() || doc.Undo() == nil || doc.Redo() == nil {
+		t.Fatalf("新文档不应有可以撤销或重做的修改")
+	}
+
+	var states []string
+	edits := []func() error{
+		func() error { return doc.SetVersion("flask", "==2.3.2") },
+		func() error { return doc.Remove("six") },
+		func() error { _, err := doc.Add("gunicorn==21.2.0"); return err },
+		func() error { return doc.SetComment("flask", "") },
+		func() error { return doc.Move("requests", 0) },
+	}
+	states = append(states, doc.String())
+	for i, edit := range edits {
+		if err := edit(); err != nil {
+			t.Fatalf("edit %d: %v", i, err)
+		}
+		states = append(states, doc.String())
+	}
+
+	for i := len(edits) - 1; i >= 0; i-- {
+		if err := doc.Undo(); err != nil {
+			t.Fatalf("Undo失败: %v", err)
+		}
+		if got := doc.String(); got != states[i] {
+			t.Errorf("撤销到第%d步:\n%q\n期望:\n%q", i, got, states[i])
+		}
+	}
+	if doc.CanUndo() || !doc.CanRedo() || len(doc.Journal()) != 0 {
+		t.Errorf("全部撤销后的状态不正确")
+	}
+	// 修改过又撤销的行沿用原来的requirement
+	if doc.Find("flask") != flask || flask.Version != "==1.0.0" || flask.Comment != "web" {
+		t.Errorf("撤销后的requirement = %+v", flask)
+	}
+
+	for i := 1; i <= len(edits); i++ {
+		if err := doc.Redo(); err != nil {
+			t.Fatalf("Redo失败: %v", err)
+		}
+		if got := doc.String(); got != states[i] {
+			t.Errorf("重做到第%d步:\n%q\n期望:\n%q", i, got, states[i])
+		}
+	}
+	if len(doc.Journal()) != len(edits) {
+		t.Errorf("重做后的记录数量 = %d", len(doc.Journal()))
+	}
+
+	// 新的修改清空重做栈
+	doc.Undo()
+	doc.SetVersion("gunicorn", "==22.0.0")
+	if doc.CanRedo() {
+		t.Errorf("新的修改后不应有可以重做的修改")
+	}
+	if doc.Find("gunicorn").Version != "==22.0.0" || doc.Find("requests") == nil {
+		t.Errorf("requirement没有同步: %s", doc.String())
+	}
+}
+
+func TestDocument_JournalNesting(t *testing.T) {
+	doc, _ := ParseDocument("flask==1.0.0\nsix==1.15.0\n")
+
+	// AddAt内部调用Insert，只记录一次
+	doc.AddAt("attrs==23.1.0", Alphabetical(""))
+	// 回滚的事务不产生记录
+	doc.Begin().Remove("six").Remove("missing").Commit()
+	// 提交的事务记录为一项
+	if _, err := doc.Begin().UpdateVersion("flask", "==2.0.1").Remove("six").Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	journal := doc.Journal()
+	if len(journal) != 2 || journal[0].Operation != OpAdd || journal[1].Operation != OpTransaction {
+		t.Fatalf("journal = %+v", journal)
+	}
+	if journal[1].Value != "update-version flask ==2.0.1; remove six" {
+		t.Errorf("事务的描述 = %q", journal[1].Value)
+	}
+
+	// 撤销事务时撤销其中的全部操作
+	doc.Undo()
+	if got := doc.String(); got != "attrs==23.1.0\nflask==1.0.0\nsix==1.15.0\n" {
+		t.Errorf("撤销事务后 = %q", got)
+	}
+}
+
+func TestDocument_UndoEditors(t *testing.T) {
+	content := "flask==1.0.0\n# comment\nsix==1.15.0\n"
+
+	pae := NewPositionAwareEditor()
+	doc, _ := pae.ParseRequirementsFile(content)
+	pae.RemovePackage(doc, "flask")
+	if err := pae.Undo(doc); err != nil {
+		t.Fatal(err)
+	}
+	if pae.SerializeToString(doc) != content || doc.Requirements[2].PositionInfo.LineNumber != 3 {
+		t.Errorf("撤销后位置信息没有同步: %+v", doc.Requirements[2].PositionInfo)
+	}
+	if err := pae.Redo(doc); err != nil || len(doc.Requirements) != 2 || doc.Requirements[1].PositionInfo.LineNumber != 2 {
+		t.Errorf("重做后位置信息没有同步: %v", err)
+	}
+
+	v2 := NewVersionEditorV2()
+	v2doc, _ := v2.ParseRequirementsFile(content)
+	v2.BatchUpdateVersions(v2doc, map[string]string{"flask": "==2.0.1", "six": "==1.16.0"})
+	if len(v2doc.Document().Journal()) != 1 {
+		t.Errorf("批量更新应记录为一项: %+v", v2doc.Document().Journal())
+	}
+	if err := v2.Undo(v2doc); err != nil || v2.SerializeToString(v2doc) != content || v2doc.Requirements[0].Version != "==1.0.0" {
+		t.Errorf("撤销批量更新失败: %v, %s", err, v2.SerializeToString(v2doc))
+	}
+}
+
+func TestTextChange(t *testing.T) {
+	tests := []struct {
+		before, after string
+		offset        int
+		removed       string
+		inserted      string
+	}{
+		{"flask==1.0\n", "flask==2.0\n", 7, "1", "2"},
+		{"a\nb\n", "a\nb\nc\n", 4, "", "c\n"},
+		{"a\nb\nc\n", "a\nc\n", 2, "b\n", ""},
+		{"x\n", "x\n", 2, "", ""},
+		// "é"和"è"的UTF-8编码首字节相同，区间不应截断字符
+		{"café\n", "cafè\n", 3, "é", "è"},
+	}
+
+	for _, tt := range tests {
+		offset, removed, inserted := textChange(tt.before, tt.after)
+		if offset != tt.offset || removed != tt.removed || inserted != tt.inserted {
+			t.Errorf("textChange(%q, %q) = %d, %q, %q", tt.before, tt.after, offset, removed, inserted)
+		}
+		if got := tt.before[:offset] + inserted + tt.before[offset+len(removed):]; got != tt.after {
+			t.Errorf("应用变化后 = %q, 期望 %q", got, tt.after)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package editor
+
+import (
+	"strings"
+)
+
+// 行终止符
+const (
+	lineEndingLF   = "\n"
+	lineEndingCRLF = "\r\n"
+)
+
+// splitLines 将文本拆分为行
+//
+// 返回不含行终止符的行内容和每行对应的行终止符（"\n"、"\r\n"，最后一行没有
+// 换行时为""）。以换行结尾的文本不会产生额外的空行，与parser的行数一致。
+//
+// 示例:
+//
+//	splitLines("a\r\nb\n")  // 返回 []string{"a", "b"}, []string{"\r\n", "\n"}
+//	splitLines("a\nb")      // 返回 []string{"a", "b"}, []string{"\n", ""}
+func splitLines(text string) ([]string, []string) {
+	var lines, endings []string
+	for len(text) > 0 {
+		idx := strings.IndexByte(text, '\n')
+		if idx == -1 {
+			lines = append(lines, text)
+			endings = append(endings, "")
+			break
+		}
+
+		line, ending := text[:idx], lineEndingLF
+		if strings.HasSuffix(line, "\r") {
+			line, ending = line[:len(line)-1], lineEndingCRLF
+		}
+		lines = append(lines, line)
+		endings = append(endings, ending)
+		text = text[idx+1:]
+	}
+	return lines, endings
+}
+
+// joinLines 按每行的行终止符将行重新拼接为文本
+func joinLines(lines, endings []string) string {
+	var sb strings.Builder
+	for i, line := range lines {
+		sb.WriteString(line)
+		if i < len(endings) {
+			sb.WriteString(endings[i])
+		}
+	}
+	return sb.String()
+}
+
+// dominantLineEnding 返回文档中占多数的行终止符，用于新增的行
+//
+// CRLF多于LF时返回"\r\n"，否则返回"\n"。
+func dominantLineEnding(endings []string) string {
+	crlf, lf := 0, 0
+	for _, ending := range endings {
+		switch ending {
+		case lineEndingCRLF:
+			crlf++
+		case lineEndingLF:
+			lf++
+		}
+	}
+	if crlf > lf {
+		return lineEndingCRLF
+	}
+	return lineEndingLF
+}
+
+// hasFinalNewline 检查文本的最后一行是否以换行结尾
+func hasFinalNewline(endings []string) bool {
+	return len(endings) > 0 && endings[len(endings)-1] != ""
+}
+
+// logicalLineEndings 计算每个逻辑行的行终止符
+//
+// 与parser一致，以"\"结尾的行会与下一行合并为一个逻辑行，
+// 逻辑行的行终止符取其最后一个物理行的终止符。
+func logicalLineEndings(lines, endings []string) []string {
+	var result []string
+	for i, line := range lines {
+		if strings.HasSuffix(line, "\\") && i < len(lines)-1 {
+			continue
+		}
+		result = append(result, endings[i])
+	}
+	return result
+}
@@ -0,0 +1,179 @@
+package editor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitLines(t *testing.T) {
+	testCases := []struct {
+		name        string
+		input       string
+		wantLines   []string
+		wantEndings []string
+	}{
+		{"空文本", "", nil, nil},
+		{"LF结尾", "a\nb\n", []string{"a", "b"}, []string{"\n", "\n"}},
+		{"无结尾换行", "a\nb", []string{"a", "b"}, []string{"\n", ""}},
+		{"CRLF", "a\r\nb\r\n", []string{"a", "b"}, []string{"\r\n", "\r\n"}},
+		{"混合行终止符", "a\r\nb\nc", []string{"a", "b", "c"}, []string{"\r\n", "\n", ""}},
+		{"空行", "a\n\nb\n", []string{"a", "", "b"}, []string{"\n", "\n", "\n"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lines, endings := splitLines(tc.input)
+			if strings.Join(lines, "|") != strings.Join(tc.wantLines, "|") || len(lines) != len(tc.wantLines) {
+				t.Errorf("splitLines(%q) lines = %q, want %q", tc.input, lines, tc.wantLines)
+			}
+			if strings.Join(endings, "|") != strings.Join(tc.wantEndings, "|") || len(endings) != len(tc.wantEndings) {
+				t.Errorf("splitLines(%q) endings = %q, want %q", tc.input, endings, tc.wantEndings)
+			}
+			if got := joinLines(lines, endings); got != tc.input {
+				t.Errorf("joinLines() = %q, want %q", got, tc.input)
+			}
+		})
+	}
+}
+
+func TestLogicalLineEndings(t *testing.T) {
+	lines, endings := splitLines("flask==1.0 \\\r\n    --hash=sha256:aaaa \\\r\n    --hash=sha256:bbbb\r\nrequests\n")
+	got := logicalLineEndings(lines, endings)
+	if len(got) != 2 || got[0] != "\r\n" || got[1] != "\n" {
+		t.Errorf("logicalLineEndings() = %q, want [\"\\r\\n\" \"\\n\"]", got)
+	}
+}
+
+func TestDominantLineEnding(t *testing.T) {
+	if got := dominantLineEnding([]string{"\r\n", "\r\n", "\n", ""}); got != "\r\n" {
+		t.Errorf("dominantLineEnding() = %q, want CRLF", got)
+	}
+	if got := dominantLineEnding([]string{"\r\n", "\n"}); got != "\n" {
+		t.Errorf("dominantLineEnding() = %q, want LF", got)
+	}
+	if got := dominantLineEnding(nil); got != "\n" {
+		t.Errorf("dominantLineEnding(nil) = %q, want LF", got)
+	}
+}
+
+// countChangedLines 统计两段文本中不同的行数
+func countChangedLines(before, after string) int {
+	beforeLines := strings.SplitAfter(before, "\n")
+	afterLines := strings.SplitAfter(after, "\n")
+	if len(beforeLines) != len(afterLines) {
+		return -1
+	}
+	changed := 0
+	for i := range beforeLines {
+		if beforeLines[i] != afterLines[i] {
+			changed++
+		}
+	}
+	return changed
+}
+
+func TestPositionAwareEditor_CRLF(t *testing.T) {
+	editor := NewPositionAwareEditor()
+	content := "# deps\r\nflask==1.0.0  # web\r\nrequests>=2.25.0\r\n"
+
+	doc, err := editor.ParseRequirementsFile(content)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+
+	if doc.LineEnding(2) != "\r\n" || !doc.HasFinalNewline() || doc.DefaultLineEnding() != "\r\n" {
+		t.Errorf("行终止符信息不正确: line2=%q final=%v default=%q",
+			doc.LineEnding(2), doc.HasFinalNewline(), doc.DefaultLineEnding())
+	}
+
+	flask, err := editor.GetPackageInfo(doc, "flask")
+	if err != nil {
+		t.Fatalf("获取flask失败: %v", err)
+	}
+	if flask.PositionInfo.EndColumn != len("flask==1.0.0  # web") {
+		t.Errorf("EndColumn不应包含\\r: %d", flask.PositionInfo.EndColumn)
+	}
+
+	if err := editor.UpdatePackageVersion(doc, "flask", "==2.0.1"); err != nil {
+		t.Fatalf("更新版本失败: %v", err)
+	}
+
+	result := editor.SerializeToString(doc)
+	want := "# deps\r\nflask==2.0.1  # web\r\nrequests>=2.25.0\r\n"
+	if result != want {
+		t.Errorf("SerializeToString() = %q, want %q", result, want)
+	}
+	if changed := countChangedLines(content, result); changed != 1 {
+		t.Errorf("期望只修改1行，实际修改了%d行", changed)
+	}
+}
+
+func TestVersionEditorV2_LineEndings(t *testing.T) {
+	editor := NewVersionEditorV2()
+
+	t.Run("CRLF单行diff", func(t *testing.T) {
+		content := "flask==1.0.0\r\nrequests>=2.25.0\r\ndjango>=3.2\r\n"
+		doc, err := editor.ParseRequirementsFile(content)
+		if err != nil {
+			t.Fatalf("解析失败: %v", err)
+		}
+
+		if err := editor.UpdatePackageVersion(doc, "requests", ">=2.31.0"); err != nil {
+			t.Fatalf("更新版本失败: %v", err)
+		}
+
+		result := editor.SerializeToString(doc)
+		if want := "flask==1.0.0\r\nrequests>=2.31.0\r\ndjango>=3.2\r\n"; result != want {
+			t.Errorf("SerializeToString() = %q, want %q", result, want)
+		}
+		if changed := countChangedLines(content, result); changed != 1 {
+			t.Errorf("期望只修改1行，实际修改了%d行", changed)
+		}
+	})
+
+	t.Run("混合行终止符", func(t *testing.T) {
+		content := "flask==1.0.0\r\nrequests>=2.25.0\ndjango>=3.2"
+		doc, err := editor.ParseRequirementsFile(content)
+		if err != nil {
+			t.Fatalf("解析失败: %v", err)
+		}
+
+		if result := editor.SerializeToString(doc); result != content {
+			t.Errorf("SerializeToString() = %q, want %q", result, content)
+		}
+	})
+
+	t.Run("无结尾换行时添加包", func(t *testing.T) {
+		doc, err := editor.ParseRequirementsFile("flask==1.0.0\r\nrequests>=2.25.0")
+		if err != nil {
+			t.Fatalf("解析失败: %v", err)
+		}
+		if doc.HasFinalNewline() {
+			t.Error("HasFinalNewline() = true, want false")
+		}
+
+		if err := editor.AddPackage(doc, "django", ">=3.2", nil, ""); err != nil {
+			t.Fatalf("添加包失败: %v", err)
+		}
+
+		want := "flask==1.0.0\r\nrequests>=2.25.0\r\ndjango>=3.2"
+		if result := editor.SerializeToString(doc); result != want {
+			t.Errorf("SerializeToString() = %q, want %q", result, want)
+		}
+	})
+
+	t.Run("保留结尾换行", func(t *testing.T) {
+		doc, err := editor.ParseRequirementsFile("flask==1.0.0\n")
+		if err != nil {
+			t.Fatalf("解析失败: %v", err)
+		}
+
+		if err := editor.AddPackage(doc, "django", ">=3.2", nil, ""); err != nil {
+			t.Fatalf("添加包失败: %v", err)
+		}
+
+		if result := editor.SerializeToString(doc); result != "flask==1.0.0\ndjango>=3.2\n" {
+			t.Errorf("SerializeToString() = %q", result)
+		}
+	})
+}
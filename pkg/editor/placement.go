@@ -0,0 +1,267 @@
+package editor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/scagogogo/python-requirements-parser/pkg/models"
+)
+
+// PlacementMode 新requirement的插入方式
+type PlacementMode int
+
+const (
+	// PlaceAtEnd 插入到文件末尾（末尾的空行之前）
+	PlaceAtEnd PlacementMode = iota
+
+	// PlaceAfter 插入到指定包之后（包括其下方的注释，如pip-compile的"# via"）
+	PlaceAfter
+
+	// PlaceAlphabetical 在区段内按包名的字母顺序插入
+	PlaceAlphabetical
+)
+
+// Placement 指定新requirement在文档中的插入位置
+//
+// 使用AtEnd、AfterPackage或Alphabetical创建。
+type Placement struct {
+	// Mode 插入方式
+	Mode PlacementMode
+
+	// Package PlaceAfter时作为参照的包名、本地路径或URL
+	Package string
+
+	// Section PlaceAlphabetical时的区段标题，即区段开头注释行的内容（不区分大小写），
+	// 为空时在整个文件范围内按字母顺序插入
+	Section string
+}
+
+// AtEnd 返回插入到文件末尾的Placement
+func AtEnd() Placement {
+	return Placement{Mode: PlaceAtEnd}
+}
+
+// AfterPackage 返回插入到指定包之后的Placement
+func AfterPackage(name string) Placement {
+	return Placement{Mode: PlaceAfter, Package: name}
+}
+
+// Alphabetical 返回在区段内按字母顺序插入的Placement
+//
+// 区段由空行之后（或文件开头）的注释行开始，到下一个这样的注释行为止，例如：
+//
+//	# Web frameworks
+//	django==3.2.0
+//	flask==2.0.1
+//
+//	# Testing
+//	pytest==7.0.0
+//
+// Alphabetical("Web frameworks")会把"fastapi"插入到django和flask之间。
+func Alphabetical(section string) Placement {
+	return Placement{Mode: PlaceAlphabetical, Section: section}
+}
+
+// AddAt 按placement指定的位置添加一行，返回解析得到的requirement
+//
+// 示例:
+//
+//	doc.AddAt("fastapi==0.95.0", editor.Alphabetical("Web frameworks"))
+//	doc.AddAt("gunicorn==20.1.0", editor.AfterPackage("flask"))
+func (d *Document) AddAt(text string, placement Placement) (*models.Requirement, error) {
+	defer d.record(OpAdd, "", text)()
+	var name string
+	if reqs, err := d.parser.ParseString(text); err == nil && len(reqs) == 1 {
+		name = reqs[0].Name
+	}
+
+	index, err := d.insertionIndex(name, placement)
+	if err != nil {
+		return nil, err
+	}
+	return d.Insert(index, text)
+}
+
+// insertionIndex 返回按placement插入名为name的requirement时的位置
+func (d *Document) insertionIndex(name string, placement Placement) (int, error) {
+	switch placement.Mode {
+	case PlaceAtEnd:
+		index := len(d.reqs)
+		for index > 0 && d.reqs[index-1].EffectiveKind() == models.KindBlank {
+			index--
+		}
+		return index, nil
+
+	case PlaceAfter:
+		i, err := d.lookup(placement.Package)
+		if err != nil {
+			return -1, err
+		}
+		return d.annotationEnd(i), nil
+
+	case PlaceAlphabetical:
+		if name == "" {
+			return -1, fmt.Errorf("按字母顺序插入需要包名")
+		}
+		start, end := 0, len(d.reqs)
+		if placement.Section != "" {
+			var err error
+			if start, end, err = d.section(placement.Section); err != nil {
+				return -1, err
+			}
+		}
+		return d.alphabeticalIndex(name, start, end), nil
+	}
+
+	return -1, fmt.Errorf("无效的插入方式: %d", placement.Mode)
+}
+
+// alphabeticalIndex 返回在[start, end)范围内按字母顺序插入name的位置
+//
+// 插入到第一个名称更大的requirement及其上方注释之前；没有更大的名称时
+// 插入到范围内最后一个requirement及其下方注释之后。
+func (d *Document) alphabeticalIndex(name string, start, end int) int {
+	key := models.NormalizeName(name)
+	last := -1
+	for i := start; i < end; i++ {
+		req := d.reqs[i]
+		if !req.IsInstallable() || req.Name == "" {
+			continue
+		}
+		if models.NormalizeName(req.Name) > key {
+			first, _ := d.attachedRange(i)
+			if first < start {
+				first = start
+			}
+			return first
+		}
+		last = i
+	}
+
+	if last != -1 {
+		return d.annotationEnd(last)
+	}
+	// 区段中还没有requirement时插入到标题之后
+	index := start
+	for index < end && d.reqs[index].EffectiveKind() == models.KindComment {
+		index++
+	}
+	return index
+}
+
+// section 返回标题为title的区段中标题之后的行范围[start, end)
+func (d *Document) section(title string) (int, int, error) {
+	title = strings.TrimSpace(title)
+	for i := range d.reqs {
+		if !d.isSectionHeader(i) {
+			continue
+		}
+
+		// 标题可以由连续多行注释组成，任意一行匹配即可
+		start := i
+		matched := false
+		for start < len(d.reqs) && d.reqs[start].EffectiveKind() == models.KindComment {
+			matched = matched || strings.EqualFold(d.reqs[start].Comment, title)
+			start++
+		}
+		if !matched {
+			continue
+		}
+
+		end := start
+		for end < len(d.reqs) && !d.isSectionHeader(end) {
+			end++
+		}
+		return start, end, nil
+	}
+	return -1, -1, fmt.Errorf("未找到区段: %s", title)
+}
+
+// isSectionHeader 检查第i行是否为区段标题的开头：位于文件开头或空行之后的注释行
+func (d *Document) isSectionHeader(i int) bool {
+	if d.reqs[i].EffectiveKind() != models.KindComment || d.isAnnotation(i) {
+		return false
+	}
+	return i == 0 || d.reqs[i-1].EffectiveKind() == models.KindBlank
+}
+
+// isAnnotation 检查第i行是否为缩进的注释行，如pip-compile生成的"    # via flask"
+func (d *Document) isAnnotation(i int) bool {
+	if d.reqs[i].EffectiveKind() != models.KindComment {
+		return false
+	}
+	text := d.file.Lines[i].Text()
+	return strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t")
+}
+
+// annotationEnd 返回第i行及其下方缩进注释之后的位置
+func (d *Document) annotationEnd(i int) int {
+	end := i + 1
+	for end < len(d.reqs) && d.isAnnotation(end) {
+		end++
+	}
+	return end
+}
+
+// attachedRange 返回第i行requirement及其附属注释所占的行范围[start, end)
+//
+// 附属注释包括：
+//   - 下方紧跟的缩进注释行，如pip-compile生成的"# via"注释；
+//   - 上方紧挨着的注释行，前提是它们位于两个requirement之间，
+//     或者移除该requirement后它们下方不再有内容（否则视为区段标题而保留）。
+func (d *Document) attachedRange(i int) (int, int) {
+	end := d.annotationEnd(i)
+
+	start := i
+	for start > 0 && d.reqs[start-1].EffectiveKind() == models.KindComment && !d.isAnnotation(start-1) {
+		start--
+	}
+	if start < i {
+		between := start > 0 && d.reqs[start-1].EffectiveKind() != models.KindBlank
+		orphaned := end == len(d.reqs) || d.reqs[end].EffectiveKind() == models.KindBlank ||
+			d.isSectionHeader(end)
+		if !between && !orphaned {
+			start = i
+		}
+	}
+	return start, end
+}
+
+// RemoveWithComments 移除requirement以及其附属的注释行
+//
+// 除了requirement所在的逻辑行（包括所有续行）外，还会移除其下方的缩进注释
+// （如pip-compile的"# via"）以及只属于它的上方注释。移除后如果留下两个相邻的空行，
+// 则只保留一个。
+//
+// 示例:
+//
+//	// 原文件:
+//	//   # HTTP
+//	//   requests==2.28.0
+//	//       # via -r requirements.in
+//	//
+//	//   six==1.16.0
+//	doc.RemoveWithComments("requests")
+//	// 结果:
+//	//   six==1.16.0
+func (d *Document) RemoveWithComments(name string) error {
+	defer d.record(OpRemove, name, "")()
+	i, err := d.lookup(name)
+	if err != nil {
+		return err
+	}
+
+	start, end := d.attachedRange(i)
+	blank := func(j int) bool { return d.reqs[j].EffectiveKind() == models.KindBlank }
+	switch {
+	case end < len(d.reqs) && blank(end) && (start == 0 || blank(start-1)):
+		end++
+	case end == len(d.reqs) && start > 0 && blank(start-1):
+		start--
+	}
+
+	for j := end - 1; j >= start; j-- {
+		d.removeLine(j)
+	}
+	return nil
+}
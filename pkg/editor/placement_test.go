@@ -0,0 +1,176 @@
+package editor
+
+import (
+	"strings"
+	"testing"
+)
+
+const sectionedRequirements = `# Web frameworks
+django==3.2.0
+flask==2.0.1
+
+# Testing
+pytest==7.0.0
+    # via -r requirements.in
+tox==4.0.0
+`
+
+func TestDocument_AddAt(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		line      string
+		placement Placement
+		expected  string
+	}{
+		{
+			name:      "End before trailing blank lines",
+			content:   "flask==2.0.1\n\n\n",
+			line:      "requests",
+			placement: AtEnd(),
+			expected:  "flask==2.0.1\nrequests\n\n\n",
+		},
+		{
+			name:      "After package with annotation",
+			content:   sectionedRequirements,
+			line:      "pytest-cov==4.0.0",
+			placement: AfterPackage("pytest"),
+			expected:  strings.Replace(sectionedRequirements, "requirements.in\n", "requirements.in\npytest-cov==4.0.0\n", 1),
+		},
+		{
+			name:      "Alphabetical in middle of section",
+			content:   sectionedRequirements,
+			line:      "fastapi==0.95.0",
+			placement: Alphabetical("web frameworks"),
+			expected:  strings.Replace(sectionedRequirements, "flask", "fastapi==0.95.0\nflask", 1),
+		},
+		{
+			name:      "Alphabetical at end of section",
+			content:   sectionedRequirements,
+			line:      "zope.interface",
+			placement: Alphabetical("Web frameworks"),
+			expected:  strings.Replace(sectionedRequirements, "flask==2.0.1\n", "flask==2.0.1\nzope.interface\n", 1),
+		},
+		{
+			name:      "Alphabetical at start of section",
+			content:   sectionedRequirements,
+			line:      "coverage",
+			placement: Alphabetical("Testing"),
+			expected:  strings.Replace(sectionedRequirements, "# Testing\n", "# Testing\ncoverage\n", 1),
+		},
+		{
+			name:      "Alphabetical after annotated requirement",
+			content:   sectionedRequirements,
+			line:      "requests",
+			placement: Alphabetical("Testing"),
+			expected:  strings.Replace(sectionedRequirements, "requirements.in\n", "requirements.in\nrequests\n", 1),
+		},
+		{
+			name:      "Alphabetical in empty section",
+			content:   "# Core\n\n# Dev\n",
+			line:      "black",
+			placement: Alphabetical("Core"),
+			expected:  "# Core\nblack\n\n# Dev\n",
+		},
+		{
+			name:      "Alphabetical across whole file",
+			content:   "Django==3.2\nflask==2.0\nrequests\n",
+			line:      "Flask_Login",
+			placement: Alphabetical(""),
+			expected:  "Django==3.2\nflask==2.0\nFlask_Login\nrequests\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, _ := ParseDocument(tt.content)
+			if _, err := doc.AddAt(tt.line, tt.placement); err != nil {
+				t.Fatalf("AddAt失败: %v", err)
+			}
+			if got := doc.String(); got != tt.expected {
+				t.Errorf("结果:\n%s\n期望:\n%s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDocument_AddAtErrors(t *testing.T) {
+	doc, _ := ParseDocument(sectionedRequirements)
+
+	if _, err := doc.AddAt("requests", Alphabetical("Docs")); err == nil || !strings.Contains(err.Error(), "未找到区段") {
+		t.Errorf("不存在的区段应返回错误: %v", err)
+	}
+	if _, err := doc.AddAt("requests", AfterPackage("numpy")); err == nil {
+		t.Errorf("不存在的参照包应返回错误")
+	}
+	if _, err := doc.AddAt("./libs/core", Alphabetical("")); err == nil {
+		t.Errorf("没有包名时按字母顺序插入应返回错误")
+	}
+	if doc.String() != sectionedRequirements {
+		t.Errorf("失败的插入不应修改文档")
+	}
+}
+
+func TestDocument_RemoveWithComments(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		pkg      string
+		expected string
+	}{
+		{
+			name:     "Keeps section header",
+			content:  sectionedRequirements,
+			pkg:      "django",
+			expected: strings.Replace(sectionedRequirements, "django==3.2.0\n", "", 1),
+		},
+		{
+			name:     "Removes pip-compile annotation",
+			content:  sectionedRequirements,
+			pkg:      "pytest",
+			expected: strings.Replace(sectionedRequirements, "pytest==7.0.0\n    # via -r requirements.in\n", "", 1),
+		},
+		{
+			name:     "Removes comment between requirements",
+			content:  "flask==2.0.1\n# pinned for CVE-2023-1234\nrequests==2.31.0\nsix\n",
+			pkg:      "requests",
+			expected: "flask==2.0.1\nsix\n",
+		},
+		{
+			name:     "Removes orphaned header and extra blank line",
+			content:  "flask==2.0.1\n\n# Legacy\nsix==1.16.0\n\n# Testing\npytest\n",
+			pkg:      "six",
+			expected: "flask==2.0.1\n\n# Testing\npytest\n",
+		},
+		{
+			name:     "Removes trailing section",
+			content:  "flask==2.0.1\n\n# Legacy\nsix==1.16.0\n",
+			pkg:      "six",
+			expected: "flask==2.0.1\n",
+		},
+		{
+			name:     "Removes continuation lines",
+			content:  "django==3.2.0 \\\n    --hash=sha256:aaaa \\\n    --hash=sha256:bbbb\n    # via -r requirements.in\nflask==2.0.1",
+			pkg:      "django",
+			expected: "flask==2.0.1",
+		},
+		{
+			name:     "Last line without final newline",
+			content:  "flask==2.0.1\nsix==1.16.0\n    # via flask",
+			pkg:      "six",
+			expected: "flask==2.0.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, _ := ParseDocument(tt.content)
+			if err := doc.RemoveWithComments(tt.pkg); err != nil {
+				t.Fatalf("RemoveWithComments失败: %v", err)
+			}
+			if got := doc.String(); got != tt.expected {
+				t.Errorf("结果:\n%q\n期望:\n%q", got, tt.expected)
+			}
+		})
+	}
+}
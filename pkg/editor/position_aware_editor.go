@@ -2,7 +2,7 @@ package editor
 
 import (
 	"fmt"
-	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/scagogogo/python-requirements-parser/pkg/models"
@@ -13,136 +13,147 @@ import (
 // 能够记住原始文本的位置信息，实现最小化diff的编辑
 type PositionAwareEditor struct {
 	parser *parser.Parser
+
+	// HashPolicy 修改带哈希的包的版本时的处理方式，默认为HashPolicyFail
+	HashPolicy HashPolicy
+
+	// HashProvider HashPolicy为HashPolicyProvider时提供新版本的哈希
+	HashProvider HashProvider
 }
 
 // NewPositionAwareEditor 创建一个新的位置感知编辑器
 func NewPositionAwareEditor() *PositionAwareEditor {
+	p := parser.New()
+	p.RecordPositions = true
 	return &PositionAwareEditor{
-		parser: parser.New(),
+		parser: p,
 	}
 }
 
 // PositionAwareDocument 包含位置信息的文档
+//
+// Requirements与底层Document保持同步，每次编辑后更新，其中的PositionInfo
+// 对应当前文档的文本。
 type PositionAwareDocument struct {
 	Requirements []*models.Requirement
-	originalText string
-	lines        []string
+	doc          *Document
 }
 
-// ParseRequirementsFile 解析requirements文件并记录位置信息
-func (e *PositionAwareEditor) ParseRequirementsFile(content string) (*PositionAwareDocument, error) {
-	// 首先使用标准parser解析
-	reqs, err := e.parser.ParseString(content)
-	if err != nil {
-		return nil, fmt.Errorf("解析requirements文件失败: %w", err)
-	}
-
-	// 分割为行
-	lines := strings.Split(content, "\n")
+// Document 返回底层的可编辑文档，可以使用其完整的编辑接口
+func (doc *PositionAwareDocument) Document() *Document {
+	return doc.doc
+}
 
-	// 为每个requirement添加位置信息
-	err = e.addPositionInfo(reqs, lines)
-	if err != nil {
-		return nil, fmt.Errorf("添加位置信息失败: %w", err)
+// LineEnding 返回指定行（从1开始）的行终止符，最后一行没有换行时为""
+func (doc *PositionAwareDocument) LineEnding(lineNumber int) string {
+	_, endings := splitLines(doc.doc.file.String())
+	if lineNumber < 1 || lineNumber > len(endings) {
+		return ""
 	}
-
-	return &PositionAwareDocument{
-		Requirements: reqs,
-		originalText: content,
-		lines:        lines,
-	}, nil
+	return endings[lineNumber-1]
 }
 
-// addPositionInfo 为requirements添加位置信息
-func (e *PositionAwareEditor) addPositionInfo(reqs []*models.Requirement, lines []string) error {
-	for i, req := range reqs {
-		if req.OriginalLine == "" {
-			continue
-		}
-
-		// 找到对应的行
-		lineNumber := e.findLineNumber(req.OriginalLine, lines)
-		if lineNumber == -1 {
-			// 如果找不到精确匹配，使用索引作为行号
-			lineNumber = i
-		}
+// DefaultLineEnding 返回文档中占多数的行终止符（"\n"或"\r\n"）
+func (doc *PositionAwareDocument) DefaultLineEnding() string {
+	return doc.doc.DefaultLineEnding()
+}
 
-		if lineNumber >= len(lines) {
-			continue
-		}
+// HasFinalNewline 返回文档是否以换行结尾
+func (doc *PositionAwareDocument) HasFinalNewline() bool {
+	return doc.doc.HasFinalNewline()
+}
 
-		line := lines[lineNumber]
-		posInfo := &models.PositionInfo{
-			LineNumber:  lineNumber + 1, // 行号从1开始
-			StartColumn: 0,
-			EndColumn:   len(line),
-		}
+// Encoding 返回文档原始的编码和BOM状态
+func (doc *PositionAwareDocument) Encoding() parser.Encoding {
+	return doc.doc.Encoding()
+}
 
-		// 如果是普通包依赖，尝试找到版本约束的位置
-		if !req.IsComment && !req.IsEmpty && !req.IsFileRef && !req.IsConstraint && req.Name != "" {
-			e.findVersionPosition(req, line, posInfo)
-		}
+// ParseRequirementsFile 解析requirements文件并记录位置信息
+//
+// content为已解码的文本，开头的UTF-8 BOM会被记录并在序列化时还原。
+func (e *PositionAwareEditor) ParseRequirementsFile(content string) (*PositionAwareDocument, error) {
+	text, enc := decodeText(content)
+	return e.parseText(text, enc)
+}
 
-		// 找到注释的位置
-		if req.Comment != "" {
-			e.findCommentPosition(line, posInfo)
-		}
+// ParseRequirementsBytes 解析requirements文件的原始字节
+//
+// 编码按照pip的规则由BOM或PEP 263编码声明检测，SerializeToBytes会按原编码和BOM状态写回。
+func (e *PositionAwareEditor) ParseRequirementsBytes(data []byte) (*PositionAwareDocument, error) {
+	text, enc, err := parser.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("解码requirements文件失败: %w", err)
+	}
+	return e.parseText(text, enc)
+}
 
-		req.PositionInfo = posInfo
+// parseText 解析已解码的文本并记录位置信息
+func (e *PositionAwareEditor) parseText(content string, enc parser.Encoding) (*PositionAwareDocument, error) {
+	d, err := newDocument(e.parser, content, enc, "")
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	doc := &PositionAwareDocument{doc: d}
+	e.sync(doc)
+	return doc, nil
 }
 
-// findLineNumber 在lines中找到匹配originalLine的行号
-func (e *PositionAwareEditor) findLineNumber(originalLine string, lines []string) int {
-	for i, line := range lines {
-		if strings.TrimSpace(line) == strings.TrimSpace(originalLine) {
-			return i
+// sync 在编辑后同步Requirements并更新位置信息
+//
+// 位置信息由解析器在解析时记录。Document逐行解析，因此记录的位置相对于
+// requirement所在的逻辑行，这里按每个逻辑行在当前文本中的位置整体平移。
+// 未修改的行已经是正确的位置，平移量为0。
+func (e *PositionAwareEditor) sync(doc *PositionAwareDocument) {
+	doc.Requirements = doc.doc.Requirements()
+
+	offset, lineNumber := 0, 1
+	for i, line := range doc.doc.file.Lines {
+		if info := doc.doc.reqs[i].PositionInfo; info != nil {
+			shiftPositionInfo(info, lineNumber-info.LineNumber, offset-info.Offset)
 		}
+		offset += len(line.String())
+		lineNumber += line.PhysicalLines()
 	}
-	return -1
 }
 
-// findVersionPosition 找到版本约束在行中的位置
-func (e *PositionAwareEditor) findVersionPosition(req *models.Requirement, line string, posInfo *models.PositionInfo) {
-	if req.Version == "" {
+// shiftPositionInfo 将位置信息整体平移lines行、bytes个字节，列号不变
+func shiftPositionInfo(info *models.PositionInfo, lines, bytes int) {
+	if lines == 0 && bytes == 0 {
 		return
 	}
 
-	// 构建包名模式（包括可能的extras）
-	packagePattern := regexp.QuoteMeta(req.Name)
-	if len(req.Extras) > 0 {
-		extrasStr := "[" + strings.Join(req.Extras, ",") + "]"
-		packagePattern += regexp.QuoteMeta(extrasStr)
+	shift := func(span *models.Span) {
+		span.Start += bytes
+		span.End += bytes
+		span.Line += lines
+		span.EndLine += lines
 	}
 
-	// 查找版本约束的位置
-	versionPattern := regexp.QuoteMeta(req.Version)
-	fullPattern := packagePattern + `\s*` + versionPattern
-
-	re := regexp.MustCompile(fullPattern)
-	match := re.FindStringIndex(line)
-	if match != nil {
-		// 找到版本约束的具体位置
-		versionStart := strings.Index(line[match[0]:], req.Version)
-		if versionStart != -1 {
-			posInfo.VersionStartColumn = match[0] + versionStart
-			posInfo.VersionEndColumn = posInfo.VersionStartColumn + len(req.Version)
+	info.LineNumber += lines
+	info.Offset += bytes
+	for _, span := range []*models.Span{info.Name, info.Extras, info.Specifier, info.Markers, info.Comment} {
+		if span != nil {
+			shift(span)
 		}
 	}
-}
-
-// findCommentPosition 找到注释在行中的位置
-func (e *PositionAwareEditor) findCommentPosition(line string, posInfo *models.PositionInfo) {
-	// 查找 # 符号的位置
-	commentIndex := strings.Index(line, "#")
-	if commentIndex != -1 {
-		posInfo.CommentStartColumn = commentIndex
+	for name, span := range info.Options {
+		shift(&span)
+		info.Options[name] = span
+	}
+	for i := range info.Hashes {
+		shift(&info.Hashes[i])
 	}
 }
 
 // UpdatePackageVersion 更新指定包的版本（最小化diff）
+//
+// 已有版本约束时只替换约束本身；没有版本约束时（如"flask"、"flask[async]"、
+// "flask --global-option=x"）插入到包名和extras之后、环境标记、选项和注释之前。
+// 行中的其他内容保持不变。
+//
+// 包带有哈希（--hash）时按HashPolicy处理：默认返回错误，
+// 也可以移除旧哈希或从HashProvider获取新版本的哈希。
 func (e *PositionAwareEditor) UpdatePackageVersion(doc *PositionAwareDocument, packageName, newVersion string) error {
 	if newVersion == "" {
 		return fmt.Errorf("版本约束不能为空")
@@ -153,83 +164,270 @@ func (e *PositionAwareEditor) UpdatePackageVersion(doc *PositionAwareDocument, p
 		return err
 	}
 
-	// 查找要更新的包
-	var targetReq *models.Requirement
-	for _, req := range doc.Requirements {
-		if req.Name == packageName && !req.IsComment && !req.IsEmpty {
-			targetReq = req
-			break
-		}
+	i, err := doc.doc.lookup(packageName)
+	if err != nil {
+		return err
 	}
-
-	if targetReq == nil {
-		return fmt.Errorf("在requirements中未找到包: %s", packageName)
+	if err := doc.doc.setVersionHashed(i, newVersion, e.HashPolicy, e.HashProvider); err != nil {
+		return err
 	}
-
-	// 更新版本
-	targetReq.Version = newVersion
-
+	e.sync(doc)
 	return nil
 }
 
-// SerializeToString 将文档序列化为字符串（最小化diff）
-func (e *PositionAwareEditor) SerializeToString(doc *PositionAwareDocument) string {
-	// 复制原始行
-	lines := make([]string, len(doc.lines))
-	copy(lines, doc.lines)
+// PinFromFreeze 按pip freeze的输出固定文档中的包版本（最小化diff）
+//
+// freeze中的每个"name==version"行都会应用到文档中同名（按PEP 503规范化比较）的普通包：
+// 没有版本约束或版本约束不是精确版本（==或===）的包被改为"==version"，
+// 已经精确固定的包保持不变。freeze中的注释、可编辑安装和直接引用会被忽略，
+// 文档中不存在的包也会被忽略。
+// 带哈希的包按HashPolicy处理，见UpdatePackageVersion。
+// 所有修改在一个事务中执行：任一包固定失败时文档保持不变，
+// 成功时编辑历史中只记录一条OpTransaction，一次Undo即可撤销。
+//
+// 参数:
+//   - doc: 要修改的文档
+//   - freeze: pip freeze的输出
+//
+// 返回:
+//   - []string: 被修改的包名，按在文档中出现的顺序排列
+//   - error: 解析freeze输出失败或任一包固定失败时返回错误，此时文档没有被修改
+//
+// 示例:
+//
+//	out, _ := exec.Command("pip", "freeze").Output()
+//	pinned, err := editor.PinFromFreeze(doc, string(out))
+func (e *PositionAwareEditor) PinFromFreeze(doc *PositionAwareDocument, freeze string) ([]string, error) {
+	reqs, err := e.parser.ParseString(freeze)
+	if err != nil {
+		return nil, fmt.Errorf("解析pip freeze输出失败: %w", err)
+	}
 
-	// 应用修改
-	for _, req := range doc.Requirements {
-		if req.PositionInfo == nil || req.IsComment || req.IsEmpty {
+	versions := make(map[string]string)
+	for _, req := range reqs {
+		if req.EffectiveKind() != models.KindPackage || req.Name == "" ||
+			!strings.HasPrefix(req.Version, "==") || strings.Contains(req.Version, ",") {
 			continue
 		}
+		versions[models.NormalizeName(req.Name)] = req.Version
+	}
 
-		lineIndex := req.PositionInfo.LineNumber - 1
-		if lineIndex < 0 || lineIndex >= len(lines) {
+	// 按行修改，同一个包出现多次（如带不同环境标记）时每一行都会被固定
+	var pinned []string
+	tx := e.Begin(doc)
+	for _, req := range doc.doc.reqs {
+		if req.EffectiveKind() != models.KindPackage || req.Name == "" {
 			continue
 		}
+		version, ok := versions[models.NormalizeName(req.Name)]
+		if !ok || models.IsExactPin(req.Version) {
+			continue
+		}
+		tx.updateRequirementVersion(req, version)
+		pinned = append(pinned, req.Name)
+	}
+	if len(pinned) == 0 {
+		return nil, nil
+	}
+	if _, err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("固定版本失败: %w", err)
+	}
 
-		// 如果版本约束有位置信息且被修改了，进行精确替换
-		if req.PositionInfo.VersionStartColumn > 0 && req.PositionInfo.VersionEndColumn > 0 && req.Version != "" {
-			line := lines[lineIndex]
-
-			// 提取原始版本约束
-			if req.PositionInfo.VersionEndColumn <= len(line) {
-				// 替换版本约束部分
-				newLine := line[:req.PositionInfo.VersionStartColumn] +
-					req.Version +
-					line[req.PositionInfo.VersionEndColumn:]
-				lines[lineIndex] = newLine
-			}
+	return pinned, nil
+}
+
+// AddPackage 添加新的包依赖（最小化diff）
+//
+// 新的依赖以规范格式插入到placement指定的位置，文件的其他行保持不变。
+// packageName的含义与VersionEditorV2.AddPackage相同。
+//
+// 示例:
+//
+//	editor.AddPackage(doc, "fastapi", "==0.95.0", nil, "", editor.Alphabetical("Web frameworks"))
+//	editor.AddPackage(doc, "gunicorn", "", nil, "", editor.AfterPackage("flask"))
+func (e *PositionAwareEditor) AddPackage(doc *PositionAwareDocument, packageName, version string, extras []string, markers string, placement Placement) error {
+	newReq, err := newRequirement(e.parser, packageName)
+	if err != nil {
+		return err
+	}
+
+	// 检查包是否已存在
+	if doc.doc.Index(newReq.Key()) != -1 {
+		return fmt.Errorf("包 %s 已存在", packageName)
+	}
+
+	if version != "" {
+		if newReq.IsLocalPath || newReq.IsURL || newReq.IsVCS {
+			return fmt.Errorf("本地路径或URL依赖不支持版本约束: %s", packageName)
 		}
+		if err := e.validateVersionSpecifier(version); err != nil {
+			return err
+		}
+		newReq.Version = version
+	}
+	if len(extras) > 0 {
+		newReq.Extras = extras
 	}
+	newReq.Markers = markers
 
-	return strings.Join(lines, "\n")
+	if _, err := doc.doc.AddAt(newReq.String(), placement); err != nil {
+		return err
+	}
+	e.sync(doc)
+	return nil
 }
 
-// validateVersionSpecifier 验证版本约束格式
-func (e *PositionAwareEditor) validateVersionSpecifier(version string) error {
-	if version == "" {
-		return fmt.Errorf("版本约束不能为空")
+// RemovePackage 移除指定的包（最小化diff）
+//
+// 移除包所在的逻辑行（包括续行中的哈希）以及附属的注释行，
+// 见Document.RemoveWithComments。
+func (e *PositionAwareEditor) RemovePackage(doc *PositionAwareDocument, packageName string) error {
+	if err := doc.doc.RemoveWithComments(packageName); err != nil {
+		return err
+	}
+	e.sync(doc)
+	return nil
+}
+
+// UpdatePackageMarkers 设置包的环境标记（最小化diff），markers为空时移除环境标记
+//
+// 已有环境标记时只替换";"之后的表达式；没有时以" ; "插入到版本约束之后、
+// 选项、哈希和注释之前。
+//
+// 示例:
+//
+//	editor.UpdatePackageMarkers(doc, "pywin32", "sys_platform == 'win32'")
+//	// "pywin32==306  # windows" -> "pywin32==306 ; sys_platform == 'win32'  # windows"
+func (e *PositionAwareEditor) UpdatePackageMarkers(doc *PositionAwareDocument, packageName, markers string) error {
+	if err := doc.doc.SetMarkers(packageName, strings.TrimSpace(markers)); err != nil {
+		return err
+	}
+	e.sync(doc)
+	return nil
+}
+
+// AddPackageExtras 为包添加extras（最小化diff）
+//
+// 已存在的extras（按PEP 503规范化后比较）会被忽略，新的extras追加到原有extras之后，
+// 并沿用原有的分隔风格；没有extras时在包名之后插入"[...]"。
+//
+// 示例:
+//
+//	editor.AddPackageExtras(doc, "django", "argon2")  // "django[bcrypt]>=4.2" -> "django[bcrypt,argon2]>=4.2"
+func (e *PositionAwareEditor) AddPackageExtras(doc *PositionAwareDocument, packageName string, extras ...string) error {
+	req := doc.doc.Find(packageName)
+	if req == nil {
+		return fmt.Errorf("在requirements中未找到包: %s", packageName)
+	}
+
+	merged := append([]string(nil), req.Extras...)
+	for _, extra := range extras {
+		if extra = strings.TrimSpace(extra); extra != "" && indexExtra(merged, extra) == -1 {
+			merged = append(merged, extra)
+		}
+	}
+	if len(merged) == len(req.Extras) {
+		return nil
 	}
 
-	// 简单的版本格式验证
-	validPrefixes := []string{"==", ">=", "<=", ">", "<", "~=", "!=", "==="}
-	for _, prefix := range validPrefixes {
-		if strings.HasPrefix(version, prefix) {
-			return nil
+	if err := doc.doc.SetExtras(packageName, merged); err != nil {
+		return err
+	}
+	e.sync(doc)
+	return nil
+}
+
+// RemovePackageExtras 移除包的指定extras（最小化diff），不存在的extras会被忽略
+//
+// 移除后没有剩余的extras时，整个"[...]"都会被移除。
+func (e *PositionAwareEditor) RemovePackageExtras(doc *PositionAwareDocument, packageName string, extras ...string) error {
+	req := doc.doc.Find(packageName)
+	if req == nil {
+		return fmt.Errorf("在requirements中未找到包: %s", packageName)
+	}
+
+	var remaining []string
+	for _, extra := range req.Extras {
+		if indexExtra(extras, extra) == -1 {
+			remaining = append(remaining, extra)
 		}
 	}
+	if len(remaining) == len(req.Extras) {
+		return nil
+	}
 
-	return fmt.Errorf("无效的版本约束格式: %s", version)
+	if err := doc.doc.SetExtras(packageName, remaining); err != nil {
+		return err
+	}
+	e.sync(doc)
+	return nil
+}
+
+// indexExtra 返回extra在extras中的位置（按PEP 503规范化后比较），不存在时返回-1
+func indexExtra(extras []string, extra string) int {
+	key := models.NormalizeName(strings.TrimSpace(extra))
+	for i, item := range extras {
+		if models.NormalizeName(strings.TrimSpace(item)) == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// UpdatePackageHashes 替换包的全部哈希（最小化diff），hashes为空时移除所有哈希
+//
+// 每个哈希的格式为"算法:摘要"，如"sha256:ab12..."。新的哈希沿用原有的排列方式，
+// pip-compile生成的多行格式（每个哈希单独一个"\"续行）保持不变，见Document.SetHashes。
+func (e *PositionAwareEditor) UpdatePackageHashes(doc *PositionAwareDocument, packageName string, hashes []string) error {
+	if err := doc.doc.SetHashes(packageName, hashes); err != nil {
+		return err
+	}
+	e.sync(doc)
+	return nil
+}
+
+// UpdatePackageComment 设置包的行内注释（最小化diff），comment为空时移除注释
+//
+// comment不包括"#"。已有注释时只替换注释内容，没有时追加到行尾，见Document.SetComment。
+func (e *PositionAwareEditor) UpdatePackageComment(doc *PositionAwareDocument, packageName, comment string) error {
+	if err := doc.doc.SetComment(packageName, strings.TrimSpace(comment)); err != nil {
+		return err
+	}
+	e.sync(doc)
+	return nil
+}
+
+// Undo 撤销对文档的最近一次修改，见Document.Undo
+func (e *PositionAwareEditor) Undo(doc *PositionAwareDocument) error {
+	defer e.sync(doc)
+	return doc.doc.Undo()
+}
+
+// Redo 重做最近一次撤销的修改，见Document.Redo
+func (e *PositionAwareEditor) Redo(doc *PositionAwareDocument) error {
+	defer e.sync(doc)
+	return doc.doc.Redo()
+}
+
+// SerializeToString 将文档序列化为字符串（最小化diff）
+func (e *PositionAwareEditor) SerializeToString(doc *PositionAwareDocument) string {
+	return doc.doc.String()
+}
+
+// SerializeToBytes 将文档按原始编码和BOM状态序列化为字节（最小化diff）
+func (e *PositionAwareEditor) SerializeToBytes(doc *PositionAwareDocument) ([]byte, error) {
+	return doc.doc.Bytes()
+}
+
+// validateVersionSpecifier 验证版本约束格式
+func (e *PositionAwareEditor) validateVersionSpecifier(version string) error {
+	return checkSpecifierOperator(version)
 }
 
 // GetPackageInfo 获取指定包的信息
 func (e *PositionAwareEditor) GetPackageInfo(doc *PositionAwareDocument, packageName string) (*models.Requirement, error) {
-	for _, req := range doc.Requirements {
-		if req.Name == packageName && !req.IsComment && !req.IsEmpty {
-			return req, nil
-		}
+	if req := doc.doc.Find(packageName); req != nil {
+		return req, nil
 	}
 	return nil, fmt.Errorf("在requirements中未找到包: %s", packageName)
 }
@@ -238,7 +436,7 @@ func (e *PositionAwareEditor) GetPackageInfo(doc *PositionAwareDocument, package
 func (e *PositionAwareEditor) ListPackages(doc *PositionAwareDocument) []*models.Requirement {
 	var packages []*models.Requirement
 	for _, req := range doc.Requirements {
-		if !req.IsComment && !req.IsEmpty && !req.IsFileRef && !req.IsConstraint && req.Name != "" {
+		if req.IsInstallable() && req.Name != "" {
 			packages = append(packages, req)
 		}
 	}
@@ -246,19 +444,22 @@ func (e *PositionAwareEditor) ListPackages(doc *PositionAwareDocument) []*models
 }
 
 // BatchUpdateVersions 批量更新版本（最小化diff）
+//
+// 所有更新按包名排序后在一个事务中执行（见Transaction），结果与map的遍历顺序无关。
+// 任一更新失败时撤销全部修改并返回汇总的错误，文档保持不变。
 func (e *PositionAwareEditor) BatchUpdateVersions(doc *PositionAwareDocument, updates map[string]string) error {
-	var errors []string
-
-	for packageName, newVersion := range updates {
-		err := e.UpdatePackageVersion(doc, packageName, newVersion)
-		if err != nil {
-			errors = append(errors, fmt.Sprintf("%s: %v", packageName, err))
-		}
+	names := make([]string, 0, len(updates))
+	for name := range updates {
+		names = append(names, name)
 	}
+	sort.Strings(names)
 
-	if len(errors) > 0 {
-		return fmt.Errorf("批量更新失败: %s", strings.Join(errors, "; "))
+	tx := e.Begin(doc)
+	for _, name := range names {
+		tx.UpdateVersion(name, updates[name])
+	}
+	if _, err := tx.Commit(); err != nil {
+		return fmt.Errorf("批量更新失败: %w", err)
 	}
-
 	return nil
 }
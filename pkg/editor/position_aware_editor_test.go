@@ -216,6 +216,60 @@ requests>=2.25.0,<3.0.0`
 	}
 }
 
+func TestPositionAwareEditor_PositionsFollowEdits(t *testing.T) {
+	editor := NewPositionAwareEditor()
+
+	content := "# deps\n" +
+		"six==1.16.0\n" +
+		"six==1.16.0\n" +
+		"django==3.2.0 \\\n" +
+		"    --hash=sha256:aaaa  # web\n"
+
+	doc, err := editor.ParseRequirementsFile(content)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+
+	// 相同的两行分别对应各自的行号
+	if doc.Requirements[1].PositionInfo.LineNumber != 2 || doc.Requirements[2].PositionInfo.LineNumber != 3 {
+		t.Errorf("相同行的行号不正确: %d, %d",
+			doc.Requirements[1].PositionInfo.LineNumber, doc.Requirements[2].PositionInfo.LineNumber)
+	}
+
+	// 在前面插入和修改行后，后面的位置随之平移
+	if err := editor.UpdatePackageVersion(doc, "six", ">=1.15"); err != nil {
+		t.Fatalf("更新版本失败: %v", err)
+	}
+	if _, err := doc.Document().Insert(1, "attrs==23.1.0"); err != nil {
+		t.Fatalf("插入失败: %v", err)
+	}
+	editor.sync(doc)
+
+	text := editor.SerializeToString(doc)
+	django, _ := editor.GetPackageInfo(doc, "django")
+	info := django.PositionInfo
+	if info.LineNumber != 5 || text[info.Offset:info.Offset+len("django")] != "django" {
+		t.Errorf("django的位置不正确: %+v", info)
+	}
+	if len(info.Hashes) != 1 || text[info.Hashes[0].Start:info.Hashes[0].End] != "--hash=sha256:aaaa" ||
+		info.Hashes[0].Line != 6 {
+		t.Errorf("哈希的位置不正确: %+v", info.Hashes)
+	}
+	if info.Comment == nil || text[info.Comment.Start:info.Comment.End] != "# web" {
+		t.Errorf("注释的位置不正确: %+v", info.Comment)
+	}
+
+	for i, req := range doc.Requirements {
+		if req.PositionInfo == nil || req.PositionInfo.LineNumber != doc.Document().LineNumber(req) {
+			t.Errorf("requirements[%d]的行号与文档不一致: %+v", i, req.PositionInfo)
+		}
+	}
+	six := doc.Requirements[2].PositionInfo
+	if text[six.Specifier.Start:six.Specifier.End] != ">=1.15" {
+		t.Errorf("更新后的版本位置不正确: %+v", six.Specifier)
+	}
+}
+
 func TestPositionAwareEditor_ErrorHandling(t *testing.T) {
 	editor := NewPositionAwareEditor()
 
@@ -660,3 +714,381 @@ requests>=2.25.0`
 		}
 	})
 }
+
+func TestPositionAwareEditor_AddRemovePackages(t *testing.T) {
+	editor := NewPositionAwareEditor()
+
+	originalContent := "# Web frameworks\r\n" +
+		"django==3.2.0  # admin\r\n" +
+		"flask==2.0.1\r\n" +
+		"\r\n" +
+		"# Testing\r\n" +
+		"pytest==7.0.0 \\\r\n" +
+		"    --hash=sha256:aaaa\r\n" +
+		"    # via -r requirements.in\r\n" +
+		"tox==4.0.0\r\n"
+
+	doc, err := editor.ParseRequirementsFile(originalContent)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+
+	if err := editor.AddPackage(doc, "fastapi", "==0.95.0", []string{"all"}, "", Alphabetical("Web frameworks")); err != nil {
+		t.Fatalf("添加fastapi失败: %v", err)
+	}
+	if err := editor.AddPackage(doc, "pytest-cov", "", nil, "python_version >= '3.8'", AfterPackage("pytest")); err != nil {
+		t.Fatalf("添加pytest-cov失败: %v", err)
+	}
+	if err := editor.RemovePackage(doc, "tox"); err != nil {
+		t.Fatalf("移除tox失败: %v", err)
+	}
+	if err := editor.RemovePackage(doc, "pytest"); err != nil {
+		t.Fatalf("移除pytest失败: %v", err)
+	}
+
+	expected := "# Web frameworks\r\n" +
+		"django==3.2.0  # admin\r\n" +
+		"fastapi[all]==0.95.0\r\n" +
+		"flask==2.0.1\r\n" +
+		"\r\n" +
+		"# Testing\r\n" +
+		"pytest-cov ; python_version >= '3.8'\r\n"
+
+	if result := editor.SerializeToString(doc); result != expected {
+		t.Errorf("结果:\n%q\n期望:\n%q", result, expected)
+	}
+
+	// 位置信息应与编辑后的文本一致
+	flask, err := editor.GetPackageInfo(doc, "flask")
+	if err != nil {
+		t.Fatalf("获取flask信息失败: %v", err)
+	}
+	if flask.PositionInfo == nil || flask.PositionInfo.LineNumber != 4 {
+		t.Errorf("flask的位置信息不正确: %+v", flask.PositionInfo)
+	}
+
+	if err := editor.AddPackage(doc, "Flask", "", nil, "", AtEnd()); err == nil {
+		t.Errorf("添加已存在的包应返回错误")
+	}
+	if err := editor.AddPackage(doc, "requests", "2.0", nil, "", AtEnd()); err == nil {
+		t.Errorf("无效的版本约束应返回错误")
+	}
+	if err := editor.RemovePackage(doc, "numpy"); err == nil {
+		t.Errorf("移除不存在的包应返回错误")
+	}
+}
+
+func TestPositionAwareEditor_PinUnpinned(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		pkg      string
+		expected string
+	}{
+		{"Bare name", "flask\n", "flask", "flask==2.0.1\n"},
+		{"With extras", "flask[async,dotenv]  # web\n", "flask", "flask[async,dotenv]==2.0.1  # web\n"},
+		{"Before markers", "flask; python_version >= '3.8'\n", "flask", "flask==2.0.1; python_version >= '3.8'\n"},
+		{"Before options", "flask --global-option=x\n", "flask", "flask==2.0.1 --global-option=x\n"},
+		{"Before hashes", "flask \\\n    --hash=sha256:aaaa\n", "flask", "flask==2.0.1 \\\n    --hash=sha256:aaaa\n"},
+		{"Before comment", "flask  # web\n", "flask", "flask==2.0.1  # web\n"},
+	}
+
+	// 带哈希的行需要新版本的哈希，这里使用与原来相同的哈希，使输出只体现版本的插入位置
+	editor := NewPositionAwareEditor()
+	editor.HashPolicy = HashPolicyProvider
+	editor.HashProvider = mapHashProvider{"flask==2.0.1": {"sha256:aaaa"}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := editor.ParseRequirementsFile(tt.content)
+			if err != nil {
+				t.Fatalf("解析失败: %v", err)
+			}
+			if err := editor.UpdatePackageVersion(doc, tt.pkg, "==2.0.1"); err != nil {
+				t.Fatalf("更新版本失败: %v", err)
+			}
+			if result := editor.SerializeToString(doc); result != tt.expected {
+				t.Errorf("结果 = %q, 期望 %q", result, tt.expected)
+			}
+
+			// 位置信息应指向插入的版本约束
+			req, _ := editor.GetPackageInfo(doc, tt.pkg)
+			pos := req.PositionInfo
+			if pos == nil || tt.expected[pos.VersionStartColumn:pos.VersionEndColumn] != "==2.0.1" {
+				t.Errorf("版本位置不正确: %+v", pos)
+			}
+		})
+	}
+}
+
+func TestPositionAwareEditor_PinFromFreeze(t *testing.T) {
+	editor := NewPositionAwareEditor()
+
+	content := `# Web
+Flask[async]  # web framework
+django>=3.2,<4.0
+requests==2.28.0
+pywin32 ; sys_platform == 'win32'
+pywin32>=300 ; sys_platform == 'cygwin'
+./libs/core
+numpy --global-option=x
+`
+	freeze := `# generated by pip freeze
+flask==2.0.1
+Django==3.2.18
+requests==2.31.0
+pywin32==306
+-e git+https://github.com/user/core.git#egg=core
+numpy==1.24.2
+six==1.16.0
+`
+
+	doc, err := editor.ParseRequirementsFile(content)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	pinned, err := editor.PinFromFreeze(doc, freeze)
+	if err != nil {
+		t.Fatalf("PinFromFreeze失败: %v", err)
+	}
+
+	expected := `# Web
+Flask[async]==2.0.1  # web framework
+django==3.2.18
+requests==2.28.0
+pywin32==306 ; sys_platform == 'win32'
+pywin32==306 ; sys_platform == 'cygwin'
+./libs/core
+numpy==1.24.2 --global-option=x
+`
+	if result := editor.SerializeToString(doc); result != expected {
+		t.Errorf("结果:\n%s\n期望:\n%s", result, expected)
+	}
+
+	want := []string{"Flask", "django", "pywin32", "pywin32", "numpy"}
+	if strings.Join(pinned, ",") != strings.Join(want, ",") {
+		t.Errorf("pinned = %v, 期望 %v", pinned, want)
+	}
+}
+
+func TestPositionAwareEditor_PinFromFreezeAtomic(t *testing.T) {
+	editor := NewPositionAwareEditor()
+
+	content := `flask>=2.0
+django>=3.2 \
+    --hash=sha256:aaaa
+pywin32 ; sys_platform == 'win32'
+pywin32 ; sys_platform == 'cygwin'
+`
+	freeze := "flask==2.0.1\ndjango==3.2.18\npywin32==306\n"
+
+	// 默认的HashPolicyFail使django固定失败，其他包的修改同样回滚
+	doc, err := editor.ParseRequirementsFile(content)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	pinned, err := editor.PinFromFreeze(doc, freeze)
+	if err == nil || !strings.Contains(err.Error(), "django") {
+		t.Fatalf("期望django固定失败，实际err = %v", err)
+	}
+	if pinned != nil {
+		t.Errorf("失败时pinned = %v, 期望nil", pinned)
+	}
+	if result := editor.SerializeToString(doc); result != content {
+		t.Errorf("失败后文档被修改:\n%s", result)
+	}
+	if doc.Requirements[0].Version != ">=2.0" {
+		t.Errorf("失败后flask的版本 = %q", doc.Requirements[0].Version)
+	}
+	if len(doc.Document().Journal()) != 0 {
+		t.Errorf("失败时不应记录历史: %+v", doc.Document().Journal())
+	}
+
+	// 成功时所有修改记录为一项，一次撤销即可恢复
+	editor.HashPolicy = HashPolicyDrop
+	if _, err := editor.PinFromFreeze(doc, freeze); err != nil {
+		t.Fatalf("PinFromFreeze失败: %v", err)
+	}
+	journal := doc.Document().Journal()
+	if len(journal) != 1 || journal[0].Operation != OpTransaction {
+		t.Errorf("历史记录 = %+v, 期望一项事务", journal)
+	}
+	if err := editor.Undo(doc); err != nil {
+		t.Fatalf("Undo失败: %v", err)
+	}
+	if result := editor.SerializeToString(doc); result != content {
+		t.Errorf("撤销后:\n%s\n期望:\n%s", result, content)
+	}
+}
+
+func TestPositionAwareEditor_ComponentEdits(t *testing.T) {
+	content := "# Web\n" +
+		"Django[bcrypt, argon2]>=4.2  # web\n" +
+		"pywin32==306 ; platform_system=='Windows'\n" +
+		"requests==2.31.0 \\\n" +
+		"    --hash=sha256:aaaa \\\n" +
+		"    --hash=sha256:bbbb\n" +
+		"flask==2.3.2\n"
+
+	tests := []struct {
+		name     string
+		edit     func(e *PositionAwareEditor, doc *PositionAwareDocument) error
+		expected string
+	}{
+		{
+			name: "Set markers",
+			edit: func(e *PositionAwareEditor, doc *PositionAwareDocument) error {
+				return e.UpdatePackageMarkers(doc, "flask", "python_version >= '3.8'")
+			},
+			expected: strings.Replace(content, "flask==2.3.2\n", "flask==2.3.2 ; python_version >= '3.8'\n", 1),
+		},
+		{
+			name: "Replace markers keeps spacing",
+			edit: func(e *PositionAwareEditor, doc *PositionAwareDocument) error {
+				return e.UpdatePackageMarkers(doc, "pywin32", "sys_platform == 'win32'")
+			},
+			expected: strings.Replace(content, "platform_system=='Windows'", "sys_platform == 'win32'", 1),
+		},
+		{
+			name: "Remove markers",
+			edit: func(e *PositionAwareEditor, doc *PositionAwareDocument) error {
+				return e.UpdatePackageMarkers(doc, "pywin32", "")
+			},
+			expected: strings.Replace(content, "pywin32==306 ; platform_system=='Windows'", "pywin32==306", 1),
+		},
+		{
+			name: "Add extras",
+			edit: func(e *PositionAwareEditor, doc *PositionAwareDocument) error {
+				return e.AddPackageExtras(doc, "django", "Argon2", "scrypt")
+			},
+			expected: strings.Replace(content, "[bcrypt, argon2]", "[bcrypt, argon2, scrypt]", 1),
+		},
+		{
+			name: "Add first extra",
+			edit: func(e *PositionAwareEditor, doc *PositionAwareDocument) error {
+				return e.AddPackageExtras(doc, "requests", "socks")
+			},
+			expected: strings.Replace(content, "requests==2.31.0", "requests[socks]==2.31.0", 1),
+		},
+		{
+			name: "Remove extras",
+			edit: func(e *PositionAwareEditor, doc *PositionAwareDocument) error {
+				return e.RemovePackageExtras(doc, "django", "bcrypt", "missing")
+			},
+			expected: strings.Replace(content, "[bcrypt, argon2]", "[argon2]", 1),
+		},
+		{
+			name: "Remove all extras",
+			edit: func(e *PositionAwareEditor, doc *PositionAwareDocument) error {
+				return e.RemovePackageExtras(doc, "django", "bcrypt", "argon2")
+			},
+			expected: strings.Replace(content, "Django[bcrypt, argon2]", "Django", 1),
+		},
+		{
+			name: "Replace multiline hashes",
+			edit: func(e *PositionAwareEditor, doc *PositionAwareDocument) error {
+				return e.UpdatePackageHashes(doc, "requests", []string{"sha256:cccc", "sha256:dddd", "sha256:eeee"})
+			},
+			expected: strings.Replace(content,
+				"    --hash=sha256:aaaa \\\n    --hash=sha256:bbbb\n",
+				"    --hash=sha256:cccc \\\n    --hash=sha256:dddd \\\n    --hash=sha256:eeee\n", 1),
+		},
+		{
+			name: "New hashes use the multiline layout",
+			edit: func(e *PositionAwareEditor, doc *PositionAwareDocument) error {
+				return e.UpdatePackageHashes(doc, "flask", []string{"sha256:ffff"})
+			},
+			expected: strings.Replace(content, "flask==2.3.2\n", "flask==2.3.2 \\\n    --hash=sha256:ffff\n", 1),
+		},
+		{
+			name: "Remove hashes",
+			edit: func(e *PositionAwareEditor, doc *PositionAwareDocument) error {
+				return e.UpdatePackageHashes(doc, "requests", nil)
+			},
+			expected: strings.Replace(content,
+				"requests==2.31.0 \\\n    --hash=sha256:aaaa \\\n    --hash=sha256:bbbb\n", "requests==2.31.0\n", 1),
+		},
+		{
+			name: "Replace comment",
+			edit: func(e *PositionAwareEditor, doc *PositionAwareDocument) error {
+				return e.UpdatePackageComment(doc, "django", "web framework")
+			},
+			expected: strings.Replace(content, "# web\n", "# web framework\n", 1),
+		},
+		{
+			name: "Add comment",
+			edit: func(e *PositionAwareEditor, doc *PositionAwareDocument) error {
+				return e.UpdatePackageComment(doc, "flask", "pinned for CVE-2023-30861")
+			},
+			expected: strings.Replace(content, "flask==2.3.2\n", "flask==2.3.2  # pinned for CVE-2023-30861\n", 1),
+		},
+		{
+			name: "Clear comment",
+			edit: func(e *PositionAwareEditor, doc *PositionAwareDocument) error {
+				return e.UpdatePackageComment(doc, "django", "")
+			},
+			expected: strings.Replace(content, ">=4.2  # web", ">=4.2", 1),
+		},
+	}
+
+	editor := NewPositionAwareEditor()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := editor.ParseRequirementsFile(content)
+			if err != nil {
+				t.Fatalf("解析失败: %v", err)
+			}
+			if err := tt.edit(editor, doc); err != nil {
+				t.Fatalf("编辑失败: %v", err)
+			}
+
+			result := editor.SerializeToString(doc)
+			if result != tt.expected {
+				t.Errorf("结果:\n%s\n期望:\n%s", result, tt.expected)
+			}
+
+			// 位置信息应与编辑后的文本一致
+			for _, req := range editor.ListPackages(doc) {
+				info := req.PositionInfo
+				if info == nil || info.Name == nil || result[info.Name.Start:info.Name.End] != req.Name {
+					t.Errorf("%s的包名位置不正确: %+v", req.Name, info)
+					continue
+				}
+				if req.Markers != "" && (info.Markers == nil || result[info.Markers.Start:info.Markers.End] != req.Markers) {
+					t.Errorf("%s的环境标记位置不正确: %+v", req.Name, info.Markers)
+				}
+				if len(info.Hashes) != len(req.Hashes) {
+					t.Errorf("%s的哈希位置数量不正确: %+v", req.Name, info.Hashes)
+				}
+			}
+		})
+	}
+}
+
+func TestPositionAwareEditor_ComponentEditErrors(t *testing.T) {
+	editor := NewPositionAwareEditor()
+	content := "flask==2.3.2\n-r base.txt\n"
+	doc, _ := editor.ParseRequirementsFile(content)
+
+	tests := []struct {
+		name string
+		edit func() error
+	}{
+		{"Markers on missing package", func() error { return editor.UpdatePackageMarkers(doc, "django", "os_name == 'nt'") }},
+		{"Extras on missing package", func() error { return editor.AddPackageExtras(doc, "django", "argon2") }},
+		{"Remove extras on missing package", func() error { return editor.RemovePackageExtras(doc, "django", "argon2") }},
+		{"Invalid hash", func() error { return editor.UpdatePackageHashes(doc, "flask", []string{"md5"}) }},
+		{"Multiline comment", func() error { return editor.UpdatePackageComment(doc, "flask", "a\nb") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.edit(); err == nil {
+				t.Errorf("期望返回错误")
+			}
+		})
+	}
+
+	if result := editor.SerializeToString(doc); result != content {
+		t.Errorf("失败的编辑不应修改文档: %q", result)
+	}
+}
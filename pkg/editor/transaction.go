@@ -0,0 +1,295 @@
+package editor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/scagogogo/python-requirements-parser/pkg/cst"
+	"github.com/scagogogo/python-requirements-parser/pkg/models"
+)
+
+// OperationKind 事务中编辑操作的类型
+type OperationKind string
+
+const (
+	// OpUpdateVersion 修改版本约束
+	OpUpdateVersion OperationKind = "update-version"
+
+	// OpAdd 添加一行
+	OpAdd OperationKind = "add"
+
+	// OpRemove 移除一个requirement
+	OpRemove OperationKind = "remove"
+
+	// OpSetMarkers 设置环境标记
+	OpSetMarkers OperationKind = "set-markers"
+)
+
+// Operation 事务中的一个编辑操作
+type Operation struct {
+	// Kind 操作类型
+	Kind OperationKind
+
+	// Package 操作的包名、本地路径或URL；OpAdd时为新行中的包名
+	Package string
+
+	// Value 操作的参数：OpUpdateVersion时为版本约束，OpAdd时为新行的文本，
+	// OpSetMarkers时为环境标记
+	Value string
+
+	// Placement OpAdd时新行的插入位置
+	Placement Placement
+
+	// target 操作的requirement，为nil时按Package查找第一个匹配的行
+	// 用于同一个包出现多次（如带不同环境标记）时修改指定的行
+	target *models.Requirement
+}
+
+// String 返回操作的简短描述，如"update-version flask ==2.0.1"、"add gunicorn==20.1.0"
+func (op Operation) String() string {
+	if op.Kind == OpAdd {
+		return fmt.Sprintf("%s %s", op.Kind, op.Value)
+	}
+	return strings.TrimSpace(fmt.Sprintf("%s %s %s", op.Kind, op.Package, op.Value))
+}
+
+// OperationResult 一个操作的执行结果
+type OperationResult struct {
+	// Operation 执行的操作
+	Operation Operation
+
+	// Err 操作失败的原因，成功时为nil
+	Err error
+}
+
+// Transaction 一组原子执行的编辑操作
+//
+// 操作先暂存，Commit时按添加的顺序依次执行：全部成功时保留所有修改，
+// 任一操作失败时撤销全部修改，文档恢复到事务开始前的状态。
+// 即使前面的操作已经失败，后续操作仍会执行，以便一次报告所有问题。
+// 回滚后文档中原有的*models.Requirement指针仍然有效。
+//
+// 示例:
+//
+//	tx := doc.Begin()
+//	tx.UpdateVersion("flask", "==2.3.2")
+//	tx.Add("gunicorn==21.2.0", editor.AfterPackage("flask"))
+//	tx.Remove("six")
+//	results, err := tx.Commit()
+//	if err != nil {
+//	    // 文档没有被修改，results给出每个操作的结果
+//	}
+type Transaction struct {
+	doc *Document
+	ops []Operation
+
+	// hashPolicy、hashProvider 修改带哈希的包的版本时的处理方式
+	hashPolicy   HashPolicy
+	hashProvider HashProvider
+
+	// removeWithComments 移除时是否同时移除附属的注释行
+	removeWithComments bool
+
+	// done 事务结束（提交或回滚）后调用，用于同步编辑器的文档
+	done func()
+}
+
+// Begin 开始一个事务
+//
+// 修改带哈希的包的版本时使用HashPolicyFail，见HashPolicy。
+func (d *Document) Begin() *Transaction {
+	return &Transaction{doc: d}
+}
+
+// Begin 开始一个事务，操作的行为与PositionAwareEditor的对应方法相同
+//
+// 版本修改使用编辑器的HashPolicy，移除时同时移除附属的注释行。
+func (e *PositionAwareEditor) Begin(doc *PositionAwareDocument) *Transaction {
+	return &Transaction{
+		doc:                doc.doc,
+		hashPolicy:         e.HashPolicy,
+		hashProvider:       e.HashProvider,
+		removeWithComments: true,
+		done:               func() { e.sync(doc) },
+	}
+}
+
+// Begin 开始一个事务，操作的行为与VersionEditorV2的对应方法相同
+func (v *VersionEditorV2) Begin(doc *RequirementsDocument) *Transaction {
+	return &Transaction{
+		doc:          doc.doc,
+		hashPolicy:   v.HashPolicy,
+		hashProvider: v.HashProvider,
+		done:         doc.sync,
+	}
+}
+
+// UpdateVersion 暂存一个修改版本约束的操作
+func (tx *Transaction) UpdateVersion(name, version string) *Transaction {
+	return tx.stage(Operation{Kind: OpUpdateVersion, Package: name, Value: version})
+}
+
+// updateRequirementVersion 暂存一个修改指定requirement版本约束的操作
+func (tx *Transaction) updateRequirementVersion(req *models.Requirement, version string) *Transaction {
+	return tx.stage(Operation{Kind: OpUpdateVersion, Package: req.Name, Value: version, target: req})
+}
+
+// Add 暂存一个添加行的操作
+//
+// text为一个逻辑行的内容，如"fastapi==0.95.0 ; python_version >= '3.8'"。
+// 文档中已存在同名的包时操作失败。
+func (tx *Transaction) Add(text string, placement Placement) *Transaction {
+	op := Operation{Kind: OpAdd, Value: text, Placement: placement}
+	if reqs, err := tx.doc.parser.ParseString(text); err == nil && len(reqs) == 1 {
+		op.Package = reqs[0].Key()
+	}
+	return tx.stage(op)
+}
+
+// Remove 暂存一个移除requirement的操作
+func (tx *Transaction) Remove(name string) *Transaction {
+	return tx.stage(Operation{Kind: OpRemove, Package: name})
+}
+
+// SetMarkers 暂存一个设置环境标记的操作，markers为空时移除环境标记
+func (tx *Transaction) SetMarkers(name, markers string) *Transaction {
+	return tx.stage(Operation{Kind: OpSetMarkers, Package: name, Value: markers})
+}
+
+// stage 暂存一个操作
+func (tx *Transaction) stage(op Operation) *Transaction {
+	tx.ops = append(tx.ops, op)
+	return tx
+}
+
+// Operations 返回已暂存的操作
+func (tx *Transaction) Operations() []Operation {
+	return append([]Operation(nil), tx.ops...)
+}
+
+// Validate 检查所有操作能否成功执行，文档保持不变
+//
+// 返回:
+//   - []OperationResult: 每个操作的结果，与暂存顺序一致
+//   - error: 任一操作失败时返回汇总的错误
+func (tx *Transaction) Validate() ([]OperationResult, error) {
+	return tx.run(false)
+}
+
+// Commit 执行所有操作，全部成功时保留修改，否则回滚
+//
+// 返回:
+//   - []OperationResult: 每个操作的结果，与暂存顺序一致
+//   - error: 任一操作失败时返回汇总的错误，此时文档没有被修改
+func (tx *Transaction) Commit() ([]OperationResult, error) {
+	return tx.run(true)
+}
+
+// run 依次执行所有操作，keep为false或有操作失败时回滚
+func (tx *Transaction) run(keep bool) ([]OperationResult, error) {
+	if tx.done != nil {
+		defer tx.done()
+	}
+	var summary []string
+	for _, op := range tx.ops {
+		summary = append(summary, op.String())
+	}
+	defer tx.doc.record(OpTransaction, "", strings.Join(summary, "; "))()
+	snapshot := tx.doc.snapshot()
+
+	results := make([]OperationResult, len(tx.ops))
+	var failures []string
+	for i, op := range tx.ops {
+		results[i] = OperationResult{Operation: op, Err: tx.apply(op)}
+		if results[i].Err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", op, results[i].Err))
+		}
+	}
+
+	if len(failures) > 0 {
+		tx.doc.restore(snapshot)
+		return results, fmt.Errorf("事务已回滚: %s", strings.Join(failures, "; "))
+	}
+	if !keep {
+		tx.doc.restore(snapshot)
+	}
+	return results, nil
+}
+
+// apply 执行一个操作
+func (tx *Transaction) apply(op Operation) error {
+	d := tx.doc
+	switch op.Kind {
+	case OpUpdateVersion:
+		if err := checkSpecifierOperator(op.Value); err != nil {
+			return err
+		}
+		i, err := tx.locate(op)
+		if err != nil {
+			return err
+		}
+		return d.setVersionHashed(i, op.Value, tx.hashPolicy, tx.hashProvider)
+
+	case OpAdd:
+		if op.Package != "" && d.Index(op.Package) != -1 {
+			return fmt.Errorf("包 %s 已存在", op.Package)
+		}
+		_, err := d.AddAt(op.Value, op.Placement)
+		return err
+
+	case OpRemove:
+		if tx.removeWithComments {
+			return d.RemoveWithComments(op.Package)
+		}
+		return d.Remove(op.Package)
+
+	case OpSetMarkers:
+		return d.SetMarkers(op.Package, op.Value)
+	}
+	return fmt.Errorf("未知的操作类型: %s", op.Kind)
+}
+
+// locate 返回操作的requirement在文档中的位置
+func (tx *Transaction) locate(op Operation) (int, error) {
+	if op.target == nil {
+		return tx.doc.lookup(op.Package)
+	}
+	for i, req := range tx.doc.reqs {
+		if req == op.target {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("requirement已不在文档中: %s", op.Package)
+}
+
+// documentState 文档在某一时刻的状态，用于回滚
+type documentState struct {
+	text   string
+	reqs   []*models.Requirement
+	values []*models.Requirement
+}
+
+// snapshot 保存文档的当前状态
+func (d *Document) snapshot() documentState {
+	state := documentState{
+		text:   d.file.String(),
+		reqs:   append([]*models.Requirement(nil), d.reqs...),
+		values: make([]*models.Requirement, len(d.reqs)),
+	}
+	for i, req := range d.reqs {
+		state.values[i] = req.Clone()
+	}
+	return state
+}
+
+// restore 恢复到snapshot保存的状态
+//
+// 语法树从保存的文本重新构建，requirement在原来的指针上恢复内容，
+// 因此调用方持有的指针不会失效。
+func (d *Document) restore(state documentState) {
+	d.file = cst.Parse(state.text)
+	d.reqs = append(d.reqs[:0:0], state.reqs...)
+	for i, req := range d.reqs {
+		*req = *state.values[i]
+	}
+}
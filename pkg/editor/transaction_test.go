@@ -0,0 +1,215 @@
+package editor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTransaction_Commit(t *testing.T) {
+	content := "# Web\n" +
+		"flask==1.0.0  # web\n" +
+		"six==1.15.0\n" +
+		"\n" +
+		"# Testing\n" +
+		"pytest==6.0.0\n"
+
+	doc, err := ParseDocument(content)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+
+	results, err := doc.Begin().
+		UpdateVersion("flask", "==2.0.1").
+		Add("gunicorn==20.1.0", AfterPackage("flask")).
+		UpdateVersion("gunicorn", "==21.2.0").
+		Remove("six").
+		SetMarkers("pytest", "python_version >= '3.8'").
+		Commit()
+	if err != nil {
+		t.Fatalf("Commit失败: %v", err)
+	}
+
+	expected := "# Web\n" +
+		"flask==2.0.1  # web\n" +
+		"gunicorn==21.2.0\n" +
+		"\n" +
+		"# Testing\n" +
+		"pytest==6.0.0 ; python_version >= '3.8'\n"
+	if got := doc.String(); got != expected {
+		t.Errorf("结果:\n%s\n期望:\n%s", got, expected)
+	}
+
+	kinds := []OperationKind{OpUpdateVersion, OpAdd, OpUpdateVersion, OpRemove, OpSetMarkers}
+	if len(results) != len(kinds) {
+		t.Fatalf("结果数量 = %d", len(results))
+	}
+	for i, result := range results {
+		if result.Err != nil || result.Operation.Kind != kinds[i] {
+			t.Errorf("results[%d] = %+v", i, result)
+		}
+	}
+	if results[1].Operation.Package != "gunicorn" {
+		t.Errorf("添加操作的包名 = %q", results[1].Operation.Package)
+	}
+}
+
+func TestTransaction_Rollback(t *testing.T) {
+	content := "flask==1.0.0 \\\n    --hash=sha256:aaaa\nsix==1.15.0\nrequests==2.0.0"
+
+	doc, _ := ParseDocument(content)
+	flask := doc.Find("flask")
+	six := doc.Find("six")
+
+	results, err := doc.Begin().
+		Remove("six").
+		UpdateVersion("requests", "==2.31.0").
+		Add("requests==2.0.0", AtEnd()).
+		UpdateVersion("missing", "==1.0").
+		UpdateVersion("requests", "2.31").
+		SetMarkers("flask", "python_version >= '3.8'").
+		Commit()
+	if err == nil {
+		t.Fatalf("Commit应返回错误")
+	}
+
+	// 每个操作都有结果，失败的操作给出原因
+	failed := []bool{false, false, true, true, true, false}
+	for i, result := range results {
+		if (result.Err != nil) != failed[i] {
+			t.Errorf("results[%d] = %+v", i, result)
+		}
+	}
+	for _, want := range []string{"add requests==2.0.0", "missing", "无效的版本约束格式"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("错误 %q 应包含 %q", err, want)
+		}
+	}
+
+	// 文档恢复原状，原有的指针仍然有效
+	if got := doc.String(); got != content {
+		t.Errorf("回滚后的文档:\n%s", got)
+	}
+	if doc.Find("flask") != flask || doc.Find("six") != six {
+		t.Errorf("回滚后requirement指针发生变化")
+	}
+	if flask.Markers != "" || len(flask.Hashes) != 1 || six.Version != "==1.15.0" {
+		t.Errorf("回滚后requirement的内容没有恢复: %+v, %+v", flask, six)
+	}
+
+	// 回滚后文档仍然可以编辑
+	if err := doc.SetVersion("six", "==1.16.0"); err != nil || !strings.Contains(doc.String(), "six==1.16.0\n") {
+		t.Errorf("回滚后编辑失败: %v\n%s", err, doc.String())
+	}
+}
+
+func TestTransaction_Validate(t *testing.T) {
+	content := "flask==1.0.0\n"
+	doc, _ := ParseDocument(content)
+
+	tx := doc.Begin().UpdateVersion("flask", "==2.0.1").Add("flask>=2", AtEnd())
+	results, err := tx.Validate()
+	if err == nil || results[0].Err != nil || results[1].Err == nil {
+		t.Errorf("Validate = %+v, %v", results, err)
+	}
+	if doc.String() != content {
+		t.Errorf("Validate不应修改文档: %s", doc.String())
+	}
+
+	tx = doc.Begin().UpdateVersion("flask", "==2.0.1")
+	if _, err := tx.Validate(); err != nil {
+		t.Errorf("Validate失败: %v", err)
+	}
+	if doc.String() != content || len(tx.Operations()) != 1 {
+		t.Errorf("Validate不应修改文档: %s", doc.String())
+	}
+	if _, err := tx.Commit(); err != nil || doc.String() != "flask==2.0.1\n" {
+		t.Errorf("Validate之后Commit失败: %v, %s", err, doc.String())
+	}
+}
+
+func TestTransaction_Editors(t *testing.T) {
+	content := "# HTTP\n" +
+		"requests==2.28.0\n" +
+		"    # via -r requirements.in\n" +
+		"flask==2.0.0 --hash=sha256:aaaa\n"
+
+	t.Run("PositionAwareEditor", func(t *testing.T) {
+		editor := NewPositionAwareEditor()
+		doc, _ := editor.ParseRequirementsFile(content)
+
+		// 默认的哈希策略使整个事务失败
+		if _, err := editor.Begin(doc).Remove("requests").UpdateVersion("flask", "==2.3.2").Commit(); err == nil {
+			t.Fatalf("Commit应返回错误")
+		}
+		if editor.SerializeToString(doc) != content || len(doc.Requirements) != 4 {
+			t.Errorf("回滚后的文档:\n%s", editor.SerializeToString(doc))
+		}
+
+		editor.HashPolicy = HashPolicyDrop
+		if _, err := editor.Begin(doc).Remove("requests").UpdateVersion("flask", "==2.3.2").Commit(); err != nil {
+			t.Fatalf("Commit失败: %v", err)
+		}
+		// 移除时同时移除"# via"注释，文件开头的区段标题保留
+		if got := editor.SerializeToString(doc); got != "# HTTP\nflask==2.3.2\n" {
+			t.Errorf("结果 = %q", got)
+		}
+		// 位置信息在提交后同步
+		if len(doc.Requirements) != 2 || doc.Requirements[1].PositionInfo.LineNumber != 2 {
+			t.Errorf("Requirements没有同步: %+v", doc.Requirements)
+		}
+	})
+
+	t.Run("VersionEditorV2", func(t *testing.T) {
+		editor := NewVersionEditorV2()
+		doc, _ := editor.ParseRequirementsFile(content)
+
+		if _, err := editor.Begin(doc).Remove("requests").Commit(); err != nil {
+			t.Fatalf("Commit失败: %v", err)
+		}
+		// VersionEditorV2只移除requirement所在的行
+		expected := "# HTTP\n    # via -r requirements.in\nflask==2.0.0 --hash=sha256:aaaa\n"
+		if got := editor.SerializeToString(doc); got != expected || len(doc.Requirements) != 3 {
+			t.Errorf("结果 = %q", got)
+		}
+	})
+}
+
+func TestBatchUpdateVersions_Atomic(t *testing.T) {
+	content := "django==3.1.0\nflask==1.0.0\nrequests==2.25.0\n"
+	updates := map[string]string{
+		"requests": "==2.31.0",
+		"missing":  "==1.0.0",
+		"flask":    "2.0.1",
+		"django":   "==3.2.0",
+	}
+
+	v2 := NewVersionEditorV2()
+	v2doc, _ := v2.ParseRequirementsFile(content)
+	pae := NewPositionAwareEditor()
+	paedoc, _ := pae.ParseRequirementsFile(content)
+
+	errs := []error{v2.BatchUpdateVersions(v2doc, updates), pae.BatchUpdateVersions(paedoc, updates)}
+	results := []string{v2.SerializeToString(v2doc), pae.SerializeToString(paedoc)}
+	for i, err := range errs {
+		if err == nil {
+			t.Fatalf("editor %d: 应返回错误", i)
+		}
+		// 错误按包名顺序报告，与map的遍历顺序无关
+		msg := err.Error()
+		if strings.Index(msg, "flask") > strings.Index(msg, "missing") {
+			t.Errorf("错误的顺序不确定: %s", msg)
+		}
+		if results[i] != content {
+			t.Errorf("editor %d: 失败的批量更新不应修改文档:\n%s", i, results[i])
+		}
+	}
+
+	delete(updates, "missing")
+	updates["flask"] = "==2.0.1"
+	if err := v2.BatchUpdateVersions(v2doc, updates); err != nil {
+		t.Fatalf("批量更新失败: %v", err)
+	}
+	if got := v2.SerializeToString(v2doc); got != "django==3.2.0\nflask==2.0.1\nrequests==2.31.0\n" {
+		t.Errorf("结果 = %q", got)
+	}
+}
@@ -256,7 +256,7 @@ func (v *VersionEditor) UpdateRequirementInFile(content, packageName, newVersion
 	// 寻找匹配的包名并更新
 	found := false
 	for _, req := range reqs {
-		if !req.IsComment && !req.IsEmpty && req.Name == packageName {
+		if !req.IsTrivia() && req.Name == packageName {
 			if err := validateVersionSpecifier(newVersion); err != nil {
 				return "", err
 			}
@@ -275,12 +275,12 @@ func (v *VersionEditor) UpdateRequirementInFile(content, packageName, newVersion
 	lines := strings.Split(content, "\n")
 	for i, line := range lines {
 		for _, req := range reqs {
-			if !req.IsComment && !req.IsEmpty && req.Name == packageName &&
+			if !req.IsTrivia() && req.Name == packageName &&
 				strings.TrimSpace(line) == strings.TrimSpace(req.OriginalLine) {
 
 				// 构建新的行，保留任何注释
 				newLine := line
-				if commentIdx := strings.Index(line, "#"); commentIdx != -1 {
+				if commentIdx := parser.CommentStart(line); commentIdx != -1 {
 					// 有注释，提取并保留
 					commentPart := line[commentIdx:]
 
@@ -2,6 +2,7 @@ package editor
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/scagogogo/python-requirements-parser/pkg/models"
@@ -14,8 +15,16 @@ import (
 // 1. 使用parser解析requirements.txt文件为AST
 // 2. 在对象级别进行编辑操作
 // 3. 将修改后的AST序列化回文本格式
+//
+// 编辑操作由Document完成，只修改受影响的部分，文件的其余格式保持不变。
 type VersionEditorV2 struct {
 	parser *parser.Parser
+
+	// HashPolicy 修改带哈希的包的版本时的处理方式，默认为HashPolicyFail
+	HashPolicy HashPolicy
+
+	// HashProvider HashPolicy为HashPolicyProvider时提供新版本的哈希
+	HashProvider HashProvider
 }
 
 // NewVersionEditorV2 创建一个新的基于parser的版本编辑器
@@ -26,25 +35,146 @@ func NewVersionEditorV2() *VersionEditorV2 {
 }
 
 // RequirementsDocument 表示一个完整的requirements文档
+//
+// Requirements与底层Document保持同步，每次编辑后更新。
 type RequirementsDocument struct {
 	Requirements []*models.Requirement
-	originalText string
+	doc          *Document
+}
+
+// Document 返回底层的可编辑文档，可以使用其完整的编辑接口
+func (doc *RequirementsDocument) Document() *Document {
+	return doc.doc
+}
+
+// LineEnding 返回requirement在文档中的行终止符
+//
+// 新添加的requirement以及没有记录的情况返回文档中占多数的行终止符。
+func (doc *RequirementsDocument) LineEnding(req *models.Requirement) string {
+	return doc.doc.LineEnding(req)
+}
+
+// DefaultLineEnding 返回文档中占多数的行终止符（"\n"或"\r\n"）
+func (doc *RequirementsDocument) DefaultLineEnding() string {
+	return doc.doc.DefaultLineEnding()
+}
+
+// HasFinalNewline 返回文档是否以换行结尾
+func (doc *RequirementsDocument) HasFinalNewline() bool {
+	return doc.doc.HasFinalNewline()
+}
+
+// Encoding 返回文档原始的编码和BOM状态
+func (doc *RequirementsDocument) Encoding() parser.Encoding {
+	return doc.doc.Encoding()
+}
+
+// sync 在编辑后同步Requirements
+func (doc *RequirementsDocument) sync() {
+	doc.Requirements = doc.doc.Requirements()
 }
 
 // ParseRequirementsFile 解析requirements文件内容
+//
+// content为已解码的文本，开头的UTF-8 BOM会被记录并在序列化时还原。
 func (v *VersionEditorV2) ParseRequirementsFile(content string) (*RequirementsDocument, error) {
-	reqs, err := v.parser.ParseString(content)
+	text, enc := decodeText(content)
+	return v.parseText(text, enc)
+}
+
+// ParseRequirementsBytes 解析requirements文件的原始字节
+//
+// 编码按照pip的规则由BOM或PEP 263编码声明检测，SerializeToBytes会按原编码和BOM状态写回。
+func (v *VersionEditorV2) ParseRequirementsBytes(data []byte) (*RequirementsDocument, error) {
+	text, enc, err := parser.Decode(data)
 	if err != nil {
-		return nil, fmt.Errorf("解析requirements文件失败: %w", err)
+		return nil, fmt.Errorf("解码requirements文件失败: %w", err)
 	}
+	return v.parseText(text, enc)
+}
 
-	return &RequirementsDocument{
-		Requirements: reqs,
-		originalText: content,
-	}, nil
+// parseText 解析已解码的文本
+func (v *VersionEditorV2) parseText(content string, enc parser.Encoding) (*RequirementsDocument, error) {
+	d, err := newDocument(v.parser, content, enc, "")
+	if err != nil {
+		return nil, err
+	}
+	return &RequirementsDocument{Requirements: d.Requirements(), doc: d}, nil
+}
+
+// ToDocument 将编辑器文档转换为可交换的models.Document
+//
+// 返回的Document包含requirements的深拷贝以及编码、BOM、行终止符和结尾换行等
+// 格式信息，可以用models.MarshalDocument编码为JSON交给其他语言的工具。
+// 每行各自的行终止符不会保存，混用LF和CRLF的文档统一使用占多数的行终止符。
+func (v *VersionEditorV2) ToDocument(doc *RequirementsDocument) *models.Document {
+	reqs := make([]*models.Requirement, len(doc.Requirements))
+	for i, req := range doc.Requirements {
+		reqs[i] = req.Clone()
+	}
+
+	enc := doc.Encoding()
+	if enc.Name == "" {
+		enc.Name = parser.EncodingUTF8
+	}
+
+	return models.NewDocument(reqs, models.FileMetadata{
+		Encoding:     enc.Name,
+		BOM:          enc.BOM,
+		LineEnding:   doc.DefaultLineEnding(),
+		FinalNewline: doc.HasFinalNewline(),
+	})
+}
+
+// FromDocument 从models.Document（通常由models.UnmarshalDocument解码得到）创建编辑器文档
+//
+// 每个requirement按models.Requirement.String()的规范格式输出为一行，
+// 得到的文档可以像解析得到的文档一样编辑，并通过SerializeToString或
+// SerializeToBytes按Metadata中的编码、BOM、行终止符和结尾换行写回。
+//
+// 示例:
+//
+//	model, err := models.UnmarshalDocument(data)
+//	if err != nil {
+//	    return err
+//	}
+//	doc, err := editor.FromDocument(model)
+//	if err != nil {
+//	    return err
+//	}
+//	text := editor.SerializeToString(doc)
+func (v *VersionEditorV2) FromDocument(model *models.Document) (*RequirementsDocument, error) {
+	if model == nil {
+		return nil, fmt.Errorf("文档不能为nil")
+	}
+
+	newline := model.Metadata.LineEnding
+	if newline == "" {
+		newline = lineEndingLF
+	}
+
+	var sb strings.Builder
+	for i, req := range model.Requirements {
+		if req == nil {
+			return nil, fmt.Errorf("第%d个requirement为nil", i+1)
+		}
+		sb.WriteString(req.Format(models.FormatOptions{Newline: newline}))
+		if i < len(model.Requirements)-1 || model.Metadata.FinalNewline {
+			sb.WriteString(newline)
+		}
+	}
+
+	enc := parser.Encoding{Name: model.Metadata.Encoding, BOM: model.Metadata.BOM}
+	if enc.Name == "" {
+		enc.Name = parser.EncodingUTF8
+	}
+	return v.parseText(sb.String(), enc)
 }
 
 // UpdatePackageVersion 更新指定包的版本
+//
+// 包带有哈希（--hash）时按HashPolicy处理：默认返回错误，
+// 也可以移除旧哈希或从HashProvider获取新版本的哈希。
 func (v *VersionEditorV2) UpdatePackageVersion(doc *RequirementsDocument, packageName, newVersion string) error {
 	if newVersion == "" {
 		return fmt.Errorf("版本约束不能为空")
@@ -55,127 +185,141 @@ func (v *VersionEditorV2) UpdatePackageVersion(doc *RequirementsDocument, packag
 		return err
 	}
 
-	// 查找并更新包
-	found := false
-	for _, req := range doc.Requirements {
-		if !req.IsComment && !req.IsEmpty && req.Name == packageName {
-			req.Version = newVersion
-			found = true
-			break
-		}
-	}
-
-	if !found {
-		return fmt.Errorf("在requirements中未找到包: %s", packageName)
+	i, err := doc.doc.lookup(packageName)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	defer doc.sync()
+	return doc.doc.setVersionHashed(i, newVersion, v.HashPolicy, v.HashProvider)
 }
 
 // AddPackage 添加新的包依赖
+//
+// packageName除了普通包名外，也可以是本地路径、URL或可编辑安装，
+// 如"./libs/core"、"-e ."或"git+https://github.com/user/project.git#egg=project"，
+// 此时extras和markers同样会被应用，但不支持版本约束。
+// 新的依赖以规范格式添加到文档末尾。
 func (v *VersionEditorV2) AddPackage(doc *RequirementsDocument, packageName, version string, extras []string, markers string) error {
+	newReq, err := newRequirement(v.parser, packageName)
+	if err != nil {
+		return err
+	}
+
 	// 检查包是否已存在
-	for _, req := range doc.Requirements {
-		if !req.IsComment && !req.IsEmpty && req.Name == packageName {
-			return fmt.Errorf("包 %s 已存在", packageName)
-		}
+	if doc.doc.Index(newReq.Key()) != -1 {
+		return fmt.Errorf("包 %s 已存在", packageName)
 	}
 
 	// 验证版本格式
 	if version != "" {
+		if newReq.IsLocalPath || newReq.IsURL || newReq.IsVCS {
+			return fmt.Errorf("本地路径或URL依赖不支持版本约束: %s", packageName)
+		}
 		if err := v.validateVersionSpecifier(version); err != nil {
 			return err
 		}
+		newReq.Version = version
 	}
 
-	// 创建新的requirement
-	newReq := &models.Requirement{
-		Name:    packageName,
-		Version: version,
-		Extras:  extras,
-		Markers: markers,
+	if len(extras) > 0 {
+		newReq.Extras = extras
+	}
+	if markers != "" {
+		newReq.Markers = markers
 	}
 
 	// 添加到文档
-	doc.Requirements = append(doc.Requirements, newReq)
-	return nil
+	defer doc.sync()
+	_, err = doc.doc.Add(newReq.String())
+	return err
+}
+
+// newRequirement 根据AddPackage的参数创建requirement
+//
+// 普通包名直接创建，本地路径、URL和可编辑安装则交给parser解析，
+// 以便与解析文件时得到的结构一致。
+func newRequirement(p *parser.Parser, packageName string) (*models.Requirement, error) {
+	if strings.TrimSpace(packageName) == "" {
+		return nil, fmt.Errorf("包名不能为空")
+	}
+
+	reqs, err := p.ParseString(packageName)
+	if err != nil {
+		return nil, err
+	}
+	if len(reqs) != 1 {
+		return nil, fmt.Errorf("无效的包名: %s", packageName)
+	}
+
+	parsed := reqs[0]
+	if parsed.IsLocalPath || parsed.IsURL || parsed.IsVCS || parsed.IsEditable {
+		parsed.OriginalLine = ""
+		return parsed, nil
+	}
+
+	return &models.Requirement{Kind: models.KindPackage, Name: packageName}, nil
 }
 
 // RemovePackage 移除指定的包
 func (v *VersionEditorV2) RemovePackage(doc *RequirementsDocument, packageName string) error {
-	for i, req := range doc.Requirements {
-		if !req.IsComment && !req.IsEmpty && req.Name == packageName {
-			// 移除该requirement
-			doc.Requirements = append(doc.Requirements[:i], doc.Requirements[i+1:]...)
-			return nil
-		}
-	}
-	return fmt.Errorf("在requirements中未找到包: %s", packageName)
+	defer doc.sync()
+	return doc.doc.Remove(packageName)
 }
 
 // UpdatePackageExtras 更新包的extras
+//
+// packageName可以是包名，也可以是本地路径或URL（用于没有包名的本地/URL依赖）
 func (v *VersionEditorV2) UpdatePackageExtras(doc *RequirementsDocument, packageName string, extras []string) error {
-	for _, req := range doc.Requirements {
-		if !req.IsComment && !req.IsEmpty && req.Name == packageName {
-			req.Extras = extras
-			return nil
-		}
-	}
-	return fmt.Errorf("在requirements中未找到包: %s", packageName)
+	defer doc.sync()
+	return doc.doc.SetExtras(packageName, extras)
 }
 
 // UpdatePackageMarkers 更新包的环境标记
 func (v *VersionEditorV2) UpdatePackageMarkers(doc *RequirementsDocument, packageName string, markers string) error {
-	for _, req := range doc.Requirements {
-		if !req.IsComment && !req.IsEmpty && req.Name == packageName {
-			req.Markers = markers
-			return nil
-		}
-	}
-	return fmt.Errorf("在requirements中未找到包: %s", packageName)
+	defer doc.sync()
+	return doc.doc.SetMarkers(packageName, markers)
 }
 
 // BatchUpdateVersions 批量更新多个包的版本
+//
+// 所有更新按包名排序后在一个事务中执行（见Transaction），结果与map的遍历顺序无关。
+// 任一更新失败时撤销全部修改并返回汇总的错误，文档保持不变。
 func (v *VersionEditorV2) BatchUpdateVersions(doc *RequirementsDocument, updates map[string]string) error {
-	var errors []string
-
-	for packageName, newVersion := range updates {
-		if err := v.UpdatePackageVersion(doc, packageName, newVersion); err != nil {
-			errors = append(errors, fmt.Sprintf("%s: %v", packageName, err))
-		}
+	names := make([]string, 0, len(updates))
+	for name := range updates {
+		names = append(names, name)
 	}
+	sort.Strings(names)
 
-	if len(errors) > 0 {
-		return fmt.Errorf("批量更新失败: %s", strings.Join(errors, "; "))
+	tx := v.Begin(doc)
+	for _, name := range names {
+		tx.UpdateVersion(name, updates[name])
+	}
+	if _, err := tx.Commit(); err != nil {
+		return fmt.Errorf("批量更新失败: %w", err)
 	}
-
 	return nil
 }
 
+// Undo 撤销对文档的最近一次修改，见Document.Undo
+func (v *VersionEditorV2) Undo(doc *RequirementsDocument) error {
+	defer doc.sync()
+	return doc.doc.Undo()
+}
+
+// Redo 重做最近一次撤销的修改，见Document.Redo
+func (v *VersionEditorV2) Redo(doc *RequirementsDocument) error {
+	defer doc.sync()
+	return doc.doc.Redo()
+}
+
 // GetPackageInfo 获取指定包的信息
+//
+// 返回的是requirement的副本，修改它不会影响文档。
 func (v *VersionEditorV2) GetPackageInfo(doc *RequirementsDocument, packageName string) (*models.Requirement, error) {
-	for _, req := range doc.Requirements {
-		if !req.IsComment && !req.IsEmpty && req.Name == packageName {
-			// 返回副本，避免意外修改
-			return &models.Requirement{
-				Name:               req.Name,
-				Version:            req.Version,
-				Extras:             append([]string{}, req.Extras...),
-				Markers:            req.Markers,
-				IsComment:          req.IsComment,
-				IsEmpty:            req.IsEmpty,
-				IsURL:              req.IsURL,
-				IsVCS:              req.IsVCS,
-				IsEditable:         req.IsEditable,
-				URL:                req.URL,
-				VCSType:            req.VCSType,
-				Comment:            req.Comment,
-				Hashes:             append([]string{}, req.Hashes...),
-				GlobalOptions:      copyMap(req.GlobalOptions),
-				RequirementOptions: copyMap(req.RequirementOptions),
-				OriginalLine:       req.OriginalLine,
-			}, nil
-		}
+	if req := doc.doc.Find(packageName); req != nil {
+		return req.Clone(), nil
 	}
 	return nil, fmt.Errorf("在requirements中未找到包: %s", packageName)
 }
@@ -184,7 +328,7 @@ func (v *VersionEditorV2) GetPackageInfo(doc *RequirementsDocument, packageName
 func (v *VersionEditorV2) ListPackages(doc *RequirementsDocument) []*models.Requirement {
 	var packages []*models.Requirement
 	for _, req := range doc.Requirements {
-		if !req.IsComment && !req.IsEmpty {
+		if !req.IsTrivia() {
 			packages = append(packages, req)
 		}
 	}
@@ -192,98 +336,33 @@ func (v *VersionEditorV2) ListPackages(doc *RequirementsDocument) []*models.Requ
 }
 
 // SerializeToString 将文档序列化为字符串
+//
+// 未修改的行保持原样，修改过的行只有被修改的部分发生变化，
+// 新增的行使用文档中占多数的行终止符，并保持原始文档是否以换行结尾的状态。
 func (v *VersionEditorV2) SerializeToString(doc *RequirementsDocument) string {
-	var lines []string
-
-	for _, req := range doc.Requirements {
-		line := v.serializeRequirement(req)
-		lines = append(lines, line)
-	}
-
-	return strings.Join(lines, "\n")
+	return doc.doc.String()
 }
 
-// serializeRequirement 将单个requirement序列化为字符串
-func (v *VersionEditorV2) serializeRequirement(req *models.Requirement) string {
-	if req.IsComment {
-		if req.Comment != "" {
-			return "# " + req.Comment
-		}
-		return req.OriginalLine
-	}
-
-	if req.IsEmpty {
-		return ""
-	}
-
-	var parts []string
-
-	// 处理可编辑标记
-	if req.IsEditable {
-		parts = append(parts, "-e")
-	}
-
-	// 处理VCS URL
-	if req.IsVCS && req.URL != "" {
-		vcsURL := req.URL
-		if req.VCSType != "" {
-			vcsURL = req.VCSType + "+" + vcsURL
-		}
-		parts = append(parts, vcsURL)
-		if req.Name != "" {
-			parts[len(parts)-1] += "#egg=" + req.Name
-		}
-	} else if req.IsURL && req.URL != "" {
-		// 处理普通URL
-		parts = append(parts, req.URL)
-	} else if req.IsLocalPath && req.LocalPath != "" {
-		// 处理本地路径
-		parts = append(parts, req.LocalPath)
-	} else {
-		// 处理普通包名
-		packagePart := req.Name
-
-		// 添加extras
-		if len(req.Extras) > 0 {
-			packagePart += "[" + strings.Join(req.Extras, ",") + "]"
-		}
-
-		// 添加版本约束
-		if req.Version != "" {
-			packagePart += req.Version
-		}
-
-		parts = append(parts, packagePart)
-	}
-
-	// 添加环境标记
-	if req.Markers != "" {
-		parts = append(parts, "; "+req.Markers)
-	}
-
-	// 添加选项
-	for key, value := range req.RequirementOptions {
-		if key == "hash" {
-			parts = append(parts, "--hash="+value)
-		} else {
-			parts = append(parts, "--"+key+"="+value)
-		}
-	}
-
-	// 添加注释
-	result := strings.Join(parts, " ")
-	if req.Comment != "" {
-		result += " # " + req.Comment
-	}
-
-	return result
+// SerializeToBytes 将文档按原始编码和BOM状态序列化为字节
+func (v *VersionEditorV2) SerializeToBytes(doc *RequirementsDocument) ([]byte, error) {
+	return doc.doc.Bytes()
 }
 
 // validateVersionSpecifier 验证版本约束格式
 func (v *VersionEditorV2) validateVersionSpecifier(version string) error {
+	return checkSpecifierOperator(version)
+}
+
+// checkSpecifierOperator 检查版本约束是否以比较操作符开头
+//
+// 版本约束中不会出现"#"和换行，含有它们的值会在行中变成注释或新的一行。
+func checkSpecifierOperator(version string) error {
 	if version == "" {
 		return fmt.Errorf("版本约束不能为空")
 	}
+	if strings.ContainsAny(version, "#\r\n") {
+		return fmt.Errorf("无效的版本约束格式: %q", version)
+	}
 
 	// 简单的版本格式验证
 	validPrefixes := []string{"==", ">=", "<=", ">", "<", "~=", "!=", "==="}
@@ -296,14 +375,16 @@ func (v *VersionEditorV2) validateVersionSpecifier(version string) error {
 	return fmt.Errorf("无效的版本约束格式: %s", version)
 }
 
-// copyMap 复制map
-func copyMap(original map[string]string) map[string]string {
-	if original == nil {
-		return nil
+// matchesPackage 检查requirement是否与给定的包名、本地路径或URL匹配
+func matchesPackage(req *models.Requirement, key string) bool {
+	if req.IsTrivia() || key == "" {
+		return false
+	}
+	if req.Name != "" && models.NormalizeName(req.Name) == models.NormalizeName(key) {
+		return true
 	}
-	copy := make(map[string]string)
-	for k, v := range original {
-		copy[k] = v
+	if req.IsLocalPath && (req.LocalPath == key || req.URL == key) {
+		return true
 	}
-	return copy
+	return (req.IsURL || req.IsVCS) && req.URL == key
 }
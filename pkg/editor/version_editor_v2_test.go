@@ -3,6 +3,8 @@ package editor
 import (
 	"strings"
 	"testing"
+
+	"github.com/scagogogo/python-requirements-parser/pkg/models"
 )
 
 // TestVersionEditorV2_BasicOperations 测试基本操作
@@ -347,3 +349,155 @@ func TestVersionEditorV2_ErrorHandling(t *testing.T) {
 		t.Error("批量更新包含不存在的包应该返回错误")
 	}
 }
+
+// TestVersionEditorV2_LocalAndURLExtras 测试本地路径、可编辑安装和URL依赖的extras
+func TestVersionEditorV2_LocalAndURLExtras(t *testing.T) {
+	editor := NewVersionEditorV2()
+
+	content := `-e .[dev,test]
+./libs/core[fast]
+-e ../pkg ; python_version>'3.8'
+-e git+https://github.com/user/project.git#egg=project[cli]
+pkg[extra] @ https://example.com/pkg-1.0.whl`
+
+	doc, err := editor.ParseRequirementsFile(content)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+
+	// 没有包名的本地依赖通过路径查找
+	if err := editor.UpdatePackageExtras(doc, ".", []string{"dev"}); err != nil {
+		t.Fatalf("更新可编辑安装的extras失败: %v", err)
+	}
+
+	if err := editor.UpdatePackageExtras(doc, "../pkg", []string{"gpu"}); err != nil {
+		t.Fatalf("更新本地路径的extras失败: %v", err)
+	}
+
+	if err := editor.UpdatePackageExtras(doc, "project", []string{"cli", "docs"}); err != nil {
+		t.Fatalf("更新VCS依赖的extras失败: %v", err)
+	}
+
+	if err := editor.AddPackage(doc, "-e ./plugins/extra", "", []string{"all"}, "sys_platform == 'linux'"); err != nil {
+		t.Fatalf("添加可编辑安装失败: %v", err)
+	}
+
+	if err := editor.AddPackage(doc, "./libs/core", "", nil, ""); err == nil {
+		t.Error("重复添加本地路径应该返回错误")
+	}
+
+	if err := editor.AddPackage(doc, "./libs/other", "==1.0.0", nil, ""); err == nil {
+		t.Error("本地路径带版本约束应该返回错误")
+	}
+
+	result := editor.SerializeToString(doc)
+
+	expected := []string{
+		"-e .[dev]",
+		"./libs/core[fast]",
+		"-e ../pkg[gpu] ; python_version>'3.8'",
+		"-e git+https://github.com/user/project.git#egg=project[cli,docs]",
+		"pkg[extra] @ https://example.com/pkg-1.0.whl",
+		"-e ./plugins/extra[all] ; sys_platform == 'linux'",
+	}
+	lines := strings.Split(result, "\n")
+	if len(lines) != len(expected) {
+		t.Fatalf("期望%d行，实际得到%d行:\n%s", len(expected), len(lines), result)
+	}
+	for i, want := range expected {
+		if lines[i] != want {
+			t.Errorf("第%d行 = %q, want %q", i+1, lines[i], want)
+		}
+	}
+}
+
+// TestVersionEditorV2_SerializeAllKinds 测试文件引用、全局选项和哈希在序列化后保留
+func TestVersionEditorV2_SerializeAllKinds(t *testing.T) {
+	editor := NewVersionEditorV2()
+
+	content := `--index-url https://pypi.example.com/simple
+-r base.txt
+-c constraints.txt
+django==3.1.0 --hash=sha256:abcdef1234567890
+flask==1.0.0`
+
+	doc, err := editor.ParseRequirementsFile(content)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+
+	// django带有哈希，修改版本需要新版本的哈希
+	editor.HashPolicy = HashPolicyProvider
+	editor.HashProvider = mapHashProvider{"django==3.2.0": {"sha256:abcdef1234567890"}}
+	if err := editor.UpdatePackageVersion(doc, "django", "==3.2.0"); err != nil {
+		t.Fatalf("更新django失败: %v", err)
+	}
+
+	expected := `--index-url https://pypi.example.com/simple
+-r base.txt
+-c constraints.txt
+django==3.2.0 --hash=sha256:abcdef1234567890
+flask==1.0.0`
+
+	if result := editor.SerializeToString(doc); result != expected {
+		t.Errorf("序列化结果:\n%s\n期望:\n%s", result, expected)
+	}
+}
+
+// TestVersionEditorV2_DocumentJSONRoundTrip 测试文档经过JSON往返后可以编辑并按原格式写回
+func TestVersionEditorV2_DocumentJSONRoundTrip(t *testing.T) {
+	editor := NewVersionEditorV2()
+
+	// latin-1编码、CRLF行终止符、没有结尾换行
+	content := "# -*- coding: latin-1 -*-\r\n--index-url https://pypi.example.com/simple\r\n\r\n" +
+		"flask==1.0.0 # caf\xe9\r\n-e git+https://github.com/user/project.git#egg=project"
+
+	doc, err := editor.ParseRequirementsBytes([]byte(content))
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+
+	data, err := models.MarshalDocument(editor.ToDocument(doc))
+	if err != nil {
+		t.Fatalf("编码JSON失败: %v", err)
+	}
+	model, err := models.UnmarshalDocument(data)
+	if err != nil {
+		t.Fatalf("解码JSON失败: %v", err)
+	}
+	if model.Metadata.Encoding != "latin-1" || model.Metadata.LineEnding != "\r\n" || model.Metadata.FinalNewline {
+		t.Errorf("元数据 = %+v", model.Metadata)
+	}
+	if model.Options["index-url"] != "https://pypi.example.com/simple" {
+		t.Errorf("Options = %v", model.Options)
+	}
+
+	restored, err := editor.FromDocument(model)
+	if err != nil {
+		t.Fatalf("FromDocument失败: %v", err)
+	}
+	out, err := editor.SerializeToBytes(restored)
+	if err != nil {
+		t.Fatalf("序列化失败: %v", err)
+	}
+	if string(out) != content {
+		t.Errorf("往返结果 = %q, 期望 %q", out, content)
+	}
+
+	if err := editor.UpdatePackageVersion(restored, "flask", "==2.0.1"); err != nil {
+		t.Fatalf("更新flask失败: %v", err)
+	}
+	if result := editor.SerializeToString(restored); !strings.Contains(result, "flask==2.0.1 # caf\u00e9\r\n") {
+		t.Errorf("更新后的结果 = %q", result)
+	}
+
+	// 修改Document不影响已创建的编辑器文档
+	model.Requirements[3].Version = "==0.1"
+	if info, _ := editor.GetPackageInfo(restored, "flask"); info.Version != "==2.0.1" {
+		t.Errorf("FromDocument应复制requirements，得到版本 %q", info.Version)
+	}
+
+	if _, err := editor.FromDocument(nil); err == nil {
+		t.Errorf("FromDocument(nil)应返回错误")
+	}
+}
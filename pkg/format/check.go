@@ -0,0 +1,141 @@
+package format
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/scagogogo/python-requirements-parser/pkg/models"
+	"github.com/scagogogo/python-requirements-parser/pkg/parser"
+)
+
+// Check 检查内容是否已经是规范格式，对每个需要修改的行报告一条诊断信息
+//
+// 诊断信息的代码为"not-formatted"，级别为警告，行号为逻辑行在原内容中的起始行号，
+// 消息中给出格式化后的内容。没有诊断信息表示内容已经格式化（BOM不参与检查）。
+//
+// 参数:
+//   - content: requirements文件内容
+//   - opts: 格式化选项，为nil时使用默认选项
+//
+// 返回:
+//   - []models.Diagnostic: 需要修改的行
+//   - error: 解析失败时的错误
+//
+// 示例:
+//
+//	diagnostics, _ := format.Check("flask >= 1.0\n", nil)
+//	// diagnostics[0].Line == 1
+//	// diagnostics[0].Message == `应格式化为 "flask>=1.0"`
+func Check(content string, opts *Options) ([]models.Diagnostic, error) {
+	doc, err := formatDocument(content, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var diagnostics []models.Diagnostic
+	for _, line := range doc.lines {
+		var message string
+		switch {
+		case line.drop:
+			message = "应删除该行"
+		case line.raw == line.text+doc.newline:
+			continue
+		case line.raw == line.text:
+			message = "文件应以换行结尾"
+		case strings.TrimSuffix(strings.TrimSuffix(line.raw, "\n"), "\r") == line.text:
+			message = fmt.Sprintf("行终止符应为 %q", doc.newline)
+		default:
+			message = fmt.Sprintf("应格式化为 %q", line.text)
+		}
+		diagnostics = append(diagnostics, models.Diagnostic{
+			Severity: models.SeverityWarning,
+			Code:     "not-formatted",
+			Message:  message,
+			Line:     line.number,
+		})
+	}
+	return diagnostics, nil
+}
+
+// IsFormatted 检查内容是否已经是规范格式
+func IsFormatted(content string, opts *Options) (bool, error) {
+	formatted, err := Format(content, opts)
+	if err != nil {
+		return false, err
+	}
+	return formatted == content, nil
+}
+
+// CheckFile 检查requirements文件是否已经是规范格式
+//
+// 文件按原始编码解码，诊断信息的File字段为path。
+func CheckFile(path string, opts *Options) ([]models.Diagnostic, error) {
+	text, _, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	diagnostics, err := Check(text, opts)
+	if err != nil {
+		return nil, err
+	}
+	for i := range diagnostics {
+		diagnostics[i].File = path
+	}
+	return diagnostics, nil
+}
+
+// FormatFile 格式化requirements文件并写回，按原始编码和BOM状态编码
+//
+// 文件已经是规范格式时不写入。
+//
+// 返回:
+//   - bool: 文件是否被修改
+//   - error: 读写或解析失败时的错误
+func FormatFile(path string, opts *Options) (bool, error) {
+	text, enc, err := readFile(path)
+	if err != nil {
+		return false, err
+	}
+
+	formatted, err := Format(text, opts)
+	if err != nil {
+		return false, err
+	}
+	data, err := parser.Encode(formatted, enc)
+	if err != nil {
+		return false, fmt.Errorf("编码失败: %w", err)
+	}
+
+	original, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("读取文件失败: %w", err)
+	}
+	if bytes.Equal(original, data) {
+		return false, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, fmt.Errorf("读取文件失败: %w", err)
+	}
+	if err := os.WriteFile(path, data, info.Mode().Perm()); err != nil {
+		return false, fmt.Errorf("写入文件失败: %w", err)
+	}
+	return true, nil
+}
+
+// readFile 读取并解码requirements文件
+func readFile(path string) (string, parser.Encoding, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", parser.Encoding{}, fmt.Errorf("读取文件失败: %w", err)
+	}
+	text, enc, err := parser.Decode(data)
+	if err != nil {
+		return "", parser.Encoding{}, fmt.Errorf("解码文件失败: %w", err)
+	}
+	return text, enc, nil
+}
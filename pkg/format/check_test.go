@@ -0,0 +1,79 @@
+package format
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/scagogogo/python-requirements-parser/pkg/models"
+)
+
+func TestCheck(t *testing.T) {
+	input := "flask >= 1.0\n\n\nrequests\nsix --hash=sha256:aaaa --hash=sha256:bbbb\nattrs"
+
+	diagnostics, err := Check(input, nil)
+	if err != nil {
+		t.Fatalf("Check失败: %v", err)
+	}
+
+	want := []models.Diagnostic{
+		{Line: 1, Message: `应格式化为 "flask>=1.0"`},
+		{Line: 3, Message: "应删除该行"},
+		{Line: 5, Message: `应格式化为 "six \\\n    --hash=sha256:aaaa \\\n    --hash=sha256:bbbb"`},
+		{Line: 6, Message: "文件应以换行结尾"},
+	}
+	if len(diagnostics) != len(want) {
+		t.Fatalf("诊断信息 = %+v", diagnostics)
+	}
+	for i, d := range diagnostics {
+		if d.Line != want[i].Line || d.Message != want[i].Message {
+			t.Errorf("诊断信息[%d] = 第%d行 %q, 期望 第%d行 %q", i, d.Line, d.Message, want[i].Line, want[i].Message)
+		}
+		if d.Code != "not-formatted" || d.Severity != models.SeverityWarning {
+			t.Errorf("诊断信息[%d] = %+v", i, d)
+		}
+	}
+
+	formatted, err := IsFormatted(input, nil)
+	if err != nil || formatted {
+		t.Errorf("IsFormatted() = %v, %v", formatted, err)
+	}
+	formatted, err = IsFormatted("flask>=1.0\n", nil)
+	if err != nil || !formatted {
+		t.Errorf("IsFormatted() = %v, %v", formatted, err)
+	}
+}
+
+func TestFormatFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "requirements.txt")
+	// latin-1编码声明的文件按原编码写回
+	content := []byte("# -*- coding: latin-1 -*-\nflask >= 1.0  #caf\xe9\n")
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	diagnostics, err := CheckFile(path, nil)
+	if err != nil {
+		t.Fatalf("CheckFile失败: %v", err)
+	}
+	if len(diagnostics) != 1 || diagnostics[0].File != path || diagnostics[0].Line != 2 {
+		t.Errorf("诊断信息 = %+v", diagnostics)
+	}
+
+	changed, err := FormatFile(path, nil)
+	if err != nil || !changed {
+		t.Fatalf("FormatFile() = %v, %v", changed, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "# -*- coding: latin-1 -*-\nflask>=1.0 # caf\xe9\n"; string(data) != want {
+		t.Errorf("文件内容 = %q, 期望 %q", data, want)
+	}
+
+	changed, err = FormatFile(path, nil)
+	if err != nil || changed {
+		t.Errorf("已格式化的文件FormatFile() = %v, %v", changed, err)
+	}
+}
@@ -0,0 +1,332 @@
+// Package format 将requirements文件格式化为规范布局，类似于requirements文件的gofmt
+//
+// 格式化按逻辑行进行：每行由parser解析后使用models.Requirement.Format重新输出，
+// 同时规范化版本约束、extras、环境标记和注释。行的顺序不变，
+// 注释行和行尾注释仍然留在原来的依赖旁边。parser不能完整表示的行
+// （例如无法识别的内容）保持原样，只去掉行尾空白，因此格式化不会改变文件的含义。
+//
+// 示例:
+//
+//	out, err := format.Format("Django[rest,Auth]>= 3.2 , <4;python_version>='3.8'  #web\n", nil)
+//	// out == "Django[Auth,rest]>=3.2,<4 ; python_version >= \"3.8\" # web\n"
+package format
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/scagogogo/python-requirements-parser/pkg/cst"
+	"github.com/scagogogo/python-requirements-parser/pkg/models"
+	"github.com/scagogogo/python-requirements-parser/pkg/parser"
+)
+
+// HashLayout --hash选项的布局
+type HashLayout int
+
+const (
+	// HashesWrapped 每个--hash放在单独的续行中，与pip-compile --generate-hashes的输出一致（默认）
+	HashesWrapped HashLayout = iota
+
+	// HashesInline 所有--hash与依赖放在同一行中
+	HashesInline
+)
+
+// Options 格式化选项，零值为默认格式
+type Options struct {
+	// NormalizeNames 是否按PEP 503规范化包名和extras（转换为小写，分隔符统一为"-"）
+	NormalizeNames bool
+
+	// Hashes --hash选项的布局
+	Hashes HashLayout
+
+	// Indent 哈希续行的缩进，默认为4个空格
+	Indent string
+}
+
+// Format 将requirements文件内容格式化为规范布局
+//
+// 规则:
+//   - 依赖按models.Requirement.Format输出：环境标记前为" ; "，选项按名称排序，哈希在最后
+//   - 版本约束去掉空白和外层括号，子句顺序不变，如">= 1.0 , <2"变为">=1.0,<2"
+//   - extras按规范化的名称排序并去重
+//   - 环境标记按models.NormalizeMarker规范化
+//   - 注释以"# "开头，行尾注释前为一个空格；以"#"、"!"等符号开头的注释（如"##"分隔线）不插入空格
+//   - 连续的空行合并为一行，去掉开头和结尾的空行，文件以换行结尾
+//   - 行终止符和BOM与原文件一致
+//
+// 参数:
+//   - content: requirements文件内容
+//   - opts: 格式化选项，为nil时使用默认选项
+//
+// 返回:
+//   - string: 格式化后的内容
+//   - error: 解析失败时的错误
+func Format(content string, opts *Options) (string, error) {
+	doc, err := formatDocument(content, opts)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	sb.WriteString(doc.bom)
+	for _, line := range doc.lines {
+		if !line.drop {
+			sb.WriteString(line.text)
+			sb.WriteString(doc.newline)
+		}
+	}
+	return sb.String(), nil
+}
+
+// formattedDocument 按逻辑行记录的格式化结果
+type formattedDocument struct {
+	bom     string
+	newline string
+	lines   []formattedLine
+}
+
+// formattedLine 一个逻辑行的格式化结果
+type formattedLine struct {
+	// number 在原文件中的起始行号
+	number int
+
+	// raw 原始文本，包括行终止符
+	raw string
+
+	// text 格式化后的内容，不包括行终止符
+	text string
+
+	// drop 该行在格式化后被删除（多余的空行）
+	drop bool
+}
+
+// formatDocument 逐个逻辑行格式化内容
+func formatDocument(content string, opts *Options) (*formattedDocument, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	doc := &formattedDocument{newline: "\n"}
+	if strings.HasPrefix(content, "\ufeff") {
+		doc.bom = "\ufeff"
+	}
+	file := cst.Parse(strings.TrimPrefix(content, "\ufeff"))
+	for _, line := range file.Lines {
+		if line.Newline != "" {
+			doc.newline = line.Newline
+			break
+		}
+	}
+
+	p := parser.New()
+	previousBlank := true
+	for i, line := range file.Lines {
+		text, err := formatLine(p, line, doc.newline, opts)
+		if err != nil {
+			return nil, err
+		}
+		blank := text == ""
+		doc.lines = append(doc.lines, formattedLine{
+			number: file.LineNumber(i),
+			raw:    line.String(),
+			text:   text,
+			drop:   blank && previousBlank,
+		})
+		previousBlank = blank
+	}
+
+	// 去掉结尾的空行
+	for i := len(doc.lines) - 1; i >= 0 && doc.lines[i].text == ""; i-- {
+		doc.lines[i].drop = true
+	}
+	return doc, nil
+}
+
+// formatLine 格式化一个逻辑行，空行返回空字符串
+func formatLine(p *parser.Parser, line *cst.Line, newline string, opts *Options) (string, error) {
+	if line.IsBlank() {
+		return "", nil
+	}
+
+	var comment string
+	if node := line.Comment(); node != nil {
+		comment = formatComment(node.Raw)
+	}
+	if line.IsComment() {
+		return comment, nil
+	}
+
+	text := line.Text()
+	reqs, err := p.ParseString(text)
+	if err != nil {
+		return "", fmt.Errorf("解析失败: %w", err)
+	}
+	if len(reqs) != 1 {
+		return strings.TrimRightFunc(text, unicode.IsSpace), nil
+	}
+
+	req := normalize(reqs[0], opts)
+	req.Comment = ""
+	out := req.Format(models.FormatOptions{
+		MultilineHashes: opts.Hashes == HashesWrapped,
+		Indent:          opts.Indent,
+		Newline:         newline,
+	})
+
+	// 规范化之后含义和内容必须不变，否则保持原样
+	check, err := p.ParseString(out)
+	if err != nil || len(check) != 1 || !check[0].SemanticEqual(reqs[0]) || !lossless(line, reqs[0], out) {
+		return strings.TrimRightFunc(text, unicode.IsSpace), nil
+	}
+
+	if comment != "" {
+		out += " " + comment
+	}
+	return out, nil
+}
+
+// lossless 检查requirement重新输出后是否保留了行中的全部内容
+//
+// parser会忽略无法识别的内容，也可能把选项当作环境标记的一部分。这里比较三者的词法单元序列：
+// 原来的行与不做规范化直接输出的requirement比较全部文本（忽略空白），用于发现被丢弃的内容；
+// 原来的行与格式化的结果比较节点类型、选项和哈希，用于发现格式化改变了的结构。
+func lossless(line *cst.Line, req *models.Requirement, out string) bool {
+	plain := req.Clone()
+	plain.Comment = ""
+	return sameTokens(tokens(line, true), tokens(cst.ParseLine(plain.String()), true)) &&
+		sameTokens(tokens(line, false), tokens(cst.ParseLine(out), false))
+}
+
+// sameTokens 检查两个词法单元序列是否完全相同
+func sameTokens(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// tokens 返回行中除空白、行继续符和注释之外的词法单元
+//
+// 选项和哈希记录选项名和值（不含分隔符），因此"--config-settings a=b"与
+// "--config-settings=a=b"相同；无法识别的内容记录原文。包名、extras、版本约束、
+// 环境标记和引用在withText为true时记录去掉空白的文本，否则只记录节点类型。
+func tokens(line *cst.Line, withText bool) []string {
+	var result []string
+	for _, node := range line.Nodes {
+		switch n := node.(type) {
+		case *cst.Whitespace, *cst.Continuation, *cst.Comment:
+		case *cst.Option:
+			result = append(result, n.Flag+" "+n.Value)
+		case *cst.Hash:
+			result = append(result, n.Flag+" "+n.Value)
+		case *cst.Unknown:
+			result = append(result, "?"+n.Raw)
+		default:
+			token := node.Kind().String()
+			if withText {
+				token += ":" + strings.Join(strings.Fields(node.Text()), "")
+			}
+			result = append(result, token)
+		}
+	}
+	return result
+}
+
+// normalize 返回规范化后的requirement副本
+func normalize(req *models.Requirement, opts *Options) *models.Requirement {
+	req = req.Clone()
+	if opts.NormalizeNames && req.Name != "" {
+		req.Name = models.NormalizeName(req.Name)
+	}
+	req.Version = formatSpecifier(req.Version)
+	req.Extras = formatExtras(req.Extras, opts.NormalizeNames)
+	if req.Markers != "" {
+		req.Markers = formatMarker(req.Markers)
+	}
+	return req
+}
+
+// formatMarker 规范化环境标记，括号内侧不留空格
+//
+// 示例:
+//
+//	formatMarker("python_version<'3.9' or (os_name=='nt')")
+//	// 返回 `python_version < "3.9" or (os_name == "nt")`
+func formatMarker(marker string) string {
+	marker = models.NormalizeMarker(marker)
+	marker = strings.ReplaceAll(marker, "( ", "(")
+	return strings.ReplaceAll(marker, " )", ")")
+}
+
+// formatSpecifier 去掉版本约束中的空白和外层括号，保持子句顺序
+//
+// 示例:
+//
+//	formatSpecifier(" >= 1.0 , <2") // 返回 ">=1.0,<2"
+func formatSpecifier(spec string) string {
+	spec = strings.Join(strings.Fields(spec), "")
+	if strings.HasPrefix(spec, "(") && strings.HasSuffix(spec, ")") {
+		spec = spec[1 : len(spec)-1]
+	}
+
+	var clauses []string
+	for _, clause := range strings.Split(spec, ",") {
+		if clause != "" {
+			clauses = append(clauses, clause)
+		}
+	}
+	return strings.Join(clauses, ",")
+}
+
+// formatExtras 按规范化的名称排序并去重extras
+func formatExtras(extras []string, normalizeNames bool) []string {
+	if len(extras) == 0 {
+		return nil
+	}
+
+	seen := make(map[string]bool, len(extras))
+	var result []string
+	for _, extra := range extras {
+		extra = strings.TrimSpace(extra)
+		key := models.NormalizeName(extra)
+		if extra == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		if normalizeNames {
+			extra = key
+		}
+		result = append(result, extra)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return models.NormalizeName(result[i]) < models.NormalizeName(result[j])
+	})
+	return result
+}
+
+// formatComment 规范化注释："#text"和"#   text"都变为"# text"，去掉行尾空白
+//
+// "#"之后紧跟符号（如"##"、"#!"、"#---"）时保持原样，以免破坏分隔线等写法。
+func formatComment(raw string) string {
+	body := strings.TrimRightFunc(strings.TrimPrefix(raw, "#"), unicode.IsSpace)
+	trimmed := strings.TrimLeftFunc(body, unicode.IsSpace)
+	switch {
+	case trimmed == "":
+		return "#"
+	case trimmed != body:
+		return "# " + trimmed
+	}
+
+	first := []rune(body)[0]
+	if unicode.IsLetter(first) || unicode.IsDigit(first) {
+		return "# " + body
+	}
+	return "#" + body
+}
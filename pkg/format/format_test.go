@@ -0,0 +1,129 @@
+package format
+
+import (
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  *Options
+		want  string
+	}{
+		{
+			name:  "版本约束",
+			input: "flask >= 1.0 , <2\nrequests (>=2.0)\n",
+			want:  "flask>=1.0,<2\nrequests>=2.0\n",
+		},
+		{
+			name:  "extras排序去重",
+			input: "django[rest, Auth,auth]==4.2\n",
+			want:  "django[Auth,rest]==4.2\n",
+		},
+		{
+			name:  "规范化包名",
+			input: "Flask_Login[Extra_One]==0.6\n",
+			opts:  &Options{NormalizeNames: true},
+			want:  "flask-login[extra-one]==0.6\n",
+		},
+		{
+			name:  "默认不修改包名",
+			input: "Flask_Login==0.6\n",
+			want:  "Flask_Login==0.6\n",
+		},
+		{
+			name:  "环境标记",
+			input: "pywin32;os_name=='nt'  AND python_version>='3.8'\n",
+			want:  "pywin32 ; os_name == \"nt\" and python_version >= \"3.8\"\n",
+		},
+		{
+			name:  "环境标记中的括号",
+			input: "numpy ; python_version<'3.9' or ( os_name=='nt' )\n",
+			want:  "numpy ; python_version < \"3.9\" or (os_name == \"nt\")\n",
+		},
+		{
+			name:  "注释",
+			input: "#deps\n#   web   \nflask   #pinned\n## section\n#!special\n",
+			want:  "# deps\n# web\nflask # pinned\n## section\n#!special\n",
+		},
+		{
+			name:  "空行",
+			input: "\n\nflask\n\n\n\nrequests\n   \n",
+			want:  "flask\n\nrequests\n",
+		},
+		{
+			name:  "换行的哈希",
+			input: "six==1.16.0 --hash=sha256:aaaa --hash=sha256:bbbb  # via x\n",
+			want:  "six==1.16.0 \\\n    --hash=sha256:aaaa \\\n    --hash=sha256:bbbb # via x\n",
+		},
+		{
+			name:  "同一行的哈希",
+			input: "six==1.16.0 \\\n  --hash=sha256:aaaa \\\n  --hash=sha256:bbbb\n",
+			opts:  &Options{Hashes: HashesInline},
+			want:  "six==1.16.0 --hash=sha256:aaaa --hash=sha256:bbbb\n",
+		},
+		{
+			name:  "哈希缩进",
+			input: "six==1.16.0 --hash=sha256:aaaa\n",
+			opts:  &Options{Indent: "\t"},
+			want:  "six==1.16.0 \\\n\t--hash=sha256:aaaa\n",
+		},
+		{
+			name:  "指令和URL",
+			input: "-r   base.txt\n--index-url   https://pypi.org/simple\n-e   git+https://github.com/org/lib.git#egg=lib\n",
+			want:  "-r base.txt\n--index-url https://pypi.org/simple\n-e git+https://github.com/org/lib.git#egg=lib\n",
+		},
+		{
+			name:  "选项",
+			input: "pkg  --config-settings a=b\n",
+			want:  "pkg --config-settings=a=b\n",
+		},
+		{
+			name:  "解析时被丢弃的内容保持原样",
+			input: "flask == 1.0 garbage\n",
+			want:  "flask == 1.0 garbage\n",
+		},
+		{
+			name:  "环境标记后的哈希",
+			input: "Django>=3.2 ;python_version>'3' --hash=sha256:abcd\n",
+			want:  "Django>=3.2 ; python_version > \"3\" \\\n    --hash=sha256:abcd\n",
+		},
+		{
+			name:  "无法完整解析的行保持原样",
+			input: "garbage line here ???   \nflask >= 1.0\n",
+			want:  "garbage line here ???\nflask>=1.0\n",
+		},
+		{
+			name:  "保留CRLF和BOM，补上末尾换行",
+			input: "\ufeffflask >= 1.0\r\nrequests",
+			want:  "\ufeffflask>=1.0\r\nrequests\r\n",
+		},
+		{
+			name:  "空文件",
+			input: "\n\n",
+			want:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Format(tt.input, tt.opts)
+			if err != nil {
+				t.Fatalf("Format失败: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Format() =\n%q\n期望\n%q", got, tt.want)
+			}
+
+			// 格式化是幂等的
+			again, err := Format(got, tt.opts)
+			if err != nil {
+				t.Fatalf("Format失败: %v", err)
+			}
+			if again != got {
+				t.Errorf("再次格式化的结果不同:\n%q\n%q", got, again)
+			}
+		})
+	}
+}
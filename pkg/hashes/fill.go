@@ -0,0 +1,233 @@
+package hashes
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/scagogogo/python-requirements-parser/pkg/models"
+)
+
+// Target 可以写入哈希的requirements文档
+//
+// *editor.Document实现了该接口，写入时保留文件的其余格式。
+type Target interface {
+	// Requirements 返回文档中的所有requirement
+	Requirements() []*models.Requirement
+
+	// SetHashesAt 替换Requirements()中第index个requirement的全部哈希
+	SetHashesAt(index int, hashes []string) error
+}
+
+// lineNumberer 能够给出requirement在文档中行号的Target，如*editor.Document
+type lineNumberer interface {
+	LineNumber(req *models.Requirement) int
+}
+
+// Fill 为文档中的requirement写入本地发行文件的哈希
+//
+// 与"pip-compile --generate-hashes"相同，精确固定版本（==或===）的包使用Store中
+// 匹配的全部发行文件的哈希，本地文件（包括file:// URL）使用该文件本身的哈希，
+// 原有的哈希会被替换。哈希没有变化的requirement保持不变。
+//
+// 无法计算哈希的requirement（未固定版本、找不到发行文件、可编辑安装、
+// 远程URL、VCS和目录）会被跳过，并在返回的诊断信息中给出警告。
+//
+// 参数:
+//   - doc: 要写入的文档，如*editor.Document
+//
+// 返回:
+//   - []models.Diagnostic: 被跳过的requirement
+//   - error: 读取发行文件或写入哈希失败时返回错误
+//
+// 示例:
+//
+//	doc, _ := editor.ParseDocument("django==4.2.1\nrequests>=2\n")
+//	warnings, err := store.Fill(doc)
+//	// doc.String() == "django==4.2.1 --hash=sha256:...\nrequests>=2\n"
+//	// warnings[0].Code == "hash-unpinned"
+func (s *Store) Fill(doc Target) ([]models.Diagnostic, error) {
+	var diagnostics []models.Diagnostic
+	for i, req := range doc.Requirements() {
+		paths, diagnostic := s.resolve(req)
+		if diagnostic != nil {
+			diagnostic.Line = lineNumber(doc, req)
+			diagnostics = append(diagnostics, *diagnostic)
+			continue
+		}
+		if len(paths) == 0 {
+			continue
+		}
+
+		hashes, err := s.hashPaths(paths, s.algorithm())
+		if err != nil {
+			return diagnostics, err
+		}
+		if strings.Join(hashes, " ") == strings.Join(req.Hashes, " ") {
+			continue
+		}
+		if err := doc.SetHashesAt(i, hashes); err != nil {
+			return diagnostics, fmt.Errorf("写入 %s 的哈希失败: %w", req.Key(), err)
+		}
+	}
+	return diagnostics, nil
+}
+
+// Verify 用本地发行文件核对requirement中已有的哈希
+//
+// 与pip在--require-hashes模式下的检查一致，每个匹配的发行文件都必须至少与
+// requirement的一个哈希相符，否则pip选中该文件时会拒绝安装。
+// 只检查带有哈希的requirement，使用哈希中出现的算法计算摘要。
+// 诊断信息的行号来自PositionInfo，需要时使用启用了RecordPositions的解析器。
+//
+// 返回的诊断信息：
+//   - "hash-mismatch"（错误）：发行文件与所有哈希都不相符
+//   - "hash-unsupported-algorithm"（警告）：哈希使用了不支持的算法
+//   - 无法核对时与Fill相同的警告，如"hash-artifact-missing"
+//
+// 示例:
+//
+//	reqs, _ := parser.New().ParseFile("requirements.txt")
+//	for _, d := range store.Verify(reqs) {
+//		fmt.Printf("%d: %s\n", d.Line, d.Message)
+//	}
+func (s *Store) Verify(reqs []*models.Requirement) []models.Diagnostic {
+	var diagnostics []models.Diagnostic
+	for _, req := range reqs {
+		if len(req.Hashes) == 0 {
+			continue
+		}
+		for _, diagnostic := range s.verify(req) {
+			diagnostic.Line = requirementLine(req)
+			diagnostics = append(diagnostics, diagnostic)
+		}
+	}
+	return diagnostics
+}
+
+// verify 核对一个requirement的哈希
+func (s *Store) verify(req *models.Requirement) []models.Diagnostic {
+	paths, diagnostic := s.resolve(req)
+	if diagnostic != nil {
+		return []models.Diagnostic{*diagnostic}
+	}
+
+	var diagnostics []models.Diagnostic
+	allowed := make(map[string]bool, len(req.Hashes))
+	algorithms := make(map[string]bool)
+	var used []string
+	for _, hash := range req.Hashes {
+		algorithm, _, _ := strings.Cut(hash, ":")
+		if !Supported(algorithm) {
+			diagnostics = append(diagnostics, warning("hash-unsupported-algorithm",
+				fmt.Sprintf("%s 的哈希使用了不支持的算法: %s", req.Key(), algorithm)))
+			continue
+		}
+		if !algorithms[algorithm] {
+			algorithms[algorithm] = true
+			used = append(used, algorithm)
+		}
+		allowed[hash] = true
+	}
+	if len(used) == 0 {
+		return diagnostics
+	}
+
+	for _, path := range paths {
+		matched := false
+		for _, algorithm := range used {
+			hash, err := s.hash(path, algorithm)
+			if err != nil {
+				diagnostics = append(diagnostics, warning("hash-artifact-missing", err.Error()))
+				matched = true
+				break
+			}
+			if allowed[hash] {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			diagnostics = append(diagnostics, models.Diagnostic{
+				Severity: models.SeverityError,
+				Code:     "hash-mismatch",
+				Message:  fmt.Sprintf("%s 与 %s 的哈希都不相符", path, req.Key()),
+			})
+		}
+	}
+	return diagnostics
+}
+
+// resolve 返回requirement对应的发行文件
+//
+// 不需要哈希的行（注释、选项等）返回nil, nil；无法计算哈希时返回警告。
+func (s *Store) resolve(req *models.Requirement) ([]string, *models.Diagnostic) {
+	if !req.IsInstallable() {
+		return nil, nil
+	}
+	key := req.Key()
+
+	switch {
+	case req.IsEditable:
+		return nil, skipped("hash-editable", fmt.Sprintf("可编辑安装不支持哈希: %s", key))
+
+	case req.IsLocalPath:
+		path := req.ResolvedPath
+		if path == "" {
+			path = req.LocalPath
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, skipped("hash-artifact-missing", fmt.Sprintf("未找到本地文件: %s", path))
+		}
+		if info.IsDir() {
+			return nil, skipped("hash-unsupported", fmt.Sprintf("无法为目录计算哈希: %s", key))
+		}
+		return []string{path}, nil
+
+	case req.IsURL || req.IsVCS:
+		return nil, skipped("hash-unsupported", fmt.Sprintf("无法离线计算远程引用的哈希: %s", key))
+	}
+
+	version, ok := models.PinnedVersion(req.Version)
+	if !ok {
+		return nil, skipped("hash-unpinned", fmt.Sprintf("%s 没有固定到精确版本，无法确定发行文件", key))
+	}
+	artifacts := s.Find(req.Name, version)
+	if len(artifacts) == 0 {
+		return nil, skipped("hash-artifact-missing", fmt.Sprintf("未找到 %s==%s 的发行文件", req.Name, version))
+	}
+
+	paths := make([]string, len(artifacts))
+	for i, artifact := range artifacts {
+		paths[i] = artifact.Path
+	}
+	return paths, nil
+}
+
+// lineNumber 返回requirement在文档中的行号
+func lineNumber(doc Target, req *models.Requirement) int {
+	if numberer, ok := doc.(lineNumberer); ok {
+		return numberer.LineNumber(req)
+	}
+	return requirementLine(req)
+}
+
+// requirementLine 返回解析时记录的行号，没有位置信息时返回0
+func requirementLine(req *models.Requirement) int {
+	if req.PositionInfo != nil {
+		return req.PositionInfo.LineNumber
+	}
+	return 0
+}
+
+// skipped 创建一个跳过requirement的警告
+func skipped(code, message string) *models.Diagnostic {
+	diagnostic := warning(code, message)
+	return &diagnostic
+}
+
+// warning 创建一个警告
+func warning(code, message string) models.Diagnostic {
+	return models.Diagnostic{Severity: models.SeverityWarning, Code: code, Message: message}
+}
@@ -0,0 +1,151 @@
+package hashes
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/scagogogo/python-requirements-parser/pkg/models"
+	"github.com/scagogogo/python-requirements-parser/pkg/parser"
+)
+
+// fakeTarget 记录SetHashesAt调用的Target
+type fakeTarget struct {
+	reqs []*models.Requirement
+	set  map[int][]string
+}
+
+func newFakeTarget(t *testing.T, content string) *fakeTarget {
+	t.Helper()
+	p := parser.New()
+	p.RecordPositions = true
+	reqs, err := p.ParseString(content)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	return &fakeTarget{reqs: reqs, set: map[int][]string{}}
+}
+
+func (f *fakeTarget) Requirements() []*models.Requirement {
+	return f.reqs
+}
+
+func (f *fakeTarget) SetHashesAt(index int, hashes []string) error {
+	if index < 0 || index >= len(f.reqs) {
+		return fmt.Errorf("位置超出范围: %d", index)
+	}
+	f.reqs[index].Hashes = hashes
+	f.set[index] = hashes
+	return nil
+}
+
+// codes 返回诊断信息的"行号:代码"列表
+func codes(diagnostics []models.Diagnostic) string {
+	var result []string
+	for _, d := range diagnostics {
+		result = append(result, fmt.Sprintf("%d:%s", d.Line, d.Code))
+	}
+	return strings.Join(result, ",")
+}
+
+func TestStore_Fill(t *testing.T) {
+	dir := writeArtifacts(t, map[string]string{
+		"Django-4.2.1-py3-none-any.whl": "django",
+		"six-1.16.0.tar.gz":             "six",
+		"local-1.0-py3-none-any.whl":    "local",
+	})
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	localWheel := filepath.ToSlash(filepath.Join(dir, "local-1.0-py3-none-any.whl"))
+	content := "# comment\n" +
+		"django==4.2.1\n" +
+		"six==1.16.0 --hash=sha256:" + strings.TrimPrefix(sha256Of(t, "six"), "sha256:") + "\n" +
+		"requests>=2.31\n" +
+		"flask==2.3.2\n" +
+		"-e ./src\n" +
+		"pkg @ https://example.com/pkg-1.0.tar.gz\n" +
+		"local @ file://" + localWheel + "\n"
+	target := newFakeTarget(t, content)
+
+	diagnostics, err := store.Fill(target)
+	if err != nil {
+		t.Fatalf("Fill失败: %v", err)
+	}
+
+	if got := codes(diagnostics); got != "4:hash-unpinned,5:hash-artifact-missing,6:hash-editable,7:hash-unsupported" {
+		t.Errorf("诊断信息 = %s", got)
+	}
+	for _, d := range diagnostics {
+		if d.Severity != models.SeverityWarning {
+			t.Errorf("跳过的requirement应为警告: %+v", d)
+		}
+	}
+
+	if got := target.set[1]; len(got) != 1 || got[0] != sha256Of(t, "django") {
+		t.Errorf("django的哈希 = %v", got)
+	}
+	if got := target.set[7]; len(got) != 1 || got[0] != sha256Of(t, "local") {
+		t.Errorf("file URL的哈希 = %v", got)
+	}
+	// 哈希没有变化时不写入
+	if _, ok := target.set[2]; ok {
+		t.Errorf("哈希相同的requirement不应被修改")
+	}
+}
+
+func TestStore_FillSameNameMarkers(t *testing.T) {
+	dir := writeArtifacts(t, map[string]string{
+		"numpy-1.0.0-py3-none-any.whl": "numpy 1.0",
+		"numpy-1.1-py3-none-any.whl":   "numpy 1.1",
+	})
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 同一个包按环境标记拆分为多行时，每一行写入各自版本的哈希
+	target := newFakeTarget(t, "numpy==1.0 ; python_version<'3.8'\nnumpy==1.1 ; python_version>='3.8'\n")
+	diagnostics, err := store.Fill(target)
+	if err != nil || len(diagnostics) != 0 {
+		t.Fatalf("Fill = %v, %v", diagnostics, err)
+	}
+	if got := target.set[0]; len(got) != 1 || got[0] != sha256Of(t, "numpy 1.0") {
+		t.Errorf("numpy==1.0的哈希 = %v", got)
+	}
+	if got := target.set[1]; len(got) != 1 || got[0] != sha256Of(t, "numpy 1.1") {
+		t.Errorf("numpy==1.1的哈希 = %v", got)
+	}
+}
+
+func TestStore_Verify(t *testing.T) {
+	dir := writeArtifacts(t, map[string]string{
+		"Django-4.2.1-py3-none-any.whl": "wheel",
+		"django-4.2.1.tar.gz":           "sdist",
+		"six-1.16.0.tar.gz":             "six",
+		"attrs-23.1.0.tar.gz":           "attrs",
+	})
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sha512Six, _ := Digest(strings.NewReader("six"), SHA512)
+
+	content := "django==4.2.1 --hash=" + sha256Of(t, "wheel") + "\n" +
+		"six==1.16.0 --hash=" + sha512Six + " --hash=sha256:0000\n" +
+		"attrs==23.1.0 --hash=md5:abcd\n" +
+		"flask==2.3.2 --hash=sha256:1111\n" +
+		"requests==2.31.0\n"
+	target := newFakeTarget(t, content)
+
+	diagnostics := store.Verify(target.Requirements())
+	if got := codes(diagnostics); got != "1:hash-mismatch,3:hash-unsupported-algorithm,4:hash-artifact-missing" {
+		t.Errorf("诊断信息 = %s", got)
+	}
+	if diagnostics[0].Severity != models.SeverityError || !strings.Contains(diagnostics[0].Message, "django-4.2.1.tar.gz") {
+		t.Errorf("不匹配的发行文件应为错误: %+v", diagnostics[0])
+	}
+}
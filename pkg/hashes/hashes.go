@@ -0,0 +1,127 @@
+// Package hashes 为本地的发行文件计算pip兼容的哈希
+//
+// 该包在不访问网络的情况下完成"pip hash"和"pip-compile --generate-hashes"的工作：
+// 扫描本地目录（或file:// URL）中的wheel和源码包，计算"sha256:..."形式的哈希，
+// 并把哈希写入requirements文档或与文档中已有的哈希进行核对。
+//
+// 示例:
+//
+//	store, err := hashes.NewStore("wheelhouse", "file:///srv/artifact-cache")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	doc, _ := editor.ParseDocument(content)
+//	warnings, err := store.Fill(doc)
+package hashes
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+)
+
+// pip接受的哈希算法（pip/_internal/utils/hashes.py中的STRONG_HASHES）
+const (
+	// SHA256 默认算法，与"pip hash"和pip-compile一致
+	SHA256 = "sha256"
+
+	// SHA384 sha384算法
+	SHA384 = "sha384"
+
+	// SHA512 sha512算法
+	SHA512 = "sha512"
+)
+
+// algorithms 支持的算法及其构造函数
+var algorithms = map[string]func() hash.Hash{
+	SHA256: sha256.New,
+	SHA384: sha512.New384,
+	SHA512: sha512.New,
+}
+
+// Supported 检查algorithm是否为支持的哈希算法
+func Supported(algorithm string) bool {
+	_, ok := algorithms[algorithm]
+	return ok
+}
+
+// Digest 计算r中全部内容的哈希，返回"算法:十六进制摘要"
+//
+// 参数:
+//   - r: 要计算哈希的内容
+//   - algorithm: 哈希算法，SHA256、SHA384或SHA512
+//
+// 返回:
+//   - string: 如"sha256:2c26b46b..."，可直接用于"--hash="选项
+//   - error: 算法不受支持或读取失败时返回错误
+func Digest(r io.Reader, algorithm string) (string, error) {
+	newHash, ok := algorithms[algorithm]
+	if !ok {
+		return "", fmt.Errorf("不支持的哈希算法: %s", algorithm)
+	}
+	h := newHash()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return algorithm + ":" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// File 计算文件的哈希，结果与"pip hash --algorithm"的输出一致
+//
+// 示例:
+//
+//	hash, err := hashes.File("wheelhouse/Django-4.2.1-py3-none-any.whl", hashes.SHA256)
+//	// hash = "sha256:..."
+func File(path, algorithm string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("打开发行文件失败: %w", err)
+	}
+	defer f.Close()
+
+	digest, err := Digest(f, algorithm)
+	if err != nil {
+		return "", fmt.Errorf("计算 %s 的哈希失败: %w", path, err)
+	}
+	return digest, nil
+}
+
+// sdistExtensions 源码包的扩展名
+var sdistExtensions = []string{".tar.gz", ".tar.bz2", ".tar.xz", ".tgz", ".zip"}
+
+// ParseFilename 从发行文件名中解析包名和版本
+//
+// 支持wheel（name-version(-build)?-python-abi-platform.whl）和
+// 源码包（name-version.tar.gz、.tar.bz2、.tar.xz、.tgz、.zip）。
+// 返回的包名保持文件名中的写法，比较时应使用models.NormalizeName。
+//
+// 示例:
+//
+//	ParseFilename("Django-4.2.1-py3-none-any.whl")  // 返回"Django", "4.2.1", true
+//	ParseFilename("python-dateutil-2.8.2.tar.gz")   // 返回"python-dateutil", "2.8.2", true
+//	ParseFilename("README.txt")                     // 返回"", "", false
+func ParseFilename(filename string) (name, version string, ok bool) {
+	if strings.HasSuffix(filename, ".whl") {
+		parts := strings.Split(strings.TrimSuffix(filename, ".whl"), "-")
+		if len(parts) < 5 || parts[0] == "" || parts[1] == "" {
+			return "", "", false
+		}
+		return parts[0], parts[1], true
+	}
+
+	for _, ext := range sdistExtensions {
+		if strings.HasSuffix(filename, ext) {
+			base := strings.TrimSuffix(filename, ext)
+			if i := strings.LastIndexByte(base, '-'); i > 0 && i < len(base)-1 {
+				return base[:i], base[i+1:], true
+			}
+			return "", "", false
+		}
+	}
+	return "", "", false
+}
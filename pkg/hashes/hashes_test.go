@@ -0,0 +1,82 @@
+package hashes
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDigest(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		expected  string
+	}{
+		{SHA256, "sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"},
+		{SHA384, "sha384:98c11ffdfdd540676b1a137cb1a22b2a70350c9a44171d6b1180c6be5cbb2ee3f79d532c8a1dd9ef2e8e08e752a3babb"},
+		{SHA512, "sha512:f7fbba6e0636f890e56fbbf3283e524c6fa3204ae298382d624741d0dc6638326e282c41be5e4254d8820772c5518a2c5a8c0c7f7eda19594a7eb539453e1ed7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			got, err := Digest(strings.NewReader("foo"), tt.algorithm)
+			if err != nil {
+				t.Fatalf("Digest失败: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Digest = %q, 期望 %q", got, tt.expected)
+			}
+			if !Supported(tt.algorithm) {
+				t.Errorf("Supported(%q) = false", tt.algorithm)
+			}
+		})
+	}
+
+	if _, err := Digest(strings.NewReader("foo"), "md5"); err == nil {
+		t.Errorf("不支持的算法应返回错误")
+	}
+	if Supported("md5") {
+		t.Errorf("md5不应被支持")
+	}
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pkg-1.0.tar.gz")
+	if err := os.WriteFile(path, []byte("foo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := File(path, SHA256)
+	if err != nil || got != "sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae" {
+		t.Errorf("File = %q, %v", got, err)
+	}
+	if _, err := File(path+".missing", SHA256); err == nil {
+		t.Errorf("文件不存在时应返回错误")
+	}
+}
+
+func TestParseFilename(t *testing.T) {
+	tests := []struct {
+		filename string
+		name     string
+		version  string
+		ok       bool
+	}{
+		{"Django-4.2.1-py3-none-any.whl", "Django", "4.2.1", true},
+		{"numpy-1.24.2-1-cp311-cp311-manylinux_2_17_x86_64.whl", "numpy", "1.24.2", true},
+		{"python_dateutil-2.8.2-py2.py3-none-any.whl", "python_dateutil", "2.8.2", true},
+		{"python-dateutil-2.8.2.tar.gz", "python-dateutil", "2.8.2", true},
+		{"pkg-1.0.zip", "pkg", "1.0", true},
+		{"pkg-1.0.tgz", "pkg", "1.0", true},
+		{"broken.whl", "", "", false},
+		{"noversion.tar.gz", "", "", false},
+		{"notes.txt", "", "", false},
+	}
+
+	for _, tt := range tests {
+		name, version, ok := ParseFilename(tt.filename)
+		if name != tt.name || version != tt.version || ok != tt.ok {
+			t.Errorf("ParseFilename(%q) = %q, %q, %v", tt.filename, name, version, ok)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package hashes
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/scagogogo/python-requirements-parser/pkg/models"
+)
+
+// Artifact 一个本地发行文件
+type Artifact struct {
+	// Name 文件名中的包名，如"Django"
+	Name string
+
+	// Version 文件名中的版本，如"4.2.1"
+	Version string
+
+	// Filename 文件名，如"Django-4.2.1-py3-none-any.whl"
+	Filename string
+
+	// Path 文件路径
+	Path string
+}
+
+// Scan 列出location中的发行文件
+//
+// location可以是本地目录、单个发行文件，或指向它们的file:// URL。
+// 目录只扫描第一层，文件名不符合wheel或源码包命名规则的文件会被忽略；
+// location直接指向的文件必须符合命名规则。
+//
+// 示例:
+//
+//	artifacts, err := hashes.Scan("file:///srv/wheelhouse")
+func Scan(location string) ([]Artifact, error) {
+	path, err := localPath(location)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取发行文件位置失败: %w", err)
+	}
+	if !info.IsDir() {
+		artifact, ok := newArtifact(path)
+		if !ok {
+			return nil, fmt.Errorf("无法从文件名识别包名和版本: %s", path)
+		}
+		return []Artifact{artifact}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取发行文件目录失败: %w", err)
+	}
+	var artifacts []Artifact
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if artifact, ok := newArtifact(filepath.Join(path, entry.Name())); ok {
+			artifacts = append(artifacts, artifact)
+		}
+	}
+	return artifacts, nil
+}
+
+// newArtifact 根据文件名创建Artifact
+func newArtifact(path string) (Artifact, bool) {
+	filename := filepath.Base(path)
+	name, version, ok := ParseFilename(filename)
+	return Artifact{Name: name, Version: version, Filename: filename, Path: path}, ok
+}
+
+// localPath 将本地路径或file:// URL转换为本地路径
+func localPath(location string) (string, error) {
+	if !strings.HasPrefix(strings.ToLower(location), "file:") {
+		return location, nil
+	}
+
+	u, err := url.Parse(location)
+	if err != nil {
+		return "", fmt.Errorf("无效的file URL: %s", location)
+	}
+	path := u.Path
+	if u.Opaque != "" {
+		// file:relative/path
+		if path, err = url.PathUnescape(u.Opaque); err != nil {
+			return "", fmt.Errorf("无效的file URL: %s", location)
+		}
+	}
+	// file:///C:/path 形式的Windows路径
+	if len(path) >= 3 && path[0] == '/' && path[2] == ':' {
+		path = path[1:]
+	}
+	if u.Host != "" && !strings.EqualFold(u.Host, "localhost") {
+		path = "//" + u.Host + path
+	}
+	return path, nil
+}
+
+// Store 一组本地发行文件，按包名和版本查找并计算哈希
+//
+// Store实现了editor.HashProvider，可以直接用于HashPolicyProvider：
+//
+//	store, _ := hashes.NewStore("wheelhouse")
+//	e := editor.NewPositionAwareEditor()
+//	e.HashPolicy = editor.HashPolicyProvider
+//	e.HashProvider = store
+//
+// 计算过的哈希会被缓存。Store不是并发安全的。
+type Store struct {
+	// Algorithm 计算哈希使用的算法，为空时使用SHA256
+	Algorithm string
+
+	artifacts []Artifact
+	cache     map[string]string
+}
+
+// NewStore 扫描locations中的发行文件并创建Store
+//
+// 参数:
+//   - locations: 本地目录、发行文件或file:// URL，见Scan
+//
+// 返回:
+//   - *Store: 包含所有发行文件的Store，按文件名排序
+//   - error: 任一位置无法读取时返回错误
+func NewStore(locations ...string) (*Store, error) {
+	store := &Store{}
+	for _, location := range locations {
+		artifacts, err := Scan(location)
+		if err != nil {
+			return nil, err
+		}
+		store.artifacts = append(store.artifacts, artifacts...)
+	}
+	sort.SliceStable(store.artifacts, func(i, j int) bool {
+		return store.artifacts[i].Filename < store.artifacts[j].Filename
+	})
+	return store, nil
+}
+
+// Artifacts 返回Store中的所有发行文件
+func (s *Store) Artifacts() []Artifact {
+	return append([]Artifact(nil), s.artifacts...)
+}
+
+// Find 返回包name的version版本的所有发行文件
+//
+// 包名按PEP 503规范化后比较。版本与pip的"=="相同按PEP 440比较，因此"1.0"匹配
+// 文件名中的"1.0.0"；version没有本地版本标签时匹配任意标签。无法按PEP 440
+// 解析的版本不区分大小写地比较原文。
+func (s *Store) Find(name, version string) []Artifact {
+	key := models.NormalizeName(name)
+	var result []Artifact
+	for _, artifact := range s.artifacts {
+		if models.NormalizeName(artifact.Name) == key && versionMatches(version, artifact.Version) {
+			result = append(result, artifact)
+		}
+	}
+	return result
+}
+
+// versionMatches 检查发行文件的版本candidate是否满足"==version"
+func versionMatches(version, candidate string) bool {
+	want, ok1 := models.ParseVersion(version)
+	got, ok2 := models.ParseVersion(candidate)
+	if !ok1 || !ok2 {
+		return strings.EqualFold(version, candidate)
+	}
+	return want.Compare(got) == 0 && (want.Local() == "" || want.Local() == got.Local())
+}
+
+// Hashes 返回包name的version版本所有发行文件的哈希，按字典序排列
+//
+// 找不到发行文件时返回错误。
+func (s *Store) Hashes(name, version string) ([]string, error) {
+	artifacts := s.Find(name, version)
+	if len(artifacts) == 0 {
+		return nil, fmt.Errorf("未找到 %s==%s 的发行文件", name, version)
+	}
+
+	paths := make([]string, len(artifacts))
+	for i, artifact := range artifacts {
+		paths[i] = artifact.Path
+	}
+	return s.hashPaths(paths, s.algorithm())
+}
+
+// hashPaths 计算paths中每个文件的哈希，返回排序去重后的结果
+func (s *Store) hashPaths(paths []string, algorithm string) ([]string, error) {
+	var result []string
+	for _, path := range paths {
+		hash, err := s.hash(path, algorithm)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, hash)
+	}
+	sort.Strings(result)
+
+	unique := result[:0]
+	for i, hash := range result {
+		if i == 0 || hash != result[i-1] {
+			unique = append(unique, hash)
+		}
+	}
+	return unique, nil
+}
+
+// hash 计算文件的哈希，结果按路径和算法缓存
+func (s *Store) hash(path, algorithm string) (string, error) {
+	key := algorithm + "\x00" + path
+	if hash, ok := s.cache[key]; ok {
+		return hash, nil
+	}
+	hash, err := File(path, algorithm)
+	if err != nil {
+		return "", err
+	}
+	if s.cache == nil {
+		s.cache = make(map[string]string)
+	}
+	s.cache[key] = hash
+	return hash, nil
+}
+
+// algorithm 返回实际使用的哈希算法
+func (s *Store) algorithm() string {
+	if s.Algorithm == "" {
+		return SHA256
+	}
+	return s.Algorithm
+}
@@ -0,0 +1,145 @@
+package hashes
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeArtifacts 在临时目录中创建发行文件，返回目录路径
+func writeArtifacts(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// sha256Of 返回data的sha256哈希
+func sha256Of(t *testing.T, data string) string {
+	t.Helper()
+	hash, err := Digest(strings.NewReader(data), SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
+
+func TestScan(t *testing.T) {
+	dir := writeArtifacts(t, map[string]string{
+		"Django-4.2.1-py3-none-any.whl": "wheel",
+		"django-4.2.1.tar.gz":           "sdist",
+		"README.txt":                    "ignored",
+	})
+	if err := os.Mkdir(filepath.Join(dir, "sub-1.0.zip"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		location string
+		expected int
+		wantErr  bool
+	}{
+		{"目录", dir, 2, false},
+		{"file URL", "file://" + filepath.ToSlash(dir), 2, false},
+		{"单个文件", filepath.Join(dir, "django-4.2.1.tar.gz"), 1, false},
+		{"单个文件的file URL", "file://" + filepath.ToSlash(filepath.Join(dir, "django-4.2.1.tar.gz")), 1, false},
+		{"文件名无法识别", filepath.Join(dir, "README.txt"), 0, true},
+		{"不存在", filepath.Join(dir, "missing"), 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			artifacts, err := Scan(tt.location)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Scan错误 = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(artifacts) != tt.expected {
+				t.Errorf("发行文件数量 = %d, 期望 %d: %+v", len(artifacts), tt.expected, artifacts)
+			}
+		})
+	}
+}
+
+func TestLocalPath(t *testing.T) {
+	tests := []struct {
+		location string
+		expected string
+	}{
+		{"/srv/wheelhouse", "/srv/wheelhouse"},
+		{"file:///srv/wheel%20house", "/srv/wheel house"},
+		{"file://localhost/srv/wheelhouse", "/srv/wheelhouse"},
+		{"file:///C:/wheels", "C:/wheels"},
+		{"file://server/share/wheels", "//server/share/wheels"},
+		{"file:wheels", "wheels"},
+	}
+
+	for _, tt := range tests {
+		got, err := localPath(tt.location)
+		if err != nil || got != tt.expected {
+			t.Errorf("localPath(%q) = %q, %v, 期望 %q", tt.location, got, err, tt.expected)
+		}
+	}
+}
+
+func TestStore(t *testing.T) {
+	wheels := writeArtifacts(t, map[string]string{
+		"Django-4.2.1-py3-none-any.whl": "wheel",
+		"Django-4.2.0-py3-none-any.whl": "old",
+	})
+	sdists := writeArtifacts(t, map[string]string{
+		"django-4.2.1.tar.gz":                        "sdist",
+		"python_dateutil-2.8.2-py2.py3-none-any.whl": "dateutil",
+	})
+
+	store, err := NewStore(wheels, "file://"+filepath.ToSlash(sdists))
+	if err != nil {
+		t.Fatalf("NewStore失败: %v", err)
+	}
+	if len(store.Artifacts()) != 4 {
+		t.Errorf("Artifacts = %+v", store.Artifacts())
+	}
+
+	// 多个位置中的发行文件合并计算，结果按字典序排列
+	hashes, err := store.Hashes("django", "4.2.1")
+	if err != nil {
+		t.Fatalf("Hashes失败: %v", err)
+	}
+	want := []string{sha256Of(t, "wheel"), sha256Of(t, "sdist")}
+	if want[0] > want[1] {
+		want[0], want[1] = want[1], want[0]
+	}
+	if strings.Join(hashes, ",") != strings.Join(want, ",") {
+		t.Errorf("hashes = %v, 期望 %v", hashes, want)
+	}
+
+	// 包名按PEP 503规范化后比较
+	if found := store.Find("Python-DateUtil", "2.8.2"); len(found) != 1 || found[0].Filename != "python_dateutil-2.8.2-py2.py3-none-any.whl" {
+		t.Errorf("Find = %+v", found)
+	}
+	// 版本按PEP 440比较，指定本地版本标签时必须相同
+	for version, want := range map[string]int{"4.2.1.0": 2, "V4.2.1": 2, "4.2.1+local": 0, "4.2": 1} {
+		if found := store.Find("django", version); len(found) != want {
+			t.Errorf("Find(%q) = %+v, 期望 %d个", version, found, want)
+		}
+	}
+	if _, err := store.Hashes("django", "5.0"); err == nil {
+		t.Errorf("找不到发行文件时应返回错误")
+	}
+
+	// 使用其他算法
+	store.Algorithm = SHA512
+	hashes, err = store.Hashes("django", "4.2.0")
+	if err != nil || len(hashes) != 1 || !strings.HasPrefix(hashes[0], "sha512:") {
+		t.Errorf("sha512 hashes = %v, %v", hashes, err)
+	}
+
+	if _, err := NewStore(filepath.Join(wheels, "missing")); err == nil {
+		t.Errorf("位置不存在时应返回错误")
+	}
+}
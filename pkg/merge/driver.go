@@ -0,0 +1,65 @@
+package merge
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/scagogogo/python-requirements-parser/pkg/parser"
+)
+
+// MergeFiles 按git合并驱动的约定合并三个文件
+//
+// 合并结果（包括冲突标记）写回ours文件，调用方根据Result.Clean决定退出码：
+// git要求合并驱动在没有冲突时以0退出，有冲突时以非0退出。
+// 三个文件的编码各自按pip的规则由BOM或PEP 263编码声明检测（见parser.Decode），
+// 合并结果按ours的编码和BOM状态写回。
+//
+// 参数:
+//   - base: 共同祖先的文件路径（git的%O）
+//   - ours: 当前分支的文件路径（git的%A），合并结果写回该文件
+//   - theirs: 要合并的分支的文件路径（git的%B）
+//   - opts: 选项，为nil时使用默认值
+//
+// 返回:
+//   - *Result: 合并结果
+//   - error: 文件读写、解码或解析失败时的错误
+//
+// 示例（.git/config和.gitattributes）:
+//
+//	[merge "requirements"]
+//	    name = requirements.txt three-way merge
+//	    driver = requirements-merge -marker-size %L %O %A %B
+//
+//	requirements*.txt merge=requirements
+func MergeFiles(base, ours, theirs string, opts *Options) (*Result, error) {
+	var texts [3]string
+	var encodings [3]parser.Encoding
+	for i, path := range []string{base, ours, theirs} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取文件失败: %w", err)
+		}
+		if texts[i], encodings[i], err = parser.Decode(data); err != nil {
+			return nil, fmt.Errorf("解码文件 %s 失败: %w", path, err)
+		}
+	}
+
+	result, err := ThreeWay(texts[0], texts[1], texts[2], opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// 合并结果按ours的编码和BOM状态写回
+	data, err := parser.Encode(result.Text, encodings[1])
+	if err != nil {
+		return nil, fmt.Errorf("编码合并结果失败: %w", err)
+	}
+	info, err := os.Stat(ours)
+	if err != nil {
+		return nil, fmt.Errorf("读取文件失败: %w", err)
+	}
+	if err := os.WriteFile(ours, data, info.Mode().Perm()); err != nil {
+		return nil, fmt.Errorf("写入合并结果失败: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,88 @@
+package merge
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/scagogogo/python-requirements-parser/pkg/parser"
+)
+
+func TestMergeFiles(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	base := write("base", "flask==1.0\nsix\n")
+	ours := write("ours", "flask==1.0\nsix==1.16\n")
+	theirs := write("theirs", "flask==2.0\nsix\n")
+
+	result, err := MergeFiles(base, ours, theirs, nil)
+	if err != nil {
+		t.Fatalf("MergeFiles失败: %v", err)
+	}
+	if !result.Clean() {
+		t.Errorf("期望没有冲突: %+v", result.Conflicts)
+	}
+
+	data, err := os.ReadFile(ours)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "flask==2.0\nsix==1.16\n" {
+		t.Errorf("ours文件内容 = %q", data)
+	}
+
+	if _, err := MergeFiles(filepath.Join(dir, "missing"), ours, theirs, nil); err == nil {
+		t.Error("期望文件不存在的错误")
+	}
+}
+
+func TestMergeFilesEncoding(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, text string, enc parser.Encoding) string {
+		data, err := parser.Encode(text, enc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	utf16 := parser.Encoding{Name: "utf-16-le", BOM: true}
+	utf8BOM := parser.Encoding{Name: "utf-8", BOM: true}
+
+	// ours为UTF-16，base和theirs使用其他编码
+	base := write("base", "flask==1.0\nsix\n", utf8BOM)
+	ours := write("ours", "flask==1.0\nsix==1.16\n", utf16)
+	theirs := write("theirs", "flask==2.0\nsix\n", parser.Encoding{Name: "utf-8"})
+
+	result, err := MergeFiles(base, ours, theirs, nil)
+	if err != nil {
+		t.Fatalf("MergeFiles失败: %v", err)
+	}
+	if !result.Clean() {
+		t.Errorf("期望没有冲突: %+v", result.Conflicts)
+	}
+
+	data, err := os.ReadFile(ours)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, enc, err := parser.Decode(data)
+	if err != nil {
+		t.Fatalf("解码合并结果失败: %v", err)
+	}
+	if text != "flask==2.0\nsix==1.16\n" {
+		t.Errorf("合并结果 = %q", text)
+	}
+	if enc != utf16 {
+		t.Errorf("合并结果的编码 = %+v, 期望 %+v", enc, utf16)
+	}
+}
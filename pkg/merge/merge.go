@@ -0,0 +1,486 @@
+// Package merge 按requirement进行requirements文件的三方合并
+//
+// 与git按行合并不同，ThreeWay按包匹配三个版本中的依赖：双方修改不同的包、
+// 或者把同一个包改成相同的内容时自动合并；双方把同一个包改为不同版本时，
+// 可以按VersionPolicy选择一方；其余的真正冲突以冲突标记写入结果，
+// 同时以Conflict结构返回。MergeFiles遵循git合并驱动的约定，可以直接作为合并驱动使用。
+//
+// 示例:
+//
+//	result, err := merge.ThreeWay(base, ours, theirs, &merge.Options{VersionPolicy: merge.VersionHigher})
+//	if err != nil {
+//	    // 处理错误
+//	}
+//	if !result.Clean() {
+//	    for _, c := range result.Conflicts {
+//	        fmt.Printf("第%d行: %s\n", c.Line, c.Package)
+//	    }
+//	}
+//	fmt.Print(result.Text)
+package merge
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/scagogogo/python-requirements-parser/pkg/cst"
+	"github.com/scagogogo/python-requirements-parser/pkg/models"
+	"github.com/scagogogo/python-requirements-parser/pkg/parser"
+)
+
+// VersionPolicy 双方把同一个包改为不同版本时的处理方式
+type VersionPolicy int
+
+const (
+	// VersionConflict 报告冲突（默认）
+	VersionConflict VersionPolicy = iota
+
+	// VersionHigher 选择版本较高的一方，版本相同或无法比较时报告冲突
+	VersionHigher
+
+	// VersionOurs 选择ours
+	VersionOurs
+
+	// VersionTheirs 选择theirs
+	VersionTheirs
+)
+
+// DefaultMarkerSize 默认的冲突标记长度，与git一致
+const DefaultMarkerSize = 7
+
+// Options 三方合并的选项
+type Options struct {
+	// VersionPolicy 双方只修改了同一个包的版本约束（及其哈希）且结果不同时的处理方式
+	VersionPolicy VersionPolicy
+
+	// OursLabel、BaseLabel、TheirsLabel 冲突标记中的名称，默认为"ours"、"base"、"theirs"
+	OursLabel, BaseLabel, TheirsLabel string
+
+	// MarkerSize 冲突标记的长度，不大于0时为DefaultMarkerSize
+	MarkerSize int
+
+	// Diff3 冲突中同时写出base的内容（"|||||||"段），与git的merge.conflictStyle=diff3相同
+	Diff3 bool
+}
+
+// Conflict 无法自动合并的一个requirement
+type Conflict struct {
+	// Package 冲突的包：规范化的包名，没有包名的依赖为其URL或路径，指令为"-r file"、"-c file"或选项名
+	Package string `json:"package"`
+
+	// Base、Ours、Theirs 三个版本中的行，不存在（被删除或未添加）时为空字符串
+	Base   string `json:"base"`
+	Ours   string `json:"ours"`
+	Theirs string `json:"theirs"`
+
+	// Line 冲突标记在合并结果中的起始行号（从1开始）
+	Line int `json:"line"`
+}
+
+// Result 三方合并的结果
+type Result struct {
+	// Text 合并后的文本，包含冲突标记
+	Text string
+
+	// Conflicts 按在Text中出现的顺序排列的冲突
+	Conflicts []Conflict
+}
+
+// Clean 检查合并是否没有冲突
+func (r *Result) Clean() bool {
+	return len(r.Conflicts) == 0
+}
+
+// entry 文件中的一个逻辑行
+type entry struct {
+	// key 匹配键，注释和空行为空
+	key string
+
+	// label 冲突中显示的名称
+	label string
+
+	text    string
+	newline string
+	req     *models.Requirement
+}
+
+// parseEntries 将文本解析为逻辑行
+//
+// 同一个键出现多次时，第n次出现（n>1）的键带有序号，按出现顺序与其他版本匹配。
+func parseEntries(p *parser.Parser, text string) ([]*entry, error) {
+	file := cst.Parse(text)
+	entries := make([]*entry, 0, len(file.Lines))
+	seen := make(map[string]int)
+
+	for _, line := range file.Lines {
+		e := &entry{text: line.Text(), newline: line.Newline}
+		reqs, err := p.ParseString(e.text)
+		if err != nil {
+			return nil, err
+		}
+		if len(reqs) == 1 {
+			e.req = reqs[0]
+			e.label = key(e.req)
+		}
+		if e.label != "" {
+			seen[e.label]++
+			e.key = e.label
+			if n := seen[e.label]; n > 1 {
+				e.key = fmt.Sprintf("%s#%d", e.label, n)
+			}
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// key 返回requirement的匹配键，注释和空行返回空字符串
+func key(req *models.Requirement) string {
+	switch {
+	case req.IsTrivia():
+		return ""
+	case req.IsFileRef:
+		return "-r " + req.FileRef
+	case req.IsConstraint:
+		return "-c " + req.ConstraintFile
+	case len(req.GlobalOptions) > 0:
+		names := make([]string, 0, len(req.GlobalOptions))
+		for name := range req.GlobalOptions {
+			names = append(names, "--"+strings.TrimLeft(name, "-"))
+		}
+		sort.Strings(names)
+		return strings.Join(names, " ")
+	case req.Name != "":
+		return models.NormalizeName(req.Name)
+	case req.IsLocalPath && req.LocalPath != "":
+		return req.LocalPath
+	}
+	return req.URL
+}
+
+// index 按键建立逻辑行的索引
+func index(entries []*entry) map[string]*entry {
+	m := make(map[string]*entry, len(entries))
+	for _, e := range entries {
+		if e.key != "" {
+			m[e.key] = e
+		}
+	}
+	return m
+}
+
+// ThreeWay 对requirements文件进行三方合并
+//
+// 每个依赖和指令（-r、-c、全局选项）按键匹配：只有一方修改时采用该方的内容；
+// 双方修改相同，或者修改后在语义上等价（models.SemanticEqual）时采用ours；
+// 否则，如果双方只修改了版本约束（及其哈希），按opts.VersionPolicy处理，
+// 仍无法决定的作为冲突写出。"修改"包括删除和添加，因此一方删除、另一方修改同一个包也是冲突。
+//
+// 结果的布局、注释、空行、行终止符和BOM以ours为准，theirs新增的行插入到其在theirs中前一个依赖之后。
+// theirs对注释和空行的修改不会被合并。
+//
+// 参数:
+//   - base: 共同祖先的文本
+//   - ours: 当前分支的文本
+//   - theirs: 要合并的分支的文本
+//   - opts: 选项，为nil时使用默认值
+//
+// 返回:
+//   - *Result: 合并后的文本和冲突列表
+//   - error: 解析失败时的错误
+//
+// 示例:
+//
+//	base := "flask==1.0\nrequests==2.0\n"
+//	ours := "flask==1.1\nrequests==2.0\n"
+//	theirs := "flask==1.0\nrequests==2.1\nhttpx\n"
+//	result, _ := merge.ThreeWay(base, ours, theirs, nil)
+//	// result.Text == "flask==1.1\nrequests==2.1\nhttpx\n"
+func ThreeWay(base, ours, theirs string, opts *Options) (*Result, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	bom := ""
+	if strings.HasPrefix(ours, "\ufeff") {
+		bom = "\ufeff"
+	}
+
+	p := parser.New()
+	var sides [3][]*entry
+	for i, text := range []string{base, ours, theirs} {
+		entries, err := parseEntries(p, strings.TrimPrefix(text, "\ufeff"))
+		if err != nil {
+			return nil, fmt.Errorf("解析requirements文件失败: %w", err)
+		}
+		sides[i] = entries
+	}
+
+	m := &merger{opts: opts, base: index(sides[0]), ours: index(sides[1]), theirs: index(sides[2])}
+	blocks := m.merge(sides[1], sides[2])
+	return render(blocks, bom, sides[1], opts), nil
+}
+
+// merger 保存合并过程中的状态
+type merger struct {
+	opts               *Options
+	base, ours, theirs map[string]*entry
+}
+
+// block 合并结果中的一个逻辑行或冲突
+type block struct {
+	key      string
+	text     string
+	newline  string
+	conflict *Conflict
+}
+
+// merge 按ours的顺序输出合并结果，再插入只在theirs中出现的行
+func (m *merger) merge(ours, theirs []*entry) []*block {
+	var blocks []*block
+	for _, e := range ours {
+		if e.key == "" {
+			blocks = append(blocks, &block{text: e.text, newline: e.newline})
+			continue
+		}
+		if b := m.resolve(e.key); b != nil {
+			b.newline = e.newline
+			blocks = append(blocks, b)
+		}
+	}
+
+	for i, e := range theirs {
+		if e.key == "" || m.ours[e.key] != nil {
+			continue
+		}
+		b := m.resolve(e.key)
+		if b == nil {
+			continue
+		}
+		blocks = insertBlock(blocks, b, theirs[:i], theirs[i+1:])
+	}
+	return blocks
+}
+
+// insertBlock 把只在theirs中出现的行插入到其在theirs中前一个已输出的行之后，
+// 没有时插入到后一个已输出的行之前，都没有时追加到末尾
+func insertBlock(blocks []*block, b *block, before, after []*entry) []*block {
+	position := func(key string) int {
+		for i, existing := range blocks {
+			if key != "" && existing.key == key {
+				return i
+			}
+		}
+		return -1
+	}
+
+	target := len(blocks)
+	found := false
+	for i := len(before) - 1; i >= 0 && !found; i-- {
+		if pos := position(before[i].key); pos != -1 {
+			target, found = pos+1, true
+		}
+	}
+	for i := 0; i < len(after) && !found; i++ {
+		if pos := position(after[i].key); pos != -1 {
+			target, found = pos, true
+		}
+	}
+
+	blocks = append(blocks, nil)
+	copy(blocks[target+1:], blocks[target:])
+	blocks[target] = b
+	return blocks
+}
+
+// resolve 合并一个键在三个版本中的行，结果为删除时返回nil
+func (m *merger) resolve(k string) *block {
+	b, o, t := m.base[k], m.ours[k], m.theirs[k]
+
+	var picked *entry
+	switch {
+	case sameText(o, b):
+		picked = t
+	case sameText(t, b), sameText(o, t), equivalent(o, t):
+		picked = o
+	default:
+		var ok bool
+		if picked, ok = m.pickVersion(o, t); !ok {
+			return m.conflict(k, b, o, t)
+		}
+	}
+
+	if picked == nil {
+		return nil
+	}
+	return &block{key: k, text: picked.text, newline: picked.newline}
+}
+
+// pickVersion 按VersionPolicy在双方只修改了版本约束时选择一方
+func (m *merger) pickVersion(o, t *entry) (*entry, bool) {
+	if m.opts.VersionPolicy == VersionConflict || o == nil || t == nil || !onlyVersionDiffers(o.req, t.req) {
+		return nil, false
+	}
+
+	switch m.opts.VersionPolicy {
+	case VersionOurs:
+		return o, true
+	case VersionTheirs:
+		return t, true
+	case VersionHigher:
+		ov, ok1 := specifierVersion(o.req.Version)
+		tv, ok2 := specifierVersion(t.req.Version)
+		if !ok1 || !ok2 {
+			return nil, false
+		}
+		switch ov.Compare(tv) {
+		case 1:
+			return o, true
+		case -1:
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// conflict 创建冲突块
+func (m *merger) conflict(k string, b, o, t *entry) *block {
+	label := k
+	for _, e := range []*entry{o, t, b} {
+		if e != nil {
+			label = e.label
+			break
+		}
+	}
+	c := &Conflict{Package: label, Base: textOf(b), Ours: textOf(o), Theirs: textOf(t)}
+	return &block{key: k, conflict: c}
+}
+
+// sameText 检查两个版本中的行是否完全相同，都不存在也视为相同
+func sameText(a, b *entry) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.text == b.text
+}
+
+// equivalent 检查两个版本中的行对pip而言是否等价
+func equivalent(a, b *entry) bool {
+	return a != nil && b != nil && a.req.SemanticEqual(b.req)
+}
+
+// onlyVersionDiffers 检查两个requirement是否只有版本约束和哈希不同
+func onlyVersionDiffers(a, b *models.Requirement) bool {
+	if a.Name == "" || a.Version == "" || b.Version == "" {
+		return false
+	}
+	x, y := a.Clone(), b.Clone()
+	x.Version, y.Version = "", ""
+	x.Hashes, y.Hashes = nil, nil
+	return x.SemanticEqual(y)
+}
+
+// textOf 返回行的文本，不存在时为空字符串
+func textOf(e *entry) string {
+	if e == nil {
+		return ""
+	}
+	return e.text
+}
+
+// render 输出合并结果并记录冲突的行号
+func render(blocks []*block, bom string, ours []*entry, opts *Options) *Result {
+	ending, final := "\n", "\n"
+	for _, e := range ours {
+		if e.newline != "" {
+			ending = e.newline
+			break
+		}
+	}
+	if len(ours) > 0 {
+		final = ours[len(ours)-1].newline
+	}
+
+	size := opts.MarkerSize
+	if size <= 0 {
+		size = DefaultMarkerSize
+	}
+	marker := func(c byte, label string) string {
+		s := strings.Repeat(string(c), size)
+		if label != "" {
+			s += " " + label
+		}
+		return s + ending
+	}
+	labelOr := func(label, def string) string {
+		if label == "" {
+			return def
+		}
+		return label
+	}
+
+	result := &Result{}
+	var sb strings.Builder
+	sb.WriteString(bom)
+	line := 1
+	for i, b := range blocks {
+		newline := b.newline
+		if i == len(blocks)-1 {
+			newline = final
+		} else if newline == "" {
+			newline = ending
+		}
+
+		if b.conflict == nil {
+			sb.WriteString(b.text)
+			sb.WriteString(newline)
+			line += strings.Count(b.text, "\n") + 1
+			continue
+		}
+
+		c := *b.conflict
+		c.Line = line
+		result.Conflicts = append(result.Conflicts, c)
+
+		var cb strings.Builder
+		section := func(text string) {
+			if text != "" {
+				cb.WriteString(text)
+				cb.WriteString(ending)
+			}
+		}
+		cb.WriteString(marker('<', labelOr(opts.OursLabel, "ours")))
+		section(c.Ours)
+		if opts.Diff3 {
+			cb.WriteString(marker('|', labelOr(opts.BaseLabel, "base")))
+			section(c.Base)
+		}
+		cb.WriteString(marker('=', ""))
+		section(c.Theirs)
+		cb.WriteString(strings.TrimSuffix(marker('>', labelOr(opts.TheirsLabel, "theirs")), ending))
+		cb.WriteString(newline)
+
+		text := cb.String()
+		sb.WriteString(text)
+		line += strings.Count(text, "\n")
+	}
+
+	result.Text = sb.String()
+	return result
+}
+
+// specifierVersion 返回版本约束所指的版本：精确版本或下界
+//
+// 取第一个"=="、"==="、"~="、">="或">"子句中的版本，没有这样的子句或版本无法解析时返回false。
+func specifierVersion(spec string) (models.Version, bool) {
+	for _, clause := range strings.Split(spec, ",") {
+		clause = strings.TrimSpace(clause)
+		for _, op := range []string{"===", "==", "~=", ">=", ">"} {
+			if strings.HasPrefix(clause, op) {
+				return models.ParseVersion(clause[len(op):])
+			}
+		}
+	}
+	return models.Version{}, false
+}
@@ -0,0 +1,209 @@
+package merge
+
+import (
+	"testing"
+
+	"github.com/scagogogo/python-requirements-parser/pkg/models"
+)
+
+func TestThreeWay(t *testing.T) {
+	tests := []struct {
+		name      string
+		base      string
+		ours      string
+		theirs    string
+		opts      *Options
+		want      string
+		conflicts int
+	}{
+		{
+			name:   "不同的包",
+			base:   "flask==1.0\nrequests==2.0\n",
+			ours:   "flask==1.1\nrequests==2.0\n",
+			theirs: "flask==1.0\nrequests==2.1\n",
+			want:   "flask==1.1\nrequests==2.1\n",
+		},
+		{
+			name:   "相同的修改",
+			base:   "flask==1.0\n",
+			ours:   "flask==2.0\n",
+			theirs: "flask==2.0\n",
+			want:   "flask==2.0\n",
+		},
+		{
+			name:   "语义相同的修改采用ours",
+			base:   "flask==1.0\n",
+			ours:   "Flask == 2.0  # web\n",
+			theirs: "flask==2.0\n",
+			want:   "Flask == 2.0  # web\n",
+		},
+		{
+			name:   "theirs新增的包放在前一个包之后",
+			base:   "# deps\nflask==1.0\nsix\n",
+			ours:   "# deps\nflask==1.0\nsix\nattrs\n",
+			theirs: "# deps\nflask==1.0\nhttpx\nsix\n",
+			want:   "# deps\nflask==1.0\nhttpx\nsix\nattrs\n",
+		},
+		{
+			name:   "双方都删除",
+			base:   "flask\nsix\n",
+			ours:   "flask\n",
+			theirs: "flask\n",
+			want:   "flask\n",
+		},
+		{
+			name:   "theirs删除，ours未修改",
+			base:   "flask\nsix\n",
+			ours:   "flask\nsix\n",
+			theirs: "flask\n",
+			want:   "flask\n",
+		},
+		{
+			name:   "ours以注释和空行为准",
+			base:   "flask\n",
+			ours:   "# ours\n\nflask\n",
+			theirs: "# theirs\nflask==2.0\n",
+			want:   "# ours\n\nflask==2.0\n",
+		},
+		{
+			name:   "指令按键合并",
+			base:   "--index-url https://pypi.org/simple\n-r base.txt\nflask\n",
+			ours:   "--index-url https://pypi.org/simple\n-r base.txt\n-r dev.txt\nflask\n",
+			theirs: "--index-url https://mirror.example/simple\n-r base.txt\nflask\n",
+			want:   "--index-url https://mirror.example/simple\n-r base.txt\n-r dev.txt\nflask\n",
+		},
+		{
+			name:   "重复的包按出现顺序匹配",
+			base:   "numpy==1.21 ; python_version < '3.8'\nnumpy==1.24 ; python_version >= '3.8'\n",
+			ours:   "numpy==1.21 ; python_version < '3.8'\nnumpy==1.25 ; python_version >= '3.8'\n",
+			theirs: "numpy==1.22 ; python_version < '3.8'\nnumpy==1.24 ; python_version >= '3.8'\n",
+			want:   "numpy==1.22 ; python_version < '3.8'\nnumpy==1.25 ; python_version >= '3.8'\n",
+		},
+		{
+			name:      "版本冲突",
+			base:      "flask==1.0\nsix\n",
+			ours:      "flask==2.0\nsix\n",
+			theirs:    "flask==2.1\nsix\n",
+			want:      "<<<<<<< ours\nflask==2.0\n=======\nflask==2.1\n>>>>>>> theirs\nsix\n",
+			conflicts: 1,
+		},
+		{
+			name:   "选择较高的版本",
+			base:   "flask==1.0 --hash=sha256:aaaa\n",
+			ours:   "flask==2.1rc1 --hash=sha256:bbbb\n",
+			theirs: "flask==2.0.3 --hash=sha256:cccc\n",
+			opts:   &Options{VersionPolicy: VersionHigher},
+			want:   "flask==2.1rc1 --hash=sha256:bbbb\n",
+		},
+		{
+			name:   "下界较高的一方",
+			base:   "django>=3.2\n",
+			ours:   "django>=4.0,<5\n",
+			theirs: "django>=4.2,<5\n",
+			opts:   &Options{VersionPolicy: VersionHigher},
+			want:   "django>=4.2,<5\n",
+		},
+		{
+			name:      "版本之外的修改不按策略处理",
+			base:      "django==3.2\n",
+			ours:      "django[rest]==4.0\n",
+			theirs:    "django==4.2\n",
+			opts:      &Options{VersionPolicy: VersionHigher},
+			want:      "<<<<<<< ours\ndjango[rest]==4.0\n=======\ndjango==4.2\n>>>>>>> theirs\n",
+			conflicts: 1,
+		},
+		{
+			name:   "选择theirs",
+			base:   "flask==1.0\n",
+			ours:   "flask==2.0\n",
+			theirs: "flask==1.5\n",
+			opts:   &Options{VersionPolicy: VersionTheirs},
+			want:   "flask==1.5\n",
+		},
+		{
+			name:      "删除与修改冲突",
+			base:      "flask==1.0\nsix\n",
+			ours:      "six\n",
+			theirs:    "flask==2.0\nsix\n",
+			opts:      &Options{Diff3: true, MarkerSize: 3, OursLabel: "HEAD", TheirsLabel: "bump"},
+			want:      "<<< HEAD\n||| base\nflask==1.0\n===\nflask==2.0\n>>> bump\nsix\n",
+			conflicts: 1,
+		},
+		{
+			name:   "保留CRLF、BOM和缺少的末尾换行",
+			base:   "\ufeffflask==1.0\r\nsix",
+			ours:   "\ufeffflask==1.0\r\nsix",
+			theirs: "flask==2.0\nsix\nattrs\n",
+			want:   "\ufeffflask==2.0\r\nsix\r\nattrs",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ThreeWay(tt.base, tt.ours, tt.theirs, tt.opts)
+			if err != nil {
+				t.Fatalf("ThreeWay失败: %v", err)
+			}
+			if result.Text != tt.want {
+				t.Errorf("Text =\n%q\n期望\n%q", result.Text, tt.want)
+			}
+			if len(result.Conflicts) != tt.conflicts {
+				t.Errorf("冲突数量 = %d, 期望 %d: %+v", len(result.Conflicts), tt.conflicts, result.Conflicts)
+			}
+			if result.Clean() != (tt.conflicts == 0) {
+				t.Errorf("Clean() = %v", result.Clean())
+			}
+		})
+	}
+}
+
+func TestThreeWay_Conflicts(t *testing.T) {
+	base := "# web\nflask==1.0\nrequests==2.0\n"
+	ours := "# web\nflask==2.0\nrequests==2.1\n"
+	theirs := "# web\nflask==2.1\n"
+
+	result, err := ThreeWay(base, ours, theirs, nil)
+	if err != nil {
+		t.Fatalf("ThreeWay失败: %v", err)
+	}
+
+	want := []Conflict{
+		{Package: "flask", Base: "flask==1.0", Ours: "flask==2.0", Theirs: "flask==2.1", Line: 2},
+		{Package: "requests", Base: "requests==2.0", Ours: "requests==2.1", Theirs: "", Line: 7},
+	}
+	if len(result.Conflicts) != len(want) {
+		t.Fatalf("冲突 = %+v", result.Conflicts)
+	}
+	for i := range want {
+		if result.Conflicts[i] != want[i] {
+			t.Errorf("冲突[%d] = %+v, 期望 %+v", i, result.Conflicts[i], want[i])
+		}
+	}
+}
+
+func TestSpecifierVersion(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string
+		ok   bool
+	}{
+		{"==2.0.1", "2.0.1", true},
+		{"<5, >=4.2", "4.2", true},
+		{"~=1.4.2", "1.4.2", true},
+		{"<3", "", false},
+		{"==2.*", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := specifierVersion(tt.spec)
+		if ok != tt.ok {
+			t.Errorf("specifierVersion(%q) ok = %v", tt.spec, ok)
+			continue
+		}
+		if ok {
+			want, _ := models.ParseVersion(tt.want)
+			if got.Compare(want) != 0 {
+				t.Errorf("specifierVersion(%q) = %+v", tt.spec, got)
+			}
+		}
+	}
+}
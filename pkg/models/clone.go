@@ -0,0 +1,70 @@
+package models
+
+// Clone 返回requirement的深拷贝
+//
+// Extras、Hashes、GlobalOptions、RequirementOptions和PositionInfo都会被复制，
+// 修改副本不会影响原对象。nil的切片和map在副本中仍为nil。
+//
+// 示例:
+//
+//	clone := req.Clone()
+//	clone.Extras = append(clone.Extras, "socks") // 不影响req.Extras
+func (r *Requirement) Clone() *Requirement {
+	if r == nil {
+		return nil
+	}
+
+	clone := *r
+	clone.Extras = cloneStrings(r.Extras)
+	clone.Hashes = cloneStrings(r.Hashes)
+	clone.GlobalOptions = cloneMap(r.GlobalOptions)
+	clone.RequirementOptions = cloneMap(r.RequirementOptions)
+	clone.PositionInfo = r.PositionInfo.clone()
+
+	return &clone
+}
+
+// cloneStrings 复制字符串切片，nil保持为nil
+func cloneStrings(items []string) []string {
+	if items == nil {
+		return nil
+	}
+	return append(make([]string, 0, len(items)), items...)
+}
+
+// cloneMap 复制map，nil保持为nil
+func cloneMap(original map[string]string) map[string]string {
+	if original == nil {
+		return nil
+	}
+	clone := make(map[string]string, len(original))
+	for k, v := range original {
+		clone[k] = v
+	}
+	return clone
+}
+
+// clone 返回位置信息的深拷贝
+func (p *PositionInfo) clone() *PositionInfo {
+	if p == nil {
+		return nil
+	}
+
+	info := *p
+	for _, span := range []**Span{&info.Name, &info.Extras, &info.Specifier, &info.Markers, &info.Comment} {
+		if *span != nil {
+			copied := **span
+			*span = &copied
+		}
+	}
+	if p.Options != nil {
+		info.Options = make(map[string]Span, len(p.Options))
+		for k, v := range p.Options {
+			info.Options[k] = v
+		}
+	}
+	if p.Hashes != nil {
+		info.Hashes = append([]Span(nil), p.Hashes...)
+	}
+	return &info
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestRequirement_Clone(t *testing.T) {
+	original := &Requirement{
+		Name:               "django",
+		Version:            ">=3.2",
+		Extras:             []string{"rest"},
+		Hashes:             []string{"sha256:aaaa"},
+		GlobalOptions:      map[string]string{"index-url": "https://a.example"},
+		RequirementOptions: map[string]string{"global-option": "x"},
+		PositionInfo: &PositionInfo{
+			LineNumber: 3,
+			EndColumn:  12,
+			Name:       &Span{Start: 0, End: 6, Line: 3, EndLine: 3, EndColumn: 6},
+			Options:    map[string]Span{"global-option": {Start: 12, End: 29}},
+			Hashes:     []Span{{Start: 30, End: 48}},
+		},
+	}
+
+	clone := original.Clone()
+	if !clone.Equal(original) {
+		t.Fatalf("Clone()的结果应与原对象相等")
+	}
+
+	clone.Extras[0] = "auth"
+	clone.Hashes = append(clone.Hashes, "sha256:bbbb")
+	clone.GlobalOptions["index-url"] = "https://b.example"
+	clone.RequirementOptions["global-option"] = "y"
+	clone.PositionInfo.LineNumber = 10
+	clone.PositionInfo.Name.End = 7
+	clone.PositionInfo.Options["global-option"] = Span{}
+	clone.PositionInfo.Hashes[0].Start = 31
+
+	if original.Extras[0] != "rest" || len(original.Hashes) != 1 ||
+		original.GlobalOptions["index-url"] != "https://a.example" ||
+		original.RequirementOptions["global-option"] != "x" ||
+		original.PositionInfo.LineNumber != 3 || original.PositionInfo.Name.End != 6 ||
+		original.PositionInfo.Options["global-option"].End != 29 || original.PositionInfo.Hashes[0].Start != 30 {
+		t.Errorf("修改副本不应影响原对象: %+v", original)
+	}
+
+	if (&Requirement{}).Clone().Extras != nil {
+		t.Errorf("nil切片在副本中应保持为nil")
+	}
+
+	var nilReq *Requirement
+	if nilReq.Clone() != nil {
+		t.Errorf("nil的Clone()应返回nil")
+	}
+}
@@ -0,0 +1,218 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// DocumentSchemaVersion 当前Document JSON格式的版本号
+//
+// 格式发生不兼容的变化时递增。UnmarshalDocument拒绝高于此版本的文档，
+// 对应的JSON Schema发布在docs/public/schema/requirements-document.v1.schema.json。
+const DocumentSchemaVersion = 1
+
+// Severity 诊断信息的严重级别
+type Severity string
+
+const (
+	// SeverityError 错误，pip无法处理该行
+	SeverityError Severity = "error"
+
+	// SeverityWarning 警告，pip可以处理但可能不符合预期
+	SeverityWarning Severity = "warning"
+
+	// SeverityInfo 提示信息
+	SeverityInfo Severity = "info"
+)
+
+// Diagnostic 描述requirements文件中某一行的问题
+type Diagnostic struct {
+	// Severity 严重级别
+	Severity Severity `json:"severity"`
+
+	// Code 机器可读的问题代码
+	// 例如："invalid-specifier", "duplicate-package"
+	Code string `json:"code,omitempty"`
+
+	// Message 问题描述
+	Message string `json:"message"`
+
+	// Line 问题所在的行号（从1开始），0表示与具体行无关
+	Line int `json:"line,omitempty"`
+
+	// File 问题所在的文件，为空时表示文档本身
+	// 例如：校验"-r base.txt"引用的文件时为"base.txt"的路径
+	File string `json:"file,omitempty"`
+}
+
+// FileMetadata 描述requirements文件的文本格式
+//
+// 编辑器根据这些信息把文档写回与原文件相同的格式。
+type FileMetadata struct {
+	// Path 文件路径（如果已知）
+	Path string `json:"path,omitempty"`
+
+	// Encoding 规范化的编码名称，为空时表示"utf-8"
+	// 例如："utf-8", "utf-16-le", "latin-1"
+	Encoding string `json:"encoding,omitempty"`
+
+	// BOM 文件开头是否带有字节顺序标记
+	BOM bool `json:"bom,omitempty"`
+
+	// LineEnding 行终止符，"\n"或"\r\n"，为空时表示"\n"
+	LineEnding string `json:"line_ending,omitempty"`
+
+	// FinalNewline 文件是否以换行结尾
+	FinalNewline bool `json:"final_newline,omitempty"`
+}
+
+// Document 表示一个完整requirements文件的可交换JSON格式
+//
+// Document用于在Go服务与其他语言的工具之间传递解析结果，
+// 格式由SchemaVersion标识版本，JSON Schema由DocumentJSONSchema从Go类型生成。
+// Requirements按文件中的顺序保存每个逻辑行，包括注释行和空行，
+// 因此可以从Document重新序列化出等价的requirements文件。
+//
+// 示例JSON:
+//
+//	{
+//	  "schema_version": 1,
+//	  "metadata": {"encoding": "utf-8", "line_ending": "\n", "final_newline": true},
+//	  "requirements": [{"kind": "package", "name": "flask", "version": "==2.0.1"}],
+//	  "options": {"index-url": "https://pypi.example.com/simple"}
+//	}
+type Document struct {
+	// SchemaVersion 文档格式的版本号，当前为DocumentSchemaVersion
+	SchemaVersion int `json:"schema_version"`
+
+	// Metadata 文件的编码、行终止符等格式信息
+	Metadata FileMetadata `json:"metadata"`
+
+	// Requirements 文件中的所有逻辑行，按出现顺序排列
+	Requirements []*Requirement `json:"requirements"`
+
+	// Options 文件中全局选项的汇总，如"index-url"、"require-hashes"
+	// 该字段由Requirements中的全局选项行计算得到，仅供读取方便，
+	// UnmarshalDocument会忽略输入中的值并重新计算
+	Options map[string]string `json:"options,omitempty"`
+
+	// Diagnostics 解析或校验过程中发现的问题
+	Diagnostics []Diagnostic `json:"diagnostics,omitempty"`
+}
+
+// NewDocument 使用给定的requirements创建当前版本的Document
+//
+// 参数:
+//   - reqs: 文件中的所有逻辑行
+//   - metadata: 文件格式信息
+//
+// 返回:
+//   - *Document: 新创建的文档，Options已根据reqs中的全局选项计算
+func NewDocument(reqs []*Requirement, metadata FileMetadata) *Document {
+	doc := &Document{
+		SchemaVersion: DocumentSchemaVersion,
+		Metadata:      metadata,
+		Requirements:  reqs,
+	}
+	doc.Options = doc.collectOptions()
+	return doc
+}
+
+// collectOptions 汇总所有全局选项行中的选项，后出现的值覆盖先出现的值
+func (d *Document) collectOptions() map[string]string {
+	var options map[string]string
+	for _, req := range d.Requirements {
+		if req == nil || req.EffectiveKind() != KindGlobalOption {
+			continue
+		}
+		for key, value := range req.GlobalOptions {
+			if options == nil {
+				options = make(map[string]string)
+			}
+			options[key] = value
+		}
+	}
+	return options
+}
+
+// MarshalDocument 将文档编码为带缩进的JSON
+//
+// SchemaVersion为0时写入当前版本，Options按Requirements重新计算。
+//
+// 参数:
+//   - doc: 要编码的文档
+//
+// 返回:
+//   - []byte: JSON数据
+//   - error: 编码失败时返回错误
+func MarshalDocument(doc *Document) ([]byte, error) {
+	if doc == nil {
+		return nil, fmt.Errorf("文档不能为nil")
+	}
+
+	out := *doc
+	if out.SchemaVersion == 0 {
+		out.SchemaVersion = DocumentSchemaVersion
+	}
+	if out.Requirements == nil {
+		out.Requirements = []*Requirement{}
+	}
+	out.Options = out.collectOptions()
+
+	return json.MarshalIndent(&out, "", "  ")
+}
+
+// UnmarshalDocument 解码JSON格式的文档并检查其是否可以被编辑器序列化
+//
+// 检查内容包括：schema_version存在且不高于DocumentSchemaVersion、
+// requirements中没有null项、每一项的kind（如果提供）是已知类型、
+// line_ending为"\n"或"\r\n"。没有kind的项（如旧版本输出的JSON）
+// 通过EffectiveKind从布尔字段推断类型。
+//
+// 参数:
+//   - data: JSON数据
+//
+// 返回:
+//   - *Document: 解码后的文档
+//   - error: JSON格式错误或文档不合法时返回错误
+//
+// 示例:
+//
+//	doc, err := models.UnmarshalDocument(data)
+//	if err != nil {
+//	    // 处理错误
+//	}
+//	for _, req := range doc.Requirements {
+//	    fmt.Println(req.String())
+//	}
+func UnmarshalDocument(data []byte) (*Document, error) {
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("解析文档JSON失败: %w", err)
+	}
+
+	if doc.SchemaVersion == 0 {
+		return nil, fmt.Errorf("文档缺少schema_version")
+	}
+	if doc.SchemaVersion > DocumentSchemaVersion {
+		return nil, fmt.Errorf("不支持的文档版本: %d（最高支持%d）", doc.SchemaVersion, DocumentSchemaVersion)
+	}
+
+	switch doc.Metadata.LineEnding {
+	case "", "\n", "\r\n":
+	default:
+		return nil, fmt.Errorf("无效的行终止符: %q", doc.Metadata.LineEnding)
+	}
+
+	for i, req := range doc.Requirements {
+		if req == nil {
+			return nil, fmt.Errorf("requirements[%d]不能为null", i)
+		}
+		if req.Kind != "" && !req.Kind.IsValid() {
+			return nil, fmt.Errorf("requirements[%d]的类型无效: %q", i, req.Kind)
+		}
+	}
+
+	doc.Options = doc.collectOptions()
+	return &doc, nil
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDocument_RoundTrip(t *testing.T) {
+	original := NewDocument([]*Requirement{
+		{Kind: KindComment, IsComment: true, Comment: "Web"},
+		{Kind: KindPackage, Name: "flask", Version: "==2.0.1", Hashes: []string{"sha256:aaaa"}},
+		{Kind: KindGlobalOption, GlobalOptions: map[string]string{"index-url": "https://pypi.example.com/simple"}},
+		{Kind: KindVCS, IsVCS: true, IsEditable: true, VCSType: "git", URL: "https://github.com/user/project.git", Name: "project"},
+		{Kind: KindBlank, IsEmpty: true},
+	}, FileMetadata{Encoding: "utf-8", BOM: true, LineEnding: "\r\n", FinalNewline: true})
+	original.Diagnostics = []Diagnostic{{Severity: SeverityWarning, Code: "unpinned", Message: "未固定版本", Line: 4}}
+
+	data, err := MarshalDocument(original)
+	if err != nil {
+		t.Fatalf("MarshalDocument()失败: %v", err)
+	}
+	decoded, err := UnmarshalDocument(data)
+	if err != nil {
+		t.Fatalf("UnmarshalDocument()失败: %v", err)
+	}
+
+	if decoded.SchemaVersion != DocumentSchemaVersion || decoded.Metadata != original.Metadata {
+		t.Errorf("版本或元数据不一致: %d, %+v", decoded.SchemaVersion, decoded.Metadata)
+	}
+	if len(decoded.Requirements) != len(original.Requirements) {
+		t.Fatalf("requirements数量 = %d, 期望 %d", len(decoded.Requirements), len(original.Requirements))
+	}
+	for i, req := range decoded.Requirements {
+		if !req.Equal(original.Requirements[i]) {
+			t.Errorf("requirements[%d] = %+v, 期望 %+v", i, req, original.Requirements[i])
+		}
+	}
+	if decoded.Options["index-url"] != "https://pypi.example.com/simple" {
+		t.Errorf("Options = %v", decoded.Options)
+	}
+	if len(decoded.Diagnostics) != 1 || decoded.Diagnostics[0] != original.Diagnostics[0] {
+		t.Errorf("Diagnostics = %+v", decoded.Diagnostics)
+	}
+}
+
+func TestMarshalDocument_Defaults(t *testing.T) {
+	data, err := MarshalDocument(&Document{})
+	if err != nil {
+		t.Fatalf("MarshalDocument()失败: %v", err)
+	}
+	if !strings.Contains(string(data), `"schema_version": 1`) || !strings.Contains(string(data), `"requirements": []`) {
+		t.Errorf("MarshalDocument()应写入当前版本和空的requirements: %s", data)
+	}
+
+	if _, err := MarshalDocument(nil); err == nil {
+		t.Errorf("MarshalDocument(nil)应返回错误")
+	}
+}
+
+func TestUnmarshalDocument(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		wantErr string
+	}{
+		{
+			name: "Legacy requirement without kind",
+			json: `{"schema_version":1,"metadata":{},"requirements":[{"name":"","is_file_ref":true,"file_ref":"base.txt"}]}`,
+		},
+		{
+			name: "Options are recomputed",
+			json: `{"schema_version":1,"metadata":{},"requirements":[],"options":{"pre":"true"}}`,
+		},
+		{name: "Invalid JSON", json: `{`, wantErr: "解析文档JSON失败"},
+		{name: "Missing version", json: `{"metadata":{},"requirements":[]}`, wantErr: "缺少schema_version"},
+		{name: "Future version", json: `{"schema_version":99,"metadata":{},"requirements":[]}`, wantErr: "不支持的文档版本"},
+		{name: "Null requirement", json: `{"schema_version":1,"metadata":{},"requirements":[null]}`, wantErr: "不能为null"},
+		{name: "Unknown kind", json: `{"schema_version":1,"metadata":{},"requirements":[{"name":"x","kind":"wheel"}]}`, wantErr: "类型无效"},
+		{name: "Bad line ending", json: `{"schema_version":1,"metadata":{"line_ending":"\r"},"requirements":[]}`, wantErr: "无效的行终止符"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := UnmarshalDocument([]byte(tt.json))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("UnmarshalDocument()错误 = %v, 期望包含 %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UnmarshalDocument()失败: %v", err)
+			}
+			if doc.Options != nil {
+				t.Errorf("Options应根据requirements重新计算，得到 %v", doc.Options)
+			}
+			for _, req := range doc.Requirements {
+				if req.String() != "-r base.txt" {
+					t.Errorf("String() = %q", req.String())
+				}
+			}
+		})
+	}
+}
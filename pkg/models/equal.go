@@ -0,0 +1,316 @@
+package models
+
+import (
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Equal 检查两个requirement的所有字段是否完全相同
+//
+// 包括OriginalLine、Comment和PositionInfo在内的每个字段都参与比较，
+// nil与空的切片或map视为相同。需要忽略格式差异时使用SemanticEqual。
+//
+// 示例:
+//
+//	a := &models.Requirement{Name: "flask", Version: "==2.0.1"}
+//	b := a.Clone()
+//	a.Equal(b) // 返回true
+func (r *Requirement) Equal(other *Requirement) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+
+	if !stringsEqual(r.Extras, other.Extras) || !stringsEqual(r.Hashes, other.Hashes) ||
+		!mapsEqual(r.GlobalOptions, other.GlobalOptions) ||
+		!mapsEqual(r.RequirementOptions, other.RequirementOptions) {
+		return false
+	}
+
+	if !r.PositionInfo.equal(other.PositionInfo) {
+		return false
+	}
+
+	// 其余字段都是可比较的基本类型
+	a, b := *r, *other
+	a.Extras, b.Extras = nil, nil
+	a.Hashes, b.Hashes = nil, nil
+	a.GlobalOptions, b.GlobalOptions = nil, nil
+	a.RequirementOptions, b.RequirementOptions = nil, nil
+	a.PositionInfo, b.PositionInfo = nil, nil
+	return reflect.DeepEqual(a, b)
+}
+
+// equal 比较两个位置信息，nil与空的切片或map视为相同
+func (p *PositionInfo) equal(other *PositionInfo) bool {
+	if p == nil || other == nil {
+		return p == other
+	}
+
+	if len(p.Options) != len(other.Options) || len(p.Hashes) != len(other.Hashes) {
+		return false
+	}
+	for k, v := range p.Options {
+		if o, ok := other.Options[k]; !ok || o != v {
+			return false
+		}
+	}
+	for i := range p.Hashes {
+		if p.Hashes[i] != other.Hashes[i] {
+			return false
+		}
+	}
+
+	a, b := *p, *other
+	a.Options, b.Options = nil, nil
+	a.Hashes, b.Hashes = nil, nil
+	return reflect.DeepEqual(a, b)
+}
+
+// SemanticEqual 检查两个requirement对pip而言是否等价
+//
+// 比较时忽略格式和注释：包名按PEP 503规范化，extras规范化后按集合比较，
+// 版本约束忽略空白和子句顺序，环境标记忽略空白和引号风格，哈希按集合比较。
+// OriginalLine、Comment、PositionInfo和ResolvedPath不参与比较；
+// 直接引用（name @ url）与"url#egg=name"形式也视为等价。
+// 注释行和空行没有语义内容，彼此之间总是等价。
+//
+// 示例:
+//
+//	a := &models.Requirement{Name: "Django", Version: ">=3.2, <4", Extras: []string{"rest", "auth"}}
+//	b := &models.Requirement{Name: "django", Version: "<4,>=3.2", Extras: []string{"auth", "rest"}, Comment: "web"}
+//	a.SemanticEqual(b) // 返回true
+func (r *Requirement) SemanticEqual(other *Requirement) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+
+	if r.IsTrivia() || other.IsTrivia() {
+		return r.IsTrivia() && other.IsTrivia()
+	}
+
+	if r.IsEditable != other.IsEditable ||
+		r.IsFileRef != other.IsFileRef || r.FileRef != other.FileRef ||
+		r.IsConstraint != other.IsConstraint || r.ConstraintFile != other.ConstraintFile ||
+		r.IsURL != other.IsURL || r.IsVCS != other.IsVCS || r.IsLocalPath != other.IsLocalPath ||
+		r.VCSType != other.VCSType {
+		return false
+	}
+
+	if NormalizeName(r.Name) != NormalizeName(other.Name) ||
+		NormalizeSpecifier(r.Version) != NormalizeSpecifier(other.Version) ||
+		NormalizeMarker(r.Markers) != NormalizeMarker(other.Markers) {
+		return false
+	}
+
+	if r.IsLocalPath {
+		if normalizePath(r.LocalPath) != normalizePath(other.LocalPath) {
+			return false
+		}
+	} else if r.URL != other.URL {
+		return false
+	}
+
+	return stringsEqual(normalizedSet(r.Extras, NormalizeName), normalizedSet(other.Extras, NormalizeName)) &&
+		stringsEqual(normalizedSet(r.Hashes, strings.ToLower), normalizedSet(other.Hashes, strings.ToLower)) &&
+		mapsEqual(r.GlobalOptions, other.GlobalOptions) &&
+		mapsEqual(r.RequirementOptions, other.RequirementOptions)
+}
+
+// NormalizeName 按PEP 503规范化包名
+//
+// 转换为小写，并将连续的"-"、"_"、"."替换为单个"-"。
+//
+// 示例:
+//
+//	models.NormalizeName("Flask_SQLAlchemy") // 返回 "flask-sqlalchemy"
+//	models.NormalizeName("zope.interface")   // 返回 "zope-interface"
+func NormalizeName(name string) string {
+	var sb strings.Builder
+	sb.Grow(len(name))
+	separator := false
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c == '-' || c == '_' || c == '.' {
+			separator = true
+			continue
+		}
+		if separator {
+			sb.WriteByte('-')
+		}
+		separator = false
+		if c >= 'A' && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		sb.WriteByte(c)
+	}
+	if separator {
+		sb.WriteByte('-')
+	}
+	return sb.String()
+}
+
+// NormalizeSpecifier 规范化版本约束
+//
+// 去掉所有空白和外层括号，转换为小写，并按字典序排列各个子句。
+//
+// 示例:
+//
+//	models.NormalizeSpecifier(" < 4.0 , >= 3.2") // 返回 "<4.0,>=3.2"
+//	models.NormalizeSpecifier("(>=1.0)")         // 返回 ">=1.0"
+func NormalizeSpecifier(spec string) string {
+	spec = strings.Join(strings.Fields(spec), "")
+	if strings.HasPrefix(spec, "(") && strings.HasSuffix(spec, ")") {
+		spec = spec[1 : len(spec)-1]
+	}
+	if spec == "" {
+		return ""
+	}
+
+	var clauses []string
+	for _, clause := range strings.Split(strings.ToLower(spec), ",") {
+		if clause != "" {
+			clauses = append(clauses, clause)
+		}
+	}
+	sort.Strings(clauses)
+	return strings.Join(clauses, ",")
+}
+
+// IsExactPin 检查版本约束是否为pip认可的精确固定：单个==或===约束且不含通配符
+//
+// 哈希检查模式下按包名引用的requirement必须精确固定。
+//
+// 示例:
+//
+//	models.IsExactPin("==2.0.1")       // 返回 true
+//	models.IsExactPin("==2.*")         // 返回 false
+//	models.IsExactPin(">=2.0,==2.0.1") // 返回 false
+func IsExactPin(spec string) bool {
+	spec = strings.TrimSpace(spec)
+	return strings.HasPrefix(spec, "==") && !strings.ContainsAny(spec, ",*")
+}
+
+// PinnedVersion 返回精确固定的版本约束中的版本号
+//
+// 版本约束不是IsExactPin认可的精确固定时返回false。
+//
+// 示例:
+//
+//	models.PinnedVersion("==2.0.1")  // 返回 "2.0.1", true
+//	models.PinnedVersion("===2.0.1") // 返回 "2.0.1", true
+//	models.PinnedVersion(">=2.0")    // 返回 "", false
+func PinnedVersion(spec string) (string, bool) {
+	if !IsExactPin(spec) {
+		return "", false
+	}
+	version := strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(spec), "="))
+	return version, version != ""
+}
+
+// NormalizeMarker 规范化环境标记
+//
+// 将标记拆分为标识符、运算符、括号和字符串，字符串统一使用双引号，
+// 各部分之间使用单个空格连接，逻辑运算符转换为小写。
+//
+// 示例:
+//
+//	models.NormalizeMarker("python_version>='3.8'  AND os_name=='nt'")
+//	// 返回 `python_version >= "3.8" and os_name == "nt"`
+func NormalizeMarker(marker string) string {
+	var tokens []string
+	for i := 0; i < len(marker); {
+		c := marker[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(marker[i+1:], c)
+			if end == -1 {
+				tokens = append(tokens, `"`+marker[i+1:]+`"`)
+				i = len(marker)
+				continue
+			}
+			tokens = append(tokens, `"`+marker[i+1:i+1+end]+`"`)
+			i += end + 2
+		case c == '(' || c == ')':
+			tokens = append(tokens, string(c))
+			i++
+		case strings.IndexByte("<>=!~", c) != -1:
+			j := i
+			for j < len(marker) && strings.IndexByte("<>=!~", marker[j]) != -1 {
+				j++
+			}
+			tokens = append(tokens, marker[i:j])
+			i = j
+		default:
+			j := i
+			for j < len(marker) && strings.IndexByte(" \t\r\n'\"()<>=!~", marker[j]) == -1 {
+				j++
+			}
+			word := marker[i:j]
+			switch lower := strings.ToLower(word); lower {
+			case "and", "or", "not", "in":
+				word = lower
+			}
+			tokens = append(tokens, word)
+			i = j
+		}
+	}
+	return strings.Join(tokens, " ")
+}
+
+// normalizePath 规范化本地路径，统一使用"/"分隔符并清理多余的"."和"/"
+func normalizePath(p string) string {
+	if p == "" {
+		return ""
+	}
+	return path.Clean(strings.ReplaceAll(p, "\\", "/"))
+}
+
+// normalizedSet 规范化每一项后排序去重
+func normalizedSet(items []string, normalize func(string) string) []string {
+	if len(items) == 0 {
+		return nil
+	}
+	set := make([]string, 0, len(items))
+	for _, item := range items {
+		set = append(set, normalize(strings.TrimSpace(item)))
+	}
+	sort.Strings(set)
+
+	result := set[:1]
+	for _, item := range set[1:] {
+		if item != result[len(result)-1] {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// stringsEqual 比较两个字符串切片，nil与空切片视为相同
+func stringsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// mapsEqual 比较两个map，nil与空map视为相同
+func mapsEqual(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if other, ok := b[k]; !ok || other != v {
+			return false
+		}
+	}
+	return true
+}
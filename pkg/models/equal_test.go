@@ -0,0 +1,207 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestRequirement_Equal(t *testing.T) {
+	base := &Requirement{
+		Name:         "flask",
+		Version:      "==2.0.1",
+		Comment:      "web",
+		OriginalLine: "flask==2.0.1 # web",
+		PositionInfo: &PositionInfo{LineNumber: 1, Name: &Span{End: 5, Line: 1, EndLine: 1, EndColumn: 5}},
+	}
+
+	tests := []struct {
+		name     string
+		modify   func(r *Requirement)
+		expected bool
+	}{
+		{name: "Identical", modify: func(r *Requirement) {}, expected: true},
+		{name: "Empty extras equals nil", modify: func(r *Requirement) { r.Extras = []string{} }, expected: true},
+		{name: "Different comment", modify: func(r *Requirement) { r.Comment = "other" }, expected: false},
+		{name: "Different original line", modify: func(r *Requirement) { r.OriginalLine = "flask==2.0.1" }, expected: false},
+		{name: "Different position", modify: func(r *Requirement) { r.PositionInfo.LineNumber = 2 }, expected: false},
+		{name: "Different span", modify: func(r *Requirement) { r.PositionInfo.Name.End = 4 }, expected: false},
+		{name: "Empty spans equal nil", modify: func(r *Requirement) { r.PositionInfo.Hashes = []Span{} }, expected: true},
+		{name: "Missing position", modify: func(r *Requirement) { r.PositionInfo = nil }, expected: false},
+		{name: "Different hashes", modify: func(r *Requirement) { r.Hashes = []string{"sha256:aa"} }, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			other := base.Clone()
+			tt.modify(other)
+			if got := base.Equal(other); got != tt.expected {
+				t.Errorf("Equal() = %v, 期望 %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRequirement_SemanticEqual(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     *Requirement
+		expected bool
+	}{
+		{
+			name:     "Normalized names",
+			a:        &Requirement{Name: "Flask_SQLAlchemy"},
+			b:        &Requirement{Name: "flask-sqlalchemy"},
+			expected: true,
+		},
+		{
+			name:     "Specifier whitespace and order",
+			a:        &Requirement{Name: "django", Version: ">= 3.2 , < 4.0"},
+			b:        &Requirement{Name: "django", Version: "<4.0,>=3.2"},
+			expected: true,
+		},
+		{
+			name:     "Different specifiers",
+			a:        &Requirement{Name: "django", Version: ">=3.2"},
+			b:        &Requirement{Name: "django", Version: ">=3.1"},
+			expected: false,
+		},
+		{
+			name:     "Extras order and case",
+			a:        &Requirement{Name: "requests", Extras: []string{"Socks", "security"}},
+			b:        &Requirement{Name: "requests", Extras: []string{"security", "socks"}},
+			expected: true,
+		},
+		{
+			name:     "Marker formatting",
+			a:        &Requirement{Name: "pkg", Markers: "python_version>='3.8'"},
+			b:        &Requirement{Name: "pkg", Markers: `python_version >= "3.8"`},
+			expected: true,
+		},
+		{
+			name:     "Different markers",
+			a:        &Requirement{Name: "pkg", Markers: "python_version >= '3.8'"},
+			b:        &Requirement{Name: "pkg", Markers: "python_version >= '3.9'"},
+			expected: false,
+		},
+		{
+			name:     "Comments and original line ignored",
+			a:        &Requirement{Name: "flask", Comment: "a", OriginalLine: "flask  # a"},
+			b:        &Requirement{Name: "flask", Comment: "b", OriginalLine: "flask # b"},
+			expected: true,
+		},
+		{
+			name:     "Hash order",
+			a:        &Requirement{Name: "flask", Hashes: []string{"sha256:BB", "sha256:aa"}},
+			b:        &Requirement{Name: "flask", Hashes: []string{"sha256:aa", "sha256:bb"}},
+			expected: true,
+		},
+		{
+			name:     "Editable differs",
+			a:        &Requirement{IsEditable: true, IsLocalPath: true, LocalPath: "."},
+			b:        &Requirement{IsLocalPath: true, LocalPath: "."},
+			expected: false,
+		},
+		{
+			name:     "Local path spelling",
+			a:        &Requirement{IsLocalPath: true, LocalPath: "./libs/core/"},
+			b:        &Requirement{IsLocalPath: true, LocalPath: "libs/core"},
+			expected: true,
+		},
+		{
+			name: "Direct reference and egg form",
+			a:    &Requirement{Name: "pkg", IsDirectRef: true, IsURL: true, URL: "https://example.com/pkg.zip"},
+			b:    &Requirement{Name: "pkg", IsURL: true, URL: "https://example.com/pkg.zip"},
+
+			expected: true,
+		},
+		{
+			name:     "Comment lines",
+			a:        &Requirement{IsComment: true, Comment: "a"},
+			b:        &Requirement{IsEmpty: true},
+			expected: true,
+		},
+		{
+			name:     "Comment and package",
+			a:        &Requirement{IsComment: true, Comment: "flask"},
+			b:        &Requirement{Name: "flask"},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.SemanticEqual(tt.b); got != tt.expected {
+				t.Errorf("SemanticEqual() = %v, 期望 %v", got, tt.expected)
+			}
+			if got := tt.b.SemanticEqual(tt.a); got != tt.expected {
+				t.Errorf("SemanticEqual()不对称: %v", got)
+			}
+		})
+	}
+}
+
+func TestNormalizeName(t *testing.T) {
+	tests := map[string]string{
+		"Flask":            "flask",
+		"zope.interface":   "zope-interface",
+		"Foo__Bar--baz..x": "foo-bar-baz-x",
+		"":                 "",
+	}
+	for input, expected := range tests {
+		if got := NormalizeName(input); got != expected {
+			t.Errorf("NormalizeName(%q) = %q, 期望 %q", input, got, expected)
+		}
+	}
+}
+
+func TestNormalizeSpecifierAndMarker(t *testing.T) {
+	if got := NormalizeSpecifier("(>=1.0)"); got != ">=1.0" {
+		t.Errorf("NormalizeSpecifier = %q", got)
+	}
+	if got := NormalizeSpecifier(" < 4.0 , >= 3.2"); got != "<4.0,>=3.2" {
+		t.Errorf("NormalizeSpecifier = %q", got)
+	}
+
+	got := NormalizeMarker("(python_version>='3.8'  AND os_name=='nt') or extra == \"dev\"")
+	expected := `( python_version >= "3.8" and os_name == "nt" ) or extra == "dev"`
+	if got != expected {
+		t.Errorf("NormalizeMarker = %q, 期望 %q", got, expected)
+	}
+}
+
+func TestIsExactPin(t *testing.T) {
+	tests := map[string]bool{
+		"==2.0.1":       true,
+		" === 2.0.1 ":   true,
+		"==2.*":         false,
+		">=2.0,==2.0.1": false,
+		">=2.0":         false,
+		"":              false,
+	}
+	for spec, want := range tests {
+		if got := IsExactPin(spec); got != want {
+			t.Errorf("IsExactPin(%q) = %v, 期望 %v", spec, got, want)
+		}
+	}
+}
+
+func TestPinnedVersion(t *testing.T) {
+	tests := []struct {
+		specifier string
+		version   string
+		ok        bool
+	}{
+		{"==1.2.3", "1.2.3", true},
+		{" === 1.2.3", "1.2.3", true},
+		{"==1.*", "", false},
+		{">=1.0", "", false},
+		{"==1.0,!=1.0.1", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		version, ok := PinnedVersion(tt.specifier)
+		if version != tt.version || ok != tt.ok {
+			t.Errorf("PinnedVersion(%q) = %q, %v", tt.specifier, version, ok)
+		}
+	}
+}
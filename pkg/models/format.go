@@ -0,0 +1,230 @@
+package models
+
+import (
+	"sort"
+	"strings"
+)
+
+// FormatOptions 控制Requirement.Format的输出格式
+//
+// 零值表示规范格式：所有内容输出在一行中，环境标记前使用" ; "，
+// 注释前使用" # "。
+type FormatOptions struct {
+	// OmitComment 是否省略行尾注释（注释行本身不受影响）
+	OmitComment bool
+
+	// OmitHashes 是否省略--hash选项
+	OmitHashes bool
+
+	// MultilineHashes 是否将每个--hash选项放在单独的续行中，与pip-compile的输出一致
+	// 例如：
+	//   django==3.2.0 \
+	//       --hash=sha256:aaaa \
+	//       --hash=sha256:bbbb
+	MultilineHashes bool
+
+	// Indent 续行的缩进，默认为4个空格
+	Indent string
+
+	// Newline 续行使用的换行符，默认为"\n"
+	Newline string
+}
+
+// String 返回requirement的规范pip语法
+//
+// 等价于Format(FormatOptions{})。
+//
+// 示例:
+//
+//	req := &models.Requirement{Name: "django", Version: ">=3.2", Extras: []string{"rest"}, Markers: "python_version >= '3.8'"}
+//	req.String() // 返回 "django[rest]>=3.2 ; python_version >= '3.8'"
+func (r *Requirement) String() string {
+	return r.Format(FormatOptions{})
+}
+
+// Format 按指定选项将requirement格式化为requirements.txt中的一行
+//
+// 支持所有类型的requirement：普通包、可编辑安装、VCS URL、URL、本地路径、
+// 直接引用（name @ url）、文件引用（-r）、约束文件（-c）、全局选项、注释和空行。
+// 每个requirement的选项按名称排序输出，因此结果是确定的。
+//
+// 参数:
+//   - opts: 格式选项
+//
+// 返回:
+//   - string: 格式化后的行，不包含行终止符
+//
+// 示例:
+//
+//	req := &models.Requirement{
+//	    Name:    "django",
+//	    Version: "==3.2.0",
+//	    Hashes:  []string{"sha256:aaaa", "sha256:bbbb"},
+//	}
+//	req.Format(models.FormatOptions{MultilineHashes: true})
+//	// 返回 "django==3.2.0 \\\n    --hash=sha256:aaaa \\\n    --hash=sha256:bbbb"
+func (r *Requirement) Format(opts FormatOptions) string {
+	kind := r.EffectiveKind()
+	switch kind {
+	case KindBlank:
+		return ""
+	case KindComment:
+		if r.Comment == "" {
+			return "#"
+		}
+		return "# " + r.Comment
+	}
+
+	var sb strings.Builder
+	switch kind {
+	case KindInclude:
+		sb.WriteString("-r " + r.FileRef)
+	case KindConstraint:
+		sb.WriteString("-c " + r.ConstraintFile)
+	case KindGlobalOption:
+		writeOptions(&sb, r.GlobalOptions, " ")
+	default:
+		r.writeRequirement(&sb, kind, opts)
+	}
+
+	if !opts.OmitComment && r.Comment != "" {
+		if sb.Len() > 0 {
+			sb.WriteString(" ")
+		}
+		sb.WriteString("# " + r.Comment)
+	}
+
+	return sb.String()
+}
+
+// writeRequirement 输出可安装的requirement：包规格、环境标记、选项和哈希
+func (r *Requirement) writeRequirement(sb *strings.Builder, kind Kind, opts FormatOptions) {
+	if r.IsEditable {
+		sb.WriteString("-e ")
+	}
+	sb.WriteString(r.specifier(kind))
+
+	// URL中可能包含";"，因此环境标记前总是使用空白分隔
+	if r.Markers != "" {
+		sb.WriteString(" ; " + r.Markers)
+	}
+
+	var options map[string]string
+	hashes := r.Hashes
+	for key, value := range r.RequirementOptions {
+		if key == "hash" {
+			// 兼容把哈希保存在选项中的写法
+			if !containsString(hashes, value) {
+				hashes = append(hashes[:len(hashes):len(hashes)], value)
+			}
+			continue
+		}
+		if options == nil {
+			options = make(map[string]string, len(r.RequirementOptions))
+		}
+		options[key] = value
+	}
+	if len(options) > 0 {
+		sb.WriteString(" ")
+		writeOptions(sb, options, "=")
+	}
+
+	if opts.OmitHashes {
+		return
+	}
+
+	indent, newline := opts.Indent, opts.Newline
+	if indent == "" {
+		indent = "    "
+	}
+	if newline == "" {
+		newline = "\n"
+	}
+	for _, hash := range hashes {
+		if opts.MultilineHashes {
+			sb.WriteString(" \\" + newline + indent)
+		} else {
+			sb.WriteString(" ")
+		}
+		sb.WriteString("--hash=" + hash)
+	}
+}
+
+// specifier 返回requirement的主体部分，如"flask[async]==2.0.1"、
+// "pkg @ https://example.com/pkg.whl"或"git+https://github.com/user/project.git#egg=project"
+func (r *Requirement) specifier(kind Kind) string {
+	extras := formatExtras(r.Extras)
+
+	switch kind {
+	case KindDirectRef:
+		// 直接引用(name[extras] @ url)
+		ref := r.URL
+		if r.VCSType != "" {
+			ref = r.VCSType + "+" + r.URL
+		} else if ref == "" {
+			ref = r.LocalPath
+		}
+		return r.Name + extras + " @ " + ref
+	case KindVCS:
+		// VCS URL，包名通过#egg=指定
+		ref := r.URL
+		if r.VCSType != "" {
+			ref = r.VCSType + "+" + ref
+		}
+		if r.Name != "" {
+			ref += "#egg=" + r.Name + extras
+		}
+		return ref
+	case KindURL:
+		// 普通URL
+		if r.Name != "" {
+			return r.URL + "#egg=" + r.Name + extras
+		}
+		return r.URL
+	case KindLocalPath:
+		// 本地路径，file:// URL形式的路径保留原始URL
+		if r.URL != "" {
+			return r.URL + extras
+		}
+		return r.LocalPath + extras
+	default:
+		// 普通包
+		return r.Name + extras + r.Version
+	}
+}
+
+// writeOptions 按名称排序输出选项，值为"true"的选项作为开关输出
+func writeOptions(sb *strings.Builder, options map[string]string, sep string) {
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for i, key := range keys {
+		if i > 0 {
+			sb.WriteString(" ")
+		}
+		sb.WriteString("--" + key)
+		if value := options[key]; value != "true" {
+			sb.WriteString(sep + value)
+		}
+	}
+}
+
+// formatExtras 将extras格式化为"[a,b]"形式，没有extras时返回空字符串
+func formatExtras(extras []string) string {
+	if len(extras) == 0 {
+		return ""
+	}
+	return "[" + strings.Join(extras, ",") + "]"
+}
+
+func containsString(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
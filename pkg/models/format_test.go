@@ -0,0 +1,185 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestRequirement_String(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      *Requirement
+		expected string
+	}{
+		{
+			name:     "Empty line",
+			req:      &Requirement{IsEmpty: true},
+			expected: "",
+		},
+		{
+			name:     "Comment line",
+			req:      &Requirement{IsComment: true, Comment: "Web frameworks"},
+			expected: "# Web frameworks",
+		},
+		{
+			name:     "Bare comment",
+			req:      &Requirement{IsComment: true},
+			expected: "#",
+		},
+		{
+			name:     "Basic requirement",
+			req:      &Requirement{Name: "flask", Version: "==2.0.1"},
+			expected: "flask==2.0.1",
+		},
+		{
+			name: "Extras, markers and comment",
+			req: &Requirement{
+				Name:    "django",
+				Version: ">=3.2,<4.0",
+				Extras:  []string{"rest", "auth"},
+				Markers: "python_version >= '3.8'",
+				Comment: "Web framework",
+			},
+			expected: "django[rest,auth]>=3.2,<4.0 ; python_version >= '3.8' # Web framework",
+		},
+		{
+			name: "Options and hashes",
+			req: &Requirement{
+				Name:               "numpy",
+				Version:            "==1.21.0",
+				RequirementOptions: map[string]string{"no-binary": "true", "global-option": "x"},
+				Hashes:             []string{"sha256:aaaa", "sha256:bbbb"},
+			},
+			expected: "numpy==1.21.0 --global-option=x --no-binary --hash=sha256:aaaa --hash=sha256:bbbb",
+		},
+		{
+			name: "Hash stored as option",
+			req: &Requirement{
+				Name:               "flask",
+				RequirementOptions: map[string]string{"hash": "sha256:cccc"},
+			},
+			expected: "flask --hash=sha256:cccc",
+		},
+		{
+			name:     "File reference",
+			req:      &Requirement{IsFileRef: true, FileRef: "base.txt", Comment: "shared"},
+			expected: "-r base.txt # shared",
+		},
+		{
+			name:     "Constraint file",
+			req:      &Requirement{IsConstraint: true, ConstraintFile: "constraints.txt"},
+			expected: "-c constraints.txt",
+		},
+		{
+			name:     "Global option with value",
+			req:      &Requirement{GlobalOptions: map[string]string{"index-url": "https://pypi.example.com/simple"}},
+			expected: "--index-url https://pypi.example.com/simple",
+		},
+		{
+			name:     "Global flag",
+			req:      &Requirement{GlobalOptions: map[string]string{"require-hashes": "true"}},
+			expected: "--require-hashes",
+		},
+		{
+			name: "Editable VCS",
+			req: &Requirement{
+				IsEditable: true,
+				IsVCS:      true,
+				VCSType:    "git",
+				URL:        "https://github.com/user/project.git",
+				Name:       "project",
+				Extras:     []string{"dev"},
+			},
+			expected: "-e git+https://github.com/user/project.git#egg=project[dev]",
+		},
+		{
+			name:     "URL with egg",
+			req:      &Requirement{IsURL: true, URL: "https://example.com/pkg.zip", Name: "pkg"},
+			expected: "https://example.com/pkg.zip#egg=pkg",
+		},
+		{
+			name: "Direct reference",
+			req: &Requirement{
+				IsDirectRef: true,
+				IsURL:       true,
+				Name:        "requests",
+				Extras:      []string{"socks"},
+				URL:         "https://example.com/requests.whl",
+				Markers:     "os_name == 'nt'",
+			},
+			expected: "requests[socks] @ https://example.com/requests.whl ; os_name == 'nt'",
+		},
+		{
+			name: "Direct VCS reference",
+			req: &Requirement{
+				IsDirectRef: true,
+				IsVCS:       true,
+				VCSType:     "git",
+				Name:        "pkg",
+				URL:         "https://github.com/user/pkg.git@v1.0",
+			},
+			expected: "pkg @ git+https://github.com/user/pkg.git@v1.0",
+		},
+		{
+			name:     "Editable local path",
+			req:      &Requirement{IsEditable: true, IsLocalPath: true, LocalPath: ".", Extras: []string{"dev", "test"}},
+			expected: "-e .[dev,test]",
+		},
+		{
+			name:     "File URL local path",
+			req:      &Requirement{IsLocalPath: true, LocalPath: "/tmp/pkg.whl", URL: "file:///tmp/pkg.whl"},
+			expected: "file:///tmp/pkg.whl",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.req.String(); got != tt.expected {
+				t.Errorf("String() = %q, 期望 %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRequirement_Format(t *testing.T) {
+	req := &Requirement{
+		Name:    "django",
+		Version: "==3.2.0",
+		Hashes:  []string{"sha256:aaaa", "sha256:bbbb"},
+		Comment: "pinned",
+	}
+
+	tests := []struct {
+		name     string
+		opts     FormatOptions
+		expected string
+	}{
+		{
+			name:     "Default",
+			opts:     FormatOptions{},
+			expected: "django==3.2.0 --hash=sha256:aaaa --hash=sha256:bbbb # pinned",
+		},
+		{
+			name:     "Multiline hashes",
+			opts:     FormatOptions{MultilineHashes: true},
+			expected: "django==3.2.0 \\\n    --hash=sha256:aaaa \\\n    --hash=sha256:bbbb # pinned",
+		},
+		{
+			name:     "Custom indent and newline",
+			opts:     FormatOptions{MultilineHashes: true, Indent: "  ", Newline: "\r\n", OmitComment: true},
+			expected: "django==3.2.0 \\\r\n  --hash=sha256:aaaa \\\r\n  --hash=sha256:bbbb",
+		},
+		{
+			name:     "Omit hashes and comment",
+			opts:     FormatOptions{OmitHashes: true, OmitComment: true},
+			expected: "django==3.2.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := req.Format(tt.opts); got != tt.expected {
+				t.Errorf("Format() = %q, 期望 %q", got, tt.expected)
+			}
+		})
+	}
+}
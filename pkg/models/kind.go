@@ -0,0 +1,147 @@
+package models
+
+// Kind 表示requirements文件中一行的类型
+//
+// Kind与Requirement上的布尔字段（IsComment、IsURL等）并存：解析器会同时设置两者，
+// 布尔字段为兼容旧代码保留。手动构造或从旧版JSON反序列化的Requirement可能没有Kind，
+// 此时使用EffectiveKind从布尔字段推断。
+type Kind string
+
+const (
+	// KindPackage 普通包依赖，如"flask==2.0.1"
+	KindPackage Kind = "package"
+
+	// KindDirectRef PEP 508直接引用，如"pkg @ https://example.com/pkg.whl"
+	KindDirectRef Kind = "direct_ref"
+
+	// KindURL URL安装，如"https://example.com/pkg.whl"
+	KindURL Kind = "url"
+
+	// KindLocalPath 本地路径安装，如"./libs/core"或"-e ."
+	KindLocalPath Kind = "local_path"
+
+	// KindVCS 版本控制系统URL，如"git+https://github.com/user/project.git"
+	KindVCS Kind = "vcs"
+
+	// KindInclude 引用其他requirements文件，如"-r base.txt"
+	KindInclude Kind = "include"
+
+	// KindConstraint 引用约束文件，如"-c constraints.txt"
+	KindConstraint Kind = "constraint"
+
+	// KindGlobalOption 全局选项，如"--index-url https://pypi.example.com"
+	KindGlobalOption Kind = "global_option"
+
+	// KindComment 注释行
+	KindComment Kind = "comment"
+
+	// KindBlank 空行
+	KindBlank Kind = "blank"
+)
+
+// Kinds 返回所有已知的类型，按上面常量的声明顺序排列
+func Kinds() []Kind {
+	return []Kind{
+		KindPackage, KindDirectRef, KindURL, KindLocalPath, KindVCS,
+		KindInclude, KindConstraint, KindGlobalOption, KindComment, KindBlank,
+	}
+}
+
+// String 返回类型名称
+func (k Kind) String() string {
+	return string(k)
+}
+
+// IsValid 检查是否为已知的类型
+func (k Kind) IsValid() bool {
+	for _, kind := range Kinds() {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// IsInstallable 检查该类型是否表示一个要安装的依赖
+// （普通包、直接引用、URL、本地路径或VCS）
+func (k Kind) IsInstallable() bool {
+	switch k {
+	case KindPackage, KindDirectRef, KindURL, KindLocalPath, KindVCS:
+		return true
+	}
+	return false
+}
+
+// IsDirective 检查该类型是否为pip指令（文件引用、约束文件或全局选项）
+func (k Kind) IsDirective() bool {
+	switch k {
+	case KindInclude, KindConstraint, KindGlobalOption:
+		return true
+	}
+	return false
+}
+
+// IsTrivia 检查该类型是否没有语义内容（注释行或空行）
+func (k Kind) IsTrivia() bool {
+	return k == KindComment || k == KindBlank
+}
+
+// EffectiveKind 返回requirement的类型
+//
+// Kind字段已设置时直接返回，否则根据布尔字段推断，
+// 使手动构造的Requirement和旧版JSON也能得到正确的类型。
+//
+// 示例:
+//
+//	req := &models.Requirement{IsVCS: true, VCSType: "git", URL: "https://github.com/user/project.git"}
+//	req.EffectiveKind() // 返回 models.KindVCS
+func (r *Requirement) EffectiveKind() Kind {
+	if r.Kind != "" {
+		return r.Kind
+	}
+
+	switch {
+	case r.IsEmpty:
+		return KindBlank
+	case r.IsComment:
+		return KindComment
+	case r.IsFileRef:
+		return KindInclude
+	case r.IsConstraint:
+		return KindConstraint
+	case len(r.GlobalOptions) > 0:
+		return KindGlobalOption
+	case r.IsDirectRef:
+		return KindDirectRef
+	case r.IsVCS:
+		return KindVCS
+	case r.IsURL:
+		return KindURL
+	case r.IsLocalPath:
+		return KindLocalPath
+	}
+	return KindPackage
+}
+
+// IsInstallable 检查requirement是否为要安装的依赖
+//
+// 示例:
+//
+//	for _, req := range reqs {
+//	    if req.IsInstallable() {
+//	        fmt.Println(req.Name)
+//	    }
+//	}
+func (r *Requirement) IsInstallable() bool {
+	return r.EffectiveKind().IsInstallable()
+}
+
+// IsDirective 检查requirement是否为pip指令（-r、-c或全局选项）
+func (r *Requirement) IsDirective() bool {
+	return r.EffectiveKind().IsDirective()
+}
+
+// IsTrivia 检查requirement是否为注释行或空行
+func (r *Requirement) IsTrivia() bool {
+	return r.EffectiveKind().IsTrivia()
+}
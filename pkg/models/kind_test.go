@@ -0,0 +1,99 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRequirement_EffectiveKind(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      *Requirement
+		expected Kind
+	}{
+		{"Explicit kind wins", &Requirement{Kind: KindURL, Name: "pkg"}, KindURL},
+		{"Package", &Requirement{Name: "flask"}, KindPackage},
+		{"Blank", &Requirement{IsEmpty: true}, KindBlank},
+		{"Comment", &Requirement{IsComment: true, Comment: "x"}, KindComment},
+		{"Include", &Requirement{IsFileRef: true, FileRef: "base.txt"}, KindInclude},
+		{"Constraint", &Requirement{IsConstraint: true, ConstraintFile: "c.txt"}, KindConstraint},
+		{"Global option", &Requirement{GlobalOptions: map[string]string{"pre": "true"}}, KindGlobalOption},
+		{"Direct ref over URL", &Requirement{IsDirectRef: true, IsURL: true, Name: "pkg"}, KindDirectRef},
+		{"VCS", &Requirement{IsVCS: true, VCSType: "git"}, KindVCS},
+		{"URL", &Requirement{IsURL: true, URL: "https://example.com/pkg.whl"}, KindURL},
+		{"Local path", &Requirement{IsLocalPath: true, LocalPath: "."}, KindLocalPath},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.req.EffectiveKind(); got != tt.expected {
+				t.Errorf("EffectiveKind() = %q, 期望 %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestKind_Predicates(t *testing.T) {
+	tests := []struct {
+		kind        Kind
+		installable bool
+		directive   bool
+		trivia      bool
+	}{
+		{KindPackage, true, false, false},
+		{KindDirectRef, true, false, false},
+		{KindURL, true, false, false},
+		{KindLocalPath, true, false, false},
+		{KindVCS, true, false, false},
+		{KindInclude, false, true, false},
+		{KindConstraint, false, true, false},
+		{KindGlobalOption, false, true, false},
+		{KindComment, false, false, true},
+		{KindBlank, false, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind.String(), func(t *testing.T) {
+			if got := tt.kind.IsInstallable(); got != tt.installable {
+				t.Errorf("IsInstallable() = %v, 期望 %v", got, tt.installable)
+			}
+			if got := tt.kind.IsDirective(); got != tt.directive {
+				t.Errorf("IsDirective() = %v, 期望 %v", got, tt.directive)
+			}
+			if got := tt.kind.IsTrivia(); got != tt.trivia {
+				t.Errorf("IsTrivia() = %v, 期望 %v", got, tt.trivia)
+			}
+		})
+	}
+}
+
+func TestRequirement_KindJSON(t *testing.T) {
+	// 旧版JSON没有kind字段，应从布尔字段推断
+	var legacy Requirement
+	if err := json.Unmarshal([]byte(`{"name":"","is_comment":true,"comment":"x"}`), &legacy); err != nil {
+		t.Fatalf("反序列化失败: %v", err)
+	}
+	if legacy.Kind != "" || legacy.EffectiveKind() != KindComment {
+		t.Errorf("旧版JSON的Kind = %q, EffectiveKind() = %q", legacy.Kind, legacy.EffectiveKind())
+	}
+
+	data, err := json.Marshal(&Requirement{Kind: KindVCS, IsVCS: true, VCSType: "git"})
+	if err != nil {
+		t.Fatalf("序列化失败: %v", err)
+	}
+	var decoded Requirement
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("反序列化失败: %v", err)
+	}
+	if decoded.Kind != KindVCS || !decoded.IsVCS {
+		t.Errorf("往返后Kind = %q, IsVCS = %v", decoded.Kind, decoded.IsVCS)
+	}
+
+	// 未设置Kind时不输出kind字段
+	data, _ = json.Marshal(&Requirement{Name: "flask"})
+	var fields map[string]interface{}
+	_ = json.Unmarshal(data, &fields)
+	if _, ok := fields["kind"]; ok {
+		t.Errorf("未设置Kind时不应输出kind字段: %s", data)
+	}
+}
@@ -1,7 +1,34 @@
 package models
 
+// Span 记录一段源文本的位置
+//
+// 偏移量以字节计，相对于解码后的文本开头（不包括BOM）；列号同样以字节计。
+// 带行继续符的逻辑行中，一个Span可以跨越多个物理行，此时EndLine大于Line。
+type Span struct {
+	// Start 起始字节偏移（从0开始）
+	Start int `json:"start"`
+
+	// End 结束字节偏移（不包含）
+	End int `json:"end"`
+
+	// Line 起始行号（从1开始）
+	Line int `json:"line"`
+
+	// Column 起始列号（从0开始）
+	Column int `json:"column"`
+
+	// EndLine 结束位置所在的行号（从1开始）
+	EndLine int `json:"end_line"`
+
+	// EndColumn 结束列号（从0开始，不包含）
+	EndColumn int `json:"end_column"`
+}
+
 // PositionInfo 记录requirement在原始文本中的位置信息
 // 用于实现最小化diff的编辑功能
+//
+// 各部分的Span由解析器在解析时记录（见parser.Parser的RecordPositions），
+// 文本中不存在的部分为nil。
 type PositionInfo struct {
 	// LineNumber 行号（从1开始）
 	LineNumber int `json:"line_number"`
@@ -9,7 +36,7 @@ type PositionInfo struct {
 	// StartColumn 起始列号（从0开始）
 	StartColumn int `json:"start_column"`
 
-	// EndColumn 结束列号（从0开始，不包含）
+	// EndColumn 结束列号（从0开始，不包含），即第一个物理行的长度
 	EndColumn int `json:"end_column"`
 
 	// VersionStartColumn 版本约束的起始列号（如果有版本约束）
@@ -20,6 +47,30 @@ type PositionInfo struct {
 
 	// CommentStartColumn 注释的起始列号（如果有注释）
 	CommentStartColumn int `json:"comment_start_column,omitempty"`
+
+	// Offset 逻辑行在文本中的起始字节偏移
+	Offset int `json:"offset,omitempty"`
+
+	// Name 包名的位置，与Requirement.Name的文本相同
+	Name *Span `json:"name,omitempty"`
+
+	// Extras extras的位置，包括方括号
+	Extras *Span `json:"extras,omitempty"`
+
+	// Specifier 版本约束的位置，与Requirement.Version的文本相同
+	Specifier *Span `json:"specifier,omitempty"`
+
+	// Markers 环境标记表达式的位置（不包括";"），与Requirement.Markers的文本相同
+	Markers *Span `json:"markers,omitempty"`
+
+	// Options 每个requirement选项的位置（包括选项值），键与Requirement.RequirementOptions相同
+	Options map[string]Span `json:"options,omitempty"`
+
+	// Hashes 每个"--hash=..."选项的位置，与Requirement.Hashes一一对应
+	Hashes []Span `json:"hashes,omitempty"`
+
+	// Comment 行内注释的位置，从"#"开始
+	Comment *Span `json:"comment,omitempty"`
 }
 
 // Requirement 表示Python requirements.txt文件中的一个依赖项
@@ -42,8 +93,15 @@ type PositionInfo struct {
 //     {IsEditable: true, IsVCS: true, VCSType: "git", URL: "https://github.com/user/project.git", Name: "project"}
 //
 //  5. 全局选项：
-//     {GlobalOptions: map[string]string{"index-url": "https://pypi.example.com"}}
+//     {Kind: KindGlobalOption, GlobalOptions: map[string]string{"index-url": "https://pypi.example.com"}}
+//
+// Kind字段标识行的类型，IsComment、IsURL等布尔字段为兼容旧代码保留，与Kind保持一致。
 type Requirement struct {
+	// Kind 行的类型
+	// 例如：KindPackage、KindVCS、KindInclude、KindComment
+	// 解析器总会设置此字段，手动构造时可以省略，使用EffectiveKind获取实际类型
+	Kind Kind `json:"kind,omitempty"`
+
 	// Name 依赖包名称
 	// 例如："flask", "django", "requests"
 	Name string `json:"name"`
@@ -55,6 +113,7 @@ type Requirement struct {
 	// Extras 额外的特性要求
 	// 例如：对于"requests[security,socks]"，
 	// 此字段值为 []string{"security", "socks"}
+	// 本地路径、可编辑安装和URL同样可以带extras，如"-e .[dev,test]"、"./libs/core[fast]"
 	Extras []string `json:"extras,omitempty"`
 
 	// Markers 环境标记
@@ -110,12 +169,23 @@ type Requirement struct {
 
 	// LocalPath 本地文件路径
 	// 例如："./downloads/package.whl", "../package.tar.gz", "/absolute/path/package.tar.gz"
+	// 对于 file:// URL，此字段为转换后的路径，原始URL保存在URL字段中
 	LocalPath string `json:"local_path,omitempty"`
 
+	// ResolvedPath 本地路径解析后的绝对路径
+	// 与pip一致，相对路径相对于包含该行的requirements文件所在目录解析，
+	// 例如：文件 /repo/requirements/dev.txt 中的 "-e ../lib"，此字段值为 "/repo/lib"
+	ResolvedPath string `json:"resolved_path,omitempty"`
+
 	// IsEditable 是否为可编辑安装(-e/--editable)
 	// 例如：对于 "-e ./project" 或 "-e git+https://github.com/user/project.git"，此字段为 true
 	IsEditable bool `json:"is_editable,omitempty"`
 
+	// IsDirectRef 是否为PEP 508直接引用(name @ url)
+	// 例如：对于 "pkg[fast] @ https://example.com/pkg.whl"，此字段为 true，
+	// 同时 IsURL、IsVCS 或 IsLocalPath 之一表示引用的类型
+	IsDirectRef bool `json:"is_direct_ref,omitempty"`
+
 	// IsVCS 是否为版本控制系统URL
 	// 例如：对于 "git+https://github.com/user/project.git"，此字段为 true
 	IsVCS bool `json:"is_vcs,omitempty"`
@@ -139,3 +209,16 @@ type Requirement struct {
 	// 此字段值为 []string{"sha256:abcdef1234567890"}
 	Hashes []string `json:"hashes,omitempty"`
 }
+
+// Key 返回用于查找requirement的标识：有包名时为包名，否则为本地路径或URL
+//
+// 编辑器按该标识查找要修改的requirement。
+func (r *Requirement) Key() string {
+	switch {
+	case r.Name != "":
+		return r.Name
+	case r.IsLocalPath && r.LocalPath != "":
+		return r.LocalPath
+	}
+	return r.URL
+}
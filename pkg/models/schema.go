@@ -0,0 +1,193 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// schemaEnums 具名字符串类型的取值范围
+var schemaEnums = map[reflect.Type]func() []string{
+	reflect.TypeOf(Kind("")): func() []string {
+		var values []string
+		for _, kind := range Kinds() {
+			values = append(values, string(kind))
+		}
+		return values
+	},
+	reflect.TypeOf(Severity("")): func() []string {
+		return []string{string(SeverityError), string(SeverityWarning), string(SeverityInfo)}
+	},
+}
+
+// schemaOverrides 无法从Go类型推断的字段约束，键为"类型名.JSON字段名"
+var schemaOverrides = map[string]map[string]interface{}{
+	"Document.schema_version":  {"minimum": 1, "maximum": DocumentSchemaVersion},
+	"FileMetadata.line_ending": {"enum": []string{"\n", "\r\n"}},
+	"Diagnostic.line":          {"minimum": 0},
+	"PositionInfo.line_number": {"minimum": 0},
+}
+
+// schemaKindRequired 每种Requirement类型必需的字段
+//
+// Requirement的字段是否有意义取决于Kind（注释行没有包名，文件引用没有URL），
+// 因此Requirement不使用"没有omitempty即必需"的规则，而是按kind给出条件约束。
+// 没有列出的类型（注释行、空行）没有必需字段；省略kind的旧版JSON也不受约束。
+var schemaKindRequired = map[Kind][]string{
+	KindPackage:      {"name"},
+	KindDirectRef:    {"name"},
+	KindURL:          {"url"},
+	KindLocalPath:    {"local_path"},
+	KindVCS:          {"vcs_type", "url"},
+	KindInclude:      {"file_ref"},
+	KindConstraint:   {"constraint_file"},
+	KindGlobalOption: {"global_options"},
+}
+
+// DocumentJSONSchema 根据Go类型生成Document格式的JSON Schema（draft 2020-12）
+//
+// Schema通过反射生成：结构体的json标签决定属性名，没有omitempty的字段为必需字段，
+// Kind和Severity生成枚举。Requirement例外，其必需字段按kind决定（见schemaKindRequired）。发布的Schema文件由该函数生成，
+// 修改models中的类型后需要同步更新（见Makefile中的schema目标）。
+//
+// 返回:
+//   - []byte: 带缩进的JSON Schema
+//   - error: 生成失败时返回错误
+//
+// 示例:
+//
+//	schema, _ := models.DocumentJSONSchema()
+//	os.WriteFile("requirements-document.schema.json", schema, 0644)
+func DocumentJSONSchema() ([]byte, error) {
+	g := &schemaGenerator{defs: make(map[string]interface{})}
+	root, err := g.structSchema(reflect.TypeOf(Document{}))
+	if err != nil {
+		return nil, err
+	}
+
+	root["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	root["title"] = fmt.Sprintf("Python requirements document v%d", DocumentSchemaVersion)
+	root["$defs"] = g.defs
+
+	data, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("生成JSON Schema失败: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// schemaGenerator 生成JSON Schema，结构体类型保存在$defs中并通过$ref引用
+type schemaGenerator struct {
+	defs map[string]interface{}
+}
+
+// typeSchema 返回单个Go类型对应的Schema
+func (g *schemaGenerator) typeSchema(t reflect.Type) (map[string]interface{}, error) {
+	if values, ok := schemaEnums[t]; ok {
+		return map[string]interface{}{"type": "string", "enum": values()}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return g.typeSchema(t.Elem())
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}, nil
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}, nil
+	case reflect.Slice:
+		items, err := g.typeSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "array", "items": items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("不支持的map键类型: %s", t.Key())
+		}
+		values, err := g.typeSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "object", "additionalProperties": values}, nil
+	case reflect.Struct:
+		name := t.Name()
+		if _, ok := g.defs[name]; !ok {
+			// 先占位，防止递归类型无限展开
+			g.defs[name] = nil
+			schema, err := g.structSchema(t)
+			if err != nil {
+				return nil, err
+			}
+			g.defs[name] = schema
+		}
+		return map[string]interface{}{"$ref": "#/$defs/" + name}, nil
+	}
+	return nil, fmt.Errorf("不支持的类型: %s", t)
+}
+
+// structSchema 返回结构体的object Schema
+func (g *schemaGenerator) structSchema(t reflect.Type) (map[string]interface{}, error) {
+	properties := make(map[string]interface{})
+	required := []string{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+
+		schema, err := g.typeSchema(field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", t.Name(), field.Name, err)
+		}
+		for key, value := range schemaOverrides[t.Name()+"."+name] {
+			schema[key] = value
+		}
+		properties[name] = schema
+
+		if !strings.Contains(","+opts+",", ",omitempty,") {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+	if t == reflect.TypeOf(Requirement{}) {
+		schema["required"] = []string{}
+		schema["allOf"] = kindConditions()
+	}
+	return schema, nil
+}
+
+// kindConditions 返回Requirement按kind区分必需字段的if/then条件，按Kinds()的顺序排列
+func kindConditions() []interface{} {
+	var conditions []interface{}
+	for _, kind := range Kinds() {
+		fields, ok := schemaKindRequired[kind]
+		if !ok {
+			continue
+		}
+		conditions = append(conditions, map[string]interface{}{
+			"if": map[string]interface{}{
+				"properties": map[string]interface{}{"kind": map[string]interface{}{"const": string(kind)}},
+				"required":   []string{"kind"},
+			},
+			"then": map[string]interface{}{"required": fields},
+		})
+	}
+	return conditions
+}
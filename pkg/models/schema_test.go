@@ -0,0 +1,180 @@
+package models
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "更新发布的JSON Schema文件")
+
+// schemaFile 发布的JSON Schema文件路径
+var schemaFile = filepath.Join("..", "..", "docs", "public", "schema", "requirements-document.v1.schema.json")
+
+func TestDocumentJSONSchemaFile(t *testing.T) {
+	schema, err := DocumentJSONSchema()
+	if err != nil {
+		t.Fatalf("DocumentJSONSchema()失败: %v", err)
+	}
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(schemaFile), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(schemaFile, schema, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	published, err := os.ReadFile(schemaFile)
+	if err != nil {
+		t.Fatalf("读取%s失败: %v", schemaFile, err)
+	}
+	if string(published) != string(schema) {
+		t.Errorf("%s已过期，请运行 make schema 重新生成", schemaFile)
+	}
+}
+
+func TestDocumentJSONSchema(t *testing.T) {
+	data, err := DocumentJSONSchema()
+	if err != nil {
+		t.Fatalf("DocumentJSONSchema()失败: %v", err)
+	}
+
+	var schema struct {
+		Required   []string                          `json:"required"`
+		Properties map[string]map[string]interface{} `json:"properties"`
+		Defs       map[string]struct {
+			Required   []string                          `json:"required"`
+			Properties map[string]map[string]interface{} `json:"properties"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("Schema不是合法的JSON: %v", err)
+	}
+
+	if !stringsEqual(schema.Required, []string{"schema_version", "metadata", "requirements"}) {
+		t.Errorf("Document的必需字段 = %v", schema.Required)
+	}
+	if ref := schema.Properties["metadata"]["$ref"]; ref != "#/$defs/FileMetadata" {
+		t.Errorf("metadata的$ref = %v", ref)
+	}
+
+	req, ok := schema.Defs["Requirement"]
+	if !ok {
+		t.Fatalf("$defs中缺少Requirement")
+	}
+	if len(req.Required) != 0 {
+		t.Errorf("Requirement的必需字段 = %v", req.Required)
+	}
+	enum, _ := req.Properties["kind"]["enum"].([]interface{})
+	if len(enum) != len(Kinds()) {
+		t.Errorf("kind的枚举 = %v", enum)
+	}
+	if typ := req.Properties["global_options"]["type"]; typ != "object" {
+		t.Errorf("global_options的类型 = %v", typ)
+	}
+	for _, name := range []string{"PositionInfo", "FileMetadata", "Diagnostic"} {
+		if _, ok := schema.Defs[name]; !ok {
+			t.Errorf("$defs中缺少%s", name)
+		}
+	}
+}
+
+// requirementSchema 从生成的Schema中取出Requirement的定义
+func requirementSchema(t *testing.T) map[string]interface{} {
+	t.Helper()
+	data, err := DocumentJSONSchema()
+	if err != nil {
+		t.Fatalf("DocumentJSONSchema()失败: %v", err)
+	}
+	var schema struct {
+		Defs map[string]map[string]interface{} `json:"$defs"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("Schema不是合法的JSON: %v", err)
+	}
+	return schema.Defs["Requirement"]
+}
+
+// missingFields 按Schema中的required和按kind的if/then条件检查entry缺少的字段
+func missingFields(schema map[string]interface{}, entry map[string]interface{}) []string {
+	var missing []string
+	check := func(required interface{}) {
+		fields, _ := required.([]interface{})
+		for _, field := range fields {
+			if _, ok := entry[field.(string)]; !ok {
+				missing = append(missing, field.(string))
+			}
+		}
+	}
+
+	check(schema["required"])
+	conditions, _ := schema["allOf"].([]interface{})
+	for _, c := range conditions {
+		condition := c.(map[string]interface{})
+		kind := condition["if"].(map[string]interface{})["properties"].(map[string]interface{})["kind"].(map[string]interface{})["const"]
+		if entry["kind"] == kind {
+			check(condition["then"].(map[string]interface{})["required"])
+		}
+	}
+	return missing
+}
+
+func TestDocumentJSONSchemaRequiredByKind(t *testing.T) {
+	schema := requirementSchema(t)
+
+	tests := []struct {
+		name    string
+		entry   string
+		missing []string
+	}{
+		{"纯注释行", `{"kind": "comment", "comment": "开发依赖"}`, nil},
+		{"空行", `{"kind": "blank", "is_empty": true}`, nil},
+		{"普通包", `{"kind": "package", "name": "flask", "version": "==2.0.1"}`, nil},
+		{"普通包缺少包名", `{"kind": "package", "version": "==2.0.1"}`, []string{"name"}},
+		{"文件引用", `{"kind": "include", "file_ref": "base.txt"}`, nil},
+		{"文件引用缺少路径", `{"kind": "include", "name": "base"}`, []string{"file_ref"}},
+		{"VCS缺少URL", `{"kind": "vcs", "vcs_type": "git"}`, []string{"url"}},
+		{"全局选项", `{"kind": "global_option", "global_options": {"index-url": "https://pypi.example.com"}}`, nil},
+		{"省略kind的旧版JSON", `{"is_comment": true, "comment": "旧格式"}`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var entry map[string]interface{}
+			if err := json.Unmarshal([]byte(tt.entry), &entry); err != nil {
+				t.Fatal(err)
+			}
+			if got := missingFields(schema, entry); !stringsEqual(got, tt.missing) {
+				t.Errorf("缺少的字段 = %v, 期望 %v", got, tt.missing)
+			}
+		})
+	}
+}
+
+func TestDocumentJSONSchemaCommentOnlyDocument(t *testing.T) {
+	schema := requirementSchema(t)
+
+	doc := NewDocument([]*Requirement{{Kind: KindComment, IsComment: true, Comment: "仅包含注释"}}, FileMetadata{})
+	data, err := MarshalDocument(doc)
+	if err != nil {
+		t.Fatalf("MarshalDocument()失败: %v", err)
+	}
+
+	var encoded struct {
+		Requirements []map[string]interface{} `json:"requirements"`
+	}
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		t.Fatal(err)
+	}
+	for i, entry := range encoded.Requirements {
+		// Go总是输出"name"，其他语言的工具生成注释行时通常会省略它
+		delete(entry, "name")
+		if missing := missingFields(schema, entry); len(missing) != 0 {
+			t.Errorf("requirements[%d]缺少字段%v: %v", i, missing, entry)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Version 按PEP 440解析的版本号
+//
+// 使用ParseVersion创建。"1.0"与"1.0.0"、"1.0-1"与"1.0.post1"等等价写法解析为相同的版本。
+type Version struct {
+	epoch   int
+	release []int
+
+	// pre 预发布阶段（0=a、1=b、2=rc）和序号，没有预发布时phase为-1
+	prePhase, preNumber int
+
+	// post、dev 没有时为-1
+	post, dev int
+
+	// local 规范化的本地版本标签（"+"之后的部分），没有时为空
+	local string
+}
+
+// prePhases 预发布阶段的各种写法
+var prePhases = map[string]int{
+	"a": 0, "alpha": 0,
+	"b": 1, "beta": 1,
+	"c": 2, "rc": 2, "pre": 2, "preview": 2,
+}
+
+// ParseVersion 解析PEP 440版本号
+//
+// 参数:
+//   - s: 版本号，如"2.0.1"、"1.0rc1"、"1!2.0.post1"、"1.0+cpu"
+//
+// 返回:
+//   - Version: 解析结果
+//   - bool: 不支持的写法（如通配符"2.*"）返回false
+//
+// 示例:
+//
+//	a, _ := models.ParseVersion("1.0")
+//	b, _ := models.ParseVersion("1.0.0")
+//	a.Compare(b) // 返回 0
+func ParseVersion(s string) (Version, bool) {
+	v := Version{prePhase: -1, post: -1, dev: -1}
+
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.TrimPrefix(s, "v")
+	if idx := strings.IndexByte(s, '+'); idx != -1 {
+		v.local = strings.NewReplacer("-", ".", "_", ".").Replace(s[idx+1:])
+		if v.local == "" {
+			return v, false
+		}
+		s = s[:idx]
+	}
+	if idx := strings.IndexByte(s, '!'); idx != -1 {
+		epoch, err := strconv.Atoi(s[:idx])
+		if err != nil {
+			return v, false
+		}
+		v.epoch = epoch
+		s = s[idx+1:]
+	}
+
+	// 发布段：以"."分隔的数字
+	for {
+		n, rest := leadingNumber(s)
+		if rest == s {
+			return v, false
+		}
+		v.release = append(v.release, n)
+		s = rest
+		if !strings.HasPrefix(s, ".") {
+			break
+		}
+		if _, after := leadingNumber(s[1:]); after == s[1:] {
+			break
+		}
+		s = s[1:]
+	}
+
+	// 预发布、后发布和开发版本段，各段之前可以有"."、"-"或"_"
+	for s != "" {
+		s = strings.TrimLeft(s, ".-_")
+		switch {
+		case strings.HasPrefix(s, "post"):
+			v.post, s = leadingNumber(strings.TrimLeft(s[4:], ".-_"))
+		case strings.HasPrefix(s, "rev"):
+			v.post, s = leadingNumber(strings.TrimLeft(s[3:], ".-_"))
+		case strings.HasPrefix(s, "r") && !strings.HasPrefix(s, "rc"):
+			v.post, s = leadingNumber(strings.TrimLeft(s[1:], ".-_"))
+		case strings.HasPrefix(s, "dev"):
+			v.dev, s = leadingNumber(strings.TrimLeft(s[3:], ".-_"))
+		case s != "" && s[0] >= '0' && s[0] <= '9' && v.prePhase == -1 && v.post == -1:
+			// "1.0-1"形式的隐式后发布版本
+			v.post, s = leadingNumber(s)
+		default:
+			phase, word := -1, ""
+			for w, p := range prePhases {
+				if strings.HasPrefix(s, w) && len(w) > len(word) {
+					phase, word = p, w
+				}
+			}
+			if phase == -1 || v.prePhase != -1 {
+				return v, false
+			}
+			v.prePhase = phase
+			v.preNumber, s = leadingNumber(strings.TrimLeft(s[len(word):], ".-_"))
+		}
+	}
+	return v, true
+}
+
+// leadingNumber 解析开头的数字，没有数字时返回0并原样返回s
+func leadingNumber(s string) (int, string) {
+	end := 0
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	if end == 0 {
+		return 0, s
+	}
+	n, err := strconv.Atoi(s[:end])
+	if err != nil {
+		return 0, s
+	}
+	return n, s[end:]
+}
+
+// Compare 按PEP 440的顺序比较两个版本，返回-1、0或1
+//
+// 本地版本标签不参与比较，需要时使用Local单独比较。
+func (v Version) Compare(other Version) int {
+	if c := compareInt(v.epoch, other.epoch); c != 0 {
+		return c
+	}
+	for i := 0; i < len(v.release) || i < len(other.release); i++ {
+		if c := compareInt(at(v.release, i), at(other.release, i)); c != 0 {
+			return c
+		}
+	}
+	if c := compareInt(v.preKey(), other.preKey()); c != 0 {
+		return c
+	}
+	if v.prePhase != -1 && other.prePhase != -1 {
+		if c := compareInt(v.preNumber, other.preNumber); c != 0 {
+			return c
+		}
+	}
+	if c := compareInt(v.post, other.post); c != 0 {
+		return c
+	}
+	return compareInt(v.devKey(), other.devKey())
+}
+
+// Local 返回规范化的本地版本标签（小写，"-"和"_"替换为"."），没有时返回空字符串
+func (v Version) Local() string {
+	return v.local
+}
+
+// preKey 预发布阶段的排序键：只有开发版本的发布最小，正式发布最大
+func (v Version) preKey() int {
+	switch {
+	case v.prePhase != -1:
+		return v.prePhase
+	case v.dev != -1 && v.post == -1:
+		return -1
+	}
+	return 3
+}
+
+// devKey 开发版本的排序键：没有开发版本时最大
+func (v Version) devKey() int {
+	if v.dev == -1 {
+		return int(^uint(0) >> 1)
+	}
+	return v.dev
+}
+
+// at 返回第i个发布段，不存在时为0
+func at(release []int, i int) int {
+	if i < len(release) {
+		return release[i]
+	}
+	return 0
+}
+
+// compareInt 比较两个整数
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,60 @@
+package models
+
+import "testing"
+
+func TestVersionCompare(t *testing.T) {
+	// 按PEP 440从小到大排列
+	ordered := []string{
+		"1.0.dev1", "1.0a1.dev1", "1.0a1", "1.0a2", "1.0b1", "1.0rc1", "1.0",
+		"1.0.post1.dev1", "1.0.post1", "1.0.1", "1.1", "2.0", "1!0.1",
+	}
+	for i := range ordered {
+		for j := range ordered {
+			a, ok := ParseVersion(ordered[i])
+			if !ok {
+				t.Fatalf("无法解析 %q", ordered[i])
+			}
+			b, _ := ParseVersion(ordered[j])
+			if got, want := a.Compare(b), compareInt(i, j); got != want {
+				t.Errorf("Compare(%q, %q) = %d, 期望 %d", ordered[i], ordered[j], got, want)
+			}
+		}
+	}
+}
+
+func TestVersionEquivalentSpellings(t *testing.T) {
+	tests := [][2]string{
+		{"1.0", "1.0.0"},
+		{"1.0-1", "1.0.post1"},
+		{"1.0rev2", "1.0.post2"},
+		{"1.0alpha1", "1.0a1"},
+		{"1.0c1", "1.0rc1"},
+		{"v2.0", "2.0+local"},
+	}
+	for _, tt := range tests {
+		a, ok1 := ParseVersion(tt[0])
+		b, ok2 := ParseVersion(tt[1])
+		if !ok1 || !ok2 || a.Compare(b) != 0 {
+			t.Errorf("%q 与 %q 应相等", tt[0], tt[1])
+		}
+	}
+}
+
+func TestVersionLocal(t *testing.T) {
+	tests := []struct {
+		version string
+		local   string
+		ok      bool
+	}{
+		{"1.0", "", true},
+		{"1.0+CPU", "cpu", true},
+		{"1.0+ubuntu-1_2", "ubuntu.1.2", true},
+		{"1.0+", "", false},
+	}
+	for _, tt := range tests {
+		v, ok := ParseVersion(tt.version)
+		if ok != tt.ok || (ok && v.Local() != tt.local) {
+			t.Errorf("ParseVersion(%q).Local() = %q, %v, 期望 %q, %v", tt.version, v.Local(), ok, tt.local, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package parser
+
+import (
+	"strings"
+	"unicode"
+)
+
+// splitComment 按照pip的规则分离行内注释
+//
+// pip只把位于行首或前面是空白字符的#视为注释开始，因此URL片段中的#
+// （如#egg=、#sha256=、#subdirectory=）不会被当作注释。此外，位于引号内的#
+// （如环境标记中的字符串值）也不会开始注释；如果引号没有闭合，则按未加引号处理。
+//
+// 参数:
+//   - line: 要处理的文本行
+//
+// 返回:
+//   - string: 去掉注释后的内容（已去除首尾空白）
+//   - string: 注释内容（不含#，已去除首尾空白）
+//   - int: #在line中的位置，没有注释时为-1
+//
+// 示例:
+//
+//	splitComment("flask==2.0.1  # Web framework")
+//	// 返回: "flask==2.0.1", "Web framework", 14
+//
+//	splitComment("https://example.com/pkg.whl#sha256=abcd")
+//	// 返回: "https://example.com/pkg.whl#sha256=abcd", "", -1
+//
+//	splitComment(`pkg; platform_version == "#1 SMP" # kernel`)
+//	// 返回: `pkg; platform_version == "#1 SMP"`, "kernel", 34
+func splitComment(line string) (string, string, int) {
+	idx := findCommentStart(line, true)
+	if idx == -1 {
+		// 引号未闭合时退回到不考虑引号的规则
+		idx = findCommentStart(line, false)
+	}
+
+	if idx < 0 {
+		return strings.TrimSpace(line), "", -1
+	}
+
+	return strings.TrimSpace(line[:idx]), strings.TrimSpace(line[idx+1:]), idx
+}
+
+// CommentStart 返回行内注释开始的#的位置，没有注释时返回-1
+//
+// 判断规则与解析器一致（参见splitComment），供编辑器定位注释使用。
+//
+// 示例:
+//
+//	CommentStart("flask==2.0.1  # Web framework")            // 返回14
+//	CommentStart("https://example.com/pkg.whl#sha256=abcd")  // 返回-1
+func CommentStart(line string) int {
+	_, _, idx := splitComment(line)
+	return idx
+}
+
+// IsCommentLine 检查是否为整行注释，即第一个非空白字符为#
+//
+// 与pip的join_lines一致，只有整行注释以"\"结尾时不与下一行合并；
+// 行内注释后的"\"仍然会合并下一行，下一行的内容随后成为注释的一部分。
+//
+// 示例:
+//
+//	IsCommentLine("  # note \\")         // 返回true
+//	IsCommentLine("flask==1.0 # c \\")   // 返回false
+func IsCommentLine(line string) bool {
+	return strings.HasPrefix(strings.TrimLeftFunc(line, unicode.IsSpace), "#")
+}
+
+// findCommentStart 查找注释开始的#的位置
+//
+// 当respectQuotes为true时，引号内的#会被忽略；如果扫描结束时引号仍未闭合，
+// 返回-1表示需要按不考虑引号的规则重新扫描。没有注释时返回-2。
+func findCommentStart(line string, respectQuotes bool) int {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case respectQuotes && (c == '\'' || c == '"'):
+			quote = c
+		case c == '#':
+			if i == 0 || line[i-1] == ' ' || line[i-1] == '\t' {
+				return i
+			}
+		}
+	}
+
+	if quote != 0 {
+		return -1
+	}
+	return -2
+}
@@ -0,0 +1,183 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestSplitComment(t *testing.T) {
+	testCases := []struct {
+		name        string
+		input       string
+		wantContent string
+		wantComment string
+		wantIndex   int
+	}{
+		{
+			name:        "行内注释",
+			input:       "flask==2.0.1  # Web framework",
+			wantContent: "flask==2.0.1",
+			wantComment: "Web framework",
+			wantIndex:   14,
+		},
+		{
+			name:        "行首注释",
+			input:       "# just a comment",
+			wantContent: "",
+			wantComment: "just a comment",
+			wantIndex:   0,
+		},
+		{
+			name:        "制表符后的注释",
+			input:       "flask\t#comment",
+			wantContent: "flask",
+			wantComment: "comment",
+			wantIndex:   6,
+		},
+		{
+			name:        "没有注释",
+			input:       "requests>=2.25.0",
+			wantContent: "requests>=2.25.0",
+			wantIndex:   -1,
+		},
+		{
+			name:        "egg片段",
+			input:       "git+https://github.com/user/project.git#egg=project",
+			wantContent: "git+https://github.com/user/project.git#egg=project",
+			wantIndex:   -1,
+		},
+		{
+			name:        "sha256片段",
+			input:       "https://example.com/pkg.whl#sha256=abcdef",
+			wantContent: "https://example.com/pkg.whl#sha256=abcdef",
+			wantIndex:   -1,
+		},
+		{
+			name:        "subdirectory片段和注释",
+			input:       "git+https://github.com/user/repo.git#subdirectory=pkg # monorepo",
+			wantContent: "git+https://github.com/user/repo.git#subdirectory=pkg",
+			wantComment: "monorepo",
+			wantIndex:   54,
+		},
+		{
+			name:        "紧贴内容的#不是注释",
+			input:       "flask==2.0.1#not-a-comment",
+			wantContent: "flask==2.0.1#not-a-comment",
+			wantIndex:   -1,
+		},
+		{
+			name:        "双引号环境标记中的#",
+			input:       `pkg; platform_version == "#1 SMP" # kernel`,
+			wantContent: `pkg; platform_version == "#1 SMP"`,
+			wantComment: "kernel",
+			wantIndex:   34,
+		},
+		{
+			name:        "单引号环境标记中的#",
+			input:       `pkg; platform_version == ' #1 SMP'`,
+			wantContent: `pkg; platform_version == ' #1 SMP'`,
+			wantIndex:   -1,
+		},
+		{
+			name:        "未闭合引号",
+			input:       `pkg; python_version == '3.8 # broken`,
+			wantContent: `pkg; python_version == '3.8`,
+			wantComment: "broken",
+			wantIndex:   28,
+		},
+		{
+			name:        "注释中的引号",
+			input:       `flask # it's "stable"`,
+			wantContent: "flask",
+			wantComment: `it's "stable"`,
+			wantIndex:   6,
+		},
+		{
+			name:        "注释中的#",
+			input:       "flask # see #123",
+			wantContent: "flask",
+			wantComment: "see #123",
+			wantIndex:   6,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			content, comment, idx := splitComment(tc.input)
+
+			if content != tc.wantContent {
+				t.Errorf("splitComment(%q) content = %q, want %q", tc.input, content, tc.wantContent)
+			}
+			if comment != tc.wantComment {
+				t.Errorf("splitComment(%q) comment = %q, want %q", tc.input, comment, tc.wantComment)
+			}
+			if idx != tc.wantIndex {
+				t.Errorf("splitComment(%q) index = %d, want %d", tc.input, idx, tc.wantIndex)
+			}
+			if got := CommentStart(tc.input); got != tc.wantIndex {
+				t.Errorf("CommentStart(%q) = %d, want %d", tc.input, got, tc.wantIndex)
+			}
+		})
+	}
+}
+
+func TestIsCommentLine(t *testing.T) {
+	testCases := map[string]bool{
+		"# note":            true,
+		"  # note \\":       true,
+		"\t#":               true,
+		"flask==1.0 # c \\": false,
+		"flask":             false,
+		"":                  false,
+	}
+
+	for line, want := range testCases {
+		if got := IsCommentLine(line); got != want {
+			t.Errorf("IsCommentLine(%q) = %v, want %v", line, got, want)
+		}
+	}
+}
+
+func TestParseLineCommentRules(t *testing.T) {
+	testCases := []struct {
+		name        string
+		input       string
+		wantURL     string
+		wantMarkers string
+		wantComment string
+	}{
+		{
+			name:    "URL哈希片段",
+			input:   "https://example.com/pkg-1.0.whl#sha256=abcdef0123456789",
+			wantURL: "https://example.com/pkg-1.0.whl#sha256=abcdef0123456789",
+		},
+		{
+			name:        "URL片段后的注释",
+			input:       "https://example.com/pkg-1.0.whl#sha256=abcdef # pinned",
+			wantURL:     "https://example.com/pkg-1.0.whl#sha256=abcdef",
+			wantComment: "pinned",
+		},
+		{
+			name:        "环境标记中的#",
+			input:       `pkg==1.0; platform_version == "#1 SMP" # kernel build`,
+			wantMarkers: `platform_version == "#1 SMP"`,
+			wantComment: "kernel build",
+		},
+	}
+
+	p := New()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := p.parseLine(tc.input)
+
+			if req.URL != tc.wantURL {
+				t.Errorf("parseLine(%q).URL = %q, want %q", tc.input, req.URL, tc.wantURL)
+			}
+			if req.Markers != tc.wantMarkers {
+				t.Errorf("parseLine(%q).Markers = %q, want %q", tc.input, req.Markers, tc.wantMarkers)
+			}
+			if req.Comment != tc.wantComment {
+				t.Errorf("parseLine(%q).Comment = %q, want %q", tc.input, req.Comment, tc.wantComment)
+			}
+		})
+	}
+}
@@ -0,0 +1,337 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Encoding 描述requirements文件的文本编码
+//
+// 与pip一致，编码由文件开头的BOM或前两行中的PEP 263编码声明
+// （如"# -*- coding: latin-1 -*-"）决定，否则默认为UTF-8。
+// 编辑器使用它把修改后的内容按原编码和BOM状态写回。
+type Encoding struct {
+	// Name 规范化的编码名称
+	// 例如："utf-8", "utf-16-le", "latin-1", "cp1252"
+	Name string `json:"name"`
+
+	// BOM 文件开头是否带有字节顺序标记
+	BOM bool `json:"bom,omitempty"`
+}
+
+// 支持的编码名称
+const (
+	EncodingUTF8    = "utf-8"
+	EncodingUTF16LE = "utf-16-le"
+	EncodingUTF16BE = "utf-16-be"
+	EncodingUTF32LE = "utf-32-le"
+	EncodingUTF32BE = "utf-32-be"
+	EncodingLatin1  = "latin-1"
+	EncodingCP1252  = "cp1252"
+	EncodingASCII   = "ascii"
+)
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+	bomUTF32LE = []byte{0xFF, 0xFE, 0x00, 0x00}
+	bomUTF32BE = []byte{0x00, 0x00, 0xFE, 0xFF}
+
+	// codingRegex 匹配PEP 263编码声明
+	// 例如: "# -*- coding: latin-1 -*-" 或 "# vim: set fileencoding=utf-8 :"
+	codingRegex = regexp.MustCompile(`^[ \t\f]*#.*?coding[:=][ \t]*([-\w.]+)`)
+
+	// encodingAliases 编码名称别名到规范名称的映射
+	encodingAliases = map[string]string{
+		"utf-8":        EncodingUTF8,
+		"utf8":         EncodingUTF8,
+		"utf-8-sig":    EncodingUTF8,
+		"u8":           EncodingUTF8,
+		"utf-16":       EncodingUTF16LE,
+		"utf16":        EncodingUTF16LE,
+		"utf-16-le":    EncodingUTF16LE,
+		"utf-16le":     EncodingUTF16LE,
+		"utf-16-be":    EncodingUTF16BE,
+		"utf-16be":     EncodingUTF16BE,
+		"utf-32":       EncodingUTF32LE,
+		"utf-32-le":    EncodingUTF32LE,
+		"utf-32le":     EncodingUTF32LE,
+		"utf-32-be":    EncodingUTF32BE,
+		"utf-32be":     EncodingUTF32BE,
+		"latin-1":      EncodingLatin1,
+		"latin1":       EncodingLatin1,
+		"l1":           EncodingLatin1,
+		"iso-8859-1":   EncodingLatin1,
+		"iso8859-1":    EncodingLatin1,
+		"iso-latin-1":  EncodingLatin1,
+		"cp1252":       EncodingCP1252,
+		"windows-1252": EncodingCP1252,
+		"ascii":        EncodingASCII,
+		"us-ascii":     EncodingASCII,
+	}
+
+	// cp1252High cp1252中0x80-0x9F字节对应的字符，0表示未定义（按latin-1处理）
+	cp1252High = [32]rune{
+		0x20AC, 0, 0x201A, 0x0192, 0x201E, 0x2026, 0x2020, 0x2021,
+		0x02C6, 0x2030, 0x0160, 0x2039, 0x0152, 0, 0x017D, 0,
+		0, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+		0x02DC, 0x2122, 0x0161, 0x203A, 0x0153, 0, 0x017E, 0x0178,
+	}
+)
+
+// normalizeEncodingName 将编码名称规范化，不支持的编码返回空字符串
+func normalizeEncodingName(name string) string {
+	key := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "_", "-")
+	return encodingAliases[key]
+}
+
+// DetectEncoding 检测requirements文件内容的编码
+//
+// 检测顺序与pip相同：先检查UTF-32/UTF-16/UTF-8的BOM，再检查前两行的
+// PEP 263编码声明，都没有时默认为UTF-8。
+//
+// 参数:
+//   - data: 文件的原始字节
+//
+// 返回:
+//   - Encoding: 检测到的编码
+//   - error: 编码声明指定了不支持的编码时返回错误
+//
+// 示例:
+//
+//	enc, _ := parser.DetectEncoding([]byte("\xef\xbb\xbfflask==2.0.1"))
+//	// enc = parser.Encoding{Name: "utf-8", BOM: true}
+//
+//	enc, _ := parser.DetectEncoding([]byte("# -*- coding: latin-1 -*-\nflask"))
+//	// enc = parser.Encoding{Name: "latin-1"}
+func DetectEncoding(data []byte) (Encoding, error) {
+	switch {
+	case bytes.HasPrefix(data, bomUTF32LE):
+		return Encoding{Name: EncodingUTF32LE, BOM: true}, nil
+	case bytes.HasPrefix(data, bomUTF32BE):
+		return Encoding{Name: EncodingUTF32BE, BOM: true}, nil
+	case bytes.HasPrefix(data, bomUTF8):
+		return Encoding{Name: EncodingUTF8, BOM: true}, nil
+	case bytes.HasPrefix(data, bomUTF16LE):
+		return Encoding{Name: EncodingUTF16LE, BOM: true}, nil
+	case bytes.HasPrefix(data, bomUTF16BE):
+		return Encoding{Name: EncodingUTF16BE, BOM: true}, nil
+	}
+
+	// PEP 263: 编码声明只能出现在前两行
+	rest := data
+	for i := 0; i < 2 && len(rest) > 0; i++ {
+		line := rest
+		if idx := bytes.IndexByte(rest, '\n'); idx != -1 {
+			line, rest = rest[:idx], rest[idx+1:]
+		} else {
+			rest = nil
+		}
+
+		if matches := codingRegex.FindSubmatch(line); matches != nil {
+			name := normalizeEncodingName(string(matches[1]))
+			if name == "" {
+				return Encoding{}, fmt.Errorf("不支持的编码: %s", matches[1])
+			}
+			return Encoding{Name: name}, nil
+		}
+	}
+
+	return Encoding{Name: EncodingUTF8}, nil
+}
+
+// Decode 检测编码并将文件内容解码为字符串
+//
+// 返回的字符串不包含BOM。没有编码声明的内容按UTF-8处理，
+// 即使其中包含非法的UTF-8字节也会原样保留。
+//
+// 参数:
+//   - data: 文件的原始字节
+//
+// 返回:
+//   - string: 解码后的文本
+//   - Encoding: 检测到的编码，可用于Encode写回
+//   - error: 编码不受支持或内容无法按声明的编码解码时返回错误
+//
+// 示例:
+//
+//	data, _ := os.ReadFile("requirements.txt")
+//	text, enc, err := parser.Decode(data)
+func Decode(data []byte) (string, Encoding, error) {
+	enc, err := DetectEncoding(data)
+	if err != nil {
+		return "", enc, err
+	}
+
+	if enc.BOM {
+		data = data[len(bomFor(enc.Name)):]
+	}
+
+	text, err := decodeBytes(data, enc.Name)
+	if err != nil {
+		return "", enc, err
+	}
+
+	return text, enc, nil
+}
+
+// Encode 按指定编码将文本编码为字节，需要时在开头写入BOM
+//
+// 参数:
+//   - text: 要编码的文本（不包含BOM）
+//   - enc: 目标编码，通常来自Decode或DetectEncoding
+//
+// 返回:
+//   - []byte: 编码后的字节
+//   - error: 编码不受支持或文本包含目标编码无法表示的字符时返回错误
+//
+// 示例:
+//
+//	data, err := parser.Encode("flask==2.0.1\n", parser.Encoding{Name: "utf-16-le", BOM: true})
+func Encode(text string, enc Encoding) ([]byte, error) {
+	name := enc.Name
+	if name == "" {
+		name = EncodingUTF8
+	}
+	name = normalizeEncodingName(name)
+	if name == "" {
+		return nil, fmt.Errorf("不支持的编码: %s", enc.Name)
+	}
+
+	var buf bytes.Buffer
+	if enc.BOM {
+		buf.Write(bomFor(name))
+	}
+
+	switch name {
+	case EncodingUTF8:
+		buf.WriteString(text)
+	case EncodingUTF16LE, EncodingUTF16BE:
+		order := byteOrder(name)
+		for _, unit := range utf16.Encode([]rune(text)) {
+			var b [2]byte
+			order.PutUint16(b[:], unit)
+			buf.Write(b[:])
+		}
+	case EncodingUTF32LE, EncodingUTF32BE:
+		order := byteOrder(name)
+		for _, r := range text {
+			var b [4]byte
+			order.PutUint32(b[:], uint32(r))
+			buf.Write(b[:])
+		}
+	case EncodingLatin1, EncodingASCII:
+		limit := rune(0xFF)
+		if name == EncodingASCII {
+			limit = 0x7F
+		}
+		for _, r := range text {
+			if r > limit {
+				return nil, fmt.Errorf("字符 %q 无法使用%s编码", r, name)
+			}
+			buf.WriteByte(byte(r))
+		}
+	case EncodingCP1252:
+		for _, r := range text {
+			b, ok := encodeCP1252(r)
+			if !ok {
+				return nil, fmt.Errorf("字符 %q 无法使用%s编码", r, name)
+			}
+			buf.WriteByte(b)
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// decodeBytes 按规范化的编码名称解码字节
+func decodeBytes(data []byte, name string) (string, error) {
+	switch name {
+	case EncodingUTF8:
+		return string(data), nil
+	case EncodingUTF16LE, EncodingUTF16BE:
+		if len(data)%2 != 0 {
+			return "", fmt.Errorf("%s内容长度不是2的倍数", name)
+		}
+		order := byteOrder(name)
+		units := make([]uint16, len(data)/2)
+		for i := range units {
+			units[i] = order.Uint16(data[i*2:])
+		}
+		return string(utf16.Decode(units)), nil
+	case EncodingUTF32LE, EncodingUTF32BE:
+		if len(data)%4 != 0 {
+			return "", fmt.Errorf("%s内容长度不是4的倍数", name)
+		}
+		order := byteOrder(name)
+		var sb strings.Builder
+		for i := 0; i < len(data); i += 4 {
+			r := rune(order.Uint32(data[i:]))
+			if !utf8.ValidRune(r) {
+				return "", fmt.Errorf("%s内容包含无效字符: %#x", name, uint32(r))
+			}
+			sb.WriteRune(r)
+		}
+		return sb.String(), nil
+	case EncodingLatin1, EncodingASCII, EncodingCP1252:
+		var sb strings.Builder
+		sb.Grow(len(data))
+		for _, b := range data {
+			if name == EncodingASCII && b > 0x7F {
+				return "", fmt.Errorf("字节 %#x 无法使用ascii解码", b)
+			}
+			if name == EncodingCP1252 && b >= 0x80 && b <= 0x9F && cp1252High[b-0x80] != 0 {
+				sb.WriteRune(cp1252High[b-0x80])
+				continue
+			}
+			sb.WriteRune(rune(b))
+		}
+		return sb.String(), nil
+	}
+
+	return "", fmt.Errorf("不支持的编码: %s", name)
+}
+
+// encodeCP1252 将字符编码为cp1252字节
+func encodeCP1252(r rune) (byte, bool) {
+	if r < 0x80 || (r >= 0xA0 && r <= 0xFF) {
+		return byte(r), true
+	}
+	for i, c := range cp1252High {
+		if c == r || (c == 0 && r == rune(0x80+i)) {
+			return byte(0x80 + i), true
+		}
+	}
+	return 0, false
+}
+
+// bomFor 返回编码对应的BOM字节，没有BOM的编码返回nil
+func bomFor(name string) []byte {
+	switch name {
+	case EncodingUTF8:
+		return bomUTF8
+	case EncodingUTF16LE:
+		return bomUTF16LE
+	case EncodingUTF16BE:
+		return bomUTF16BE
+	case EncodingUTF32LE:
+		return bomUTF32LE
+	case EncodingUTF32BE:
+		return bomUTF32BE
+	}
+	return nil
+}
+
+// byteOrder 返回UTF-16/UTF-32编码的字节序
+func byteOrder(name string) binary.ByteOrder {
+	if strings.HasSuffix(name, "-be") {
+		return binary.BigEndian
+	}
+	return binary.LittleEndian
+}
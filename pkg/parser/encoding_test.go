@@ -0,0 +1,167 @@
+package parser
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf16"
+)
+
+// encodeUTF16LE 测试辅助函数：将字符串编码为UTF-16LE字节
+func encodeUTF16LE(s string, bom bool) []byte {
+	var buf bytes.Buffer
+	if bom {
+		buf.Write([]byte{0xFF, 0xFE})
+	}
+	for _, unit := range utf16.Encode([]rune(s)) {
+		buf.WriteByte(byte(unit))
+		buf.WriteByte(byte(unit >> 8))
+	}
+	return buf.Bytes()
+}
+
+func TestDetectEncoding(t *testing.T) {
+	testCases := []struct {
+		name    string
+		data    []byte
+		want    Encoding
+		wantErr bool
+	}{
+		{"无BOM", []byte("flask==2.0.1\n"), Encoding{Name: EncodingUTF8}, false},
+		{"UTF-8 BOM", []byte("\xef\xbb\xbfflask\n"), Encoding{Name: EncodingUTF8, BOM: true}, false},
+		{"UTF-16LE BOM", encodeUTF16LE("flask\n", true), Encoding{Name: EncodingUTF16LE, BOM: true}, false},
+		{"UTF-16BE BOM", []byte{0xFE, 0xFF, 0x00, 'a'}, Encoding{Name: EncodingUTF16BE, BOM: true}, false},
+		{"UTF-32LE BOM", []byte{0xFF, 0xFE, 0x00, 0x00, 'a', 0, 0, 0}, Encoding{Name: EncodingUTF32LE, BOM: true}, false},
+		{"第一行编码声明", []byte("# -*- coding: latin-1 -*-\nflask\n"), Encoding{Name: EncodingLatin1}, false},
+		{"第二行编码声明", []byte("# requirements\n# vim: set fileencoding=cp1252 :\n"), Encoding{Name: EncodingCP1252}, false},
+		{"第三行编码声明无效", []byte("flask\nrequests\n# coding: latin-1\n"), Encoding{Name: EncodingUTF8}, false},
+		{"大小写和下划线", []byte("# coding=ISO_8859_1\n"), Encoding{Name: EncodingLatin1}, false},
+		{"不支持的编码", []byte("# coding: shift_jis\n"), Encoding{}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := DetectEncoding(tc.data)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("DetectEncoding() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if !tc.wantErr && got != tc.want {
+				t.Errorf("DetectEncoding() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDecodeEncodeRoundTrip(t *testing.T) {
+	testCases := []struct {
+		name string
+		data []byte
+		text string
+	}{
+		{"UTF-8", []byte("flask==2.0.1 # 框架\n"), "flask==2.0.1 # 框架\n"},
+		{"UTF-8 BOM", []byte("\xef\xbb\xbfflask==2.0.1\n"), "flask==2.0.1\n"},
+		{"UTF-16LE BOM", encodeUTF16LE("flask==2.0.1 # 框架\r\n", true), "flask==2.0.1 # 框架\r\n"},
+		{"latin-1", []byte("# -*- coding: latin-1 -*-\nflask # caf\xe9\n"), "# -*- coding: latin-1 -*-\nflask # café\n"},
+		{"cp1252", []byte("# coding: cp1252\nflask # \x93quoted\x94 \x80\n"), "# coding: cp1252\nflask # “quoted” €\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			text, enc, err := Decode(tc.data)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if text != tc.text {
+				t.Errorf("Decode() text = %q, want %q", text, tc.text)
+			}
+
+			encoded, err := Encode(text, enc)
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if !bytes.Equal(encoded, tc.data) {
+				t.Errorf("Encode() = %x, want %x", encoded, tc.data)
+			}
+		})
+	}
+}
+
+func TestEncodeErrors(t *testing.T) {
+	if _, err := Encode("flask # 框架", Encoding{Name: EncodingLatin1}); err == nil {
+		t.Error("latin-1无法表示的字符应该返回错误")
+	}
+	if _, err := Encode("flask # café", Encoding{Name: EncodingASCII}); err == nil {
+		t.Error("ascii无法表示的字符应该返回错误")
+	}
+	if _, err := Encode("flask", Encoding{Name: "ebcdic"}); err == nil {
+		t.Error("不支持的编码应该返回错误")
+	}
+	if _, _, err := Decode([]byte{0xFF, 0xFE, 'a'}); err == nil {
+		t.Error("长度为奇数的UTF-16内容应该返回错误")
+	}
+}
+
+func TestParseWithBOMAndEncodings(t *testing.T) {
+	testCases := []struct {
+		name string
+		data []byte
+	}{
+		{"UTF-8 BOM", []byte("\xef\xbb\xbfflask==2.0.1\nrequests>=2.25.0\n")},
+		{"UTF-16LE BOM", encodeUTF16LE("flask==2.0.1\r\nrequests>=2.25.0\r\n", true)},
+		{"latin-1声明", []byte("# -*- coding: latin-1 -*-\nflask==2.0.1 # caf\xe9\nrequests>=2.25.0\n")},
+	}
+
+	p := New()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reqs, err := p.Parse(bytes.NewReader(tc.data))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			var names []string
+			for _, req := range reqs {
+				if req.Name != "" {
+					names = append(names, req.Name)
+				}
+			}
+			if len(names) != 2 || names[0] != "flask" || names[1] != "requests" {
+				t.Errorf("解析得到的包名 = %q, want [flask requests]", names)
+			}
+		})
+	}
+
+	// latin-1注释应被正确解码
+	reqs, err := p.Parse(bytes.NewReader(testCases[2].data))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if reqs[1].Comment != "café" {
+		t.Errorf("Comment = %q, want %q", reqs[1].Comment, "café")
+	}
+
+	// 字符串中的BOM同样会被去掉
+	reqs, err = p.ParseString("\ufeffflask==2.0.1")
+	if err != nil {
+		t.Fatalf("ParseString() error = %v", err)
+	}
+	if reqs[0].Name != "flask" {
+		t.Errorf("ParseString() Name = %q, want %q", reqs[0].Name, "flask")
+	}
+}
+
+func TestParseFileWithUTF16(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "requirements.txt")
+	if err := os.WriteFile(path, encodeUTF16LE("django>=3.2\r\n", true), 0644); err != nil {
+		t.Fatalf("写入文件失败: %v", err)
+	}
+
+	reqs, err := New().ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	if len(reqs) != 1 || reqs[0].Name != "django" || reqs[0].Version != ">=3.2" {
+		t.Errorf("ParseFile() = %+v", reqs[0])
+	}
+}
@@ -0,0 +1,316 @@
+package parser
+
+import (
+	"strings"
+)
+
+// 本文件实现requirements行的手写词法分析
+//
+// parseLine对每一行只做一次扫描：所有函数都直接在原始字符串上移动下标，
+// 返回的字段都是输入的子串，不会为中间结果分配内存。
+// 各函数的预期行为见lexer_test.go中的表驱动测试。
+
+// fieldScanner 按空白切分字段
+//
+// 与strings.Fields不同，它按需逐个返回字段，不分配切片。
+type fieldScanner struct {
+	s   string
+	pos int
+}
+
+// next 返回下一个字段，没有更多字段时ok为false
+func (f *fieldScanner) next() (field string, ok bool) {
+	for f.pos < len(f.s) && isSpaceByte(f.s[f.pos]) {
+		f.pos++
+	}
+	if f.pos >= len(f.s) {
+		return "", false
+	}
+
+	start := f.pos
+	for f.pos < len(f.s) && !isSpaceByte(f.s[f.pos]) {
+		f.pos++
+	}
+	return f.s[start:f.pos], true
+}
+
+// peek 返回下一个字段但不移动位置
+func (f *fieldScanner) peek() (string, bool) {
+	pos := f.pos
+	field, ok := f.next()
+	f.pos = pos
+	return field, ok
+}
+
+// rest 返回尚未读取的文本，去掉首尾空白
+func (f *fieldScanner) rest() string {
+	return strings.TrimSpace(f.s[f.pos:])
+}
+
+// splitOption 将选项行拆分为选项名称和值
+//
+// 参数:
+//   - line: 去掉注释后的行，如"--index-url https://pypi.org/simple"
+//
+// 返回:
+//   - flag: 第一个字段，如"--index-url"；行不以"-"开头时为空
+//   - value: 其余部分，去掉首尾空白
+//
+// 示例:
+//
+//	splitOption("-r  base.txt")  // 返回"-r", "base.txt"
+//	splitOption("--no-index")    // 返回"--no-index", ""
+//	splitOption("flask==1.0")    // 返回"", ""
+func splitOption(line string) (flag, value string) {
+	if !strings.HasPrefix(line, "-") {
+		return "", ""
+	}
+	fs := fieldScanner{s: line}
+	flag, _ = fs.next()
+	return flag, fs.rest()
+}
+
+// scanDirectRef 识别PEP 508直接引用"name[extras] @ ref"
+//
+// 名称须以字母或数字开头，extras的方括号必须闭合，"@"之后不能为空。
+//
+// 返回:
+//   - name: 包名
+//   - extras: 方括号内的原始文本，没有extras时为空
+//   - ref: "@"之后的全部内容（包括其后的选项）
+//   - ok: 是否为直接引用
+//
+// 示例:
+//
+//	scanDirectRef("pkg[fast] @ https://example.com/pkg.whl")
+//	// 返回"pkg", "fast", "https://example.com/pkg.whl", true
+func scanDirectRef(s string) (name, extras, ref string, ok bool) {
+	if s == "" || !isAlphaNum(s[0]) {
+		return "", "", "", false
+	}
+
+	i := 1
+	for i < len(s) && isNameByte(s[i]) {
+		i++
+	}
+	name = s[:i]
+
+	i = skipSpace(s, i)
+	if i < len(s) && s[i] == '[' {
+		end := strings.IndexByte(s[i:], ']')
+		if end == -1 {
+			return "", "", "", false
+		}
+		extras = s[i+1 : i+end]
+		i = skipSpace(s, i+end+1)
+	}
+
+	if i >= len(s) || s[i] != '@' {
+		return "", "", "", false
+	}
+
+	ref = strings.TrimLeft(s[i+1:], " \t\f\r\n")
+	if ref == "" {
+		return "", "", "", false
+	}
+
+	return name, extras, ref, true
+}
+
+// vcsPrefixes 支持的版本控制系统前缀
+var vcsPrefixes = [...]string{"git", "hg", "svn", "bzr"}
+
+// splitVCS 识别版本控制系统URL，如"git+https://github.com/user/project.git"
+//
+// 前缀区分大小写，"+"之后不能为空。
+//
+// 返回:
+//   - vcsType: 版本控制系统类型，如"git"
+//   - url: "+"之后的部分
+//   - ok: 是否为VCS URL
+func splitVCS(s string) (vcsType, url string, ok bool) {
+	for _, prefix := range vcsPrefixes {
+		if len(s) > len(prefix)+1 && strings.HasPrefix(s, prefix) && s[len(prefix)] == '+' {
+			return prefix, s[len(prefix)+1:], true
+		}
+	}
+	return "", "", false
+}
+
+// parseHashOption 解析"--hash=算法:摘要"形式的哈希选项
+//
+// 哈希值的规则见ValidHash。
+//
+// 示例:
+//
+//	parseHashOption("--hash=sha256:abcd")  // 返回"sha256:abcd", true
+//	parseHashOption("--hash=sha256:XYZ")   // 返回"", false
+func parseHashOption(field string) (string, bool) {
+	value := strings.TrimPrefix(field, "--hash=")
+	if len(value) == len(field) || !ValidHash(value) {
+		return "", false
+	}
+	return value, true
+}
+
+// ValidHash 检查哈希值是否符合解析器接受的"算法:摘要"语法
+//
+// 算法由小写字母和数字组成，摘要为小写十六进制。不符合语法的哈希会被解析器丢弃，
+// 编辑器和校验器使用同一规则判断哈希是否有效。算法是否被pip支持不在检查范围内。
+//
+// 示例:
+//
+//	ValidHash("sha256:abcd")  // 返回true
+//	ValidHash("sha256:ABCD")  // 返回false
+//	ValidHash("sha256")       // 返回false
+func ValidHash(value string) bool {
+	colon := strings.IndexByte(value, ':')
+	if colon <= 0 || colon == len(value)-1 {
+		return false
+	}
+	for i := 0; i < colon; i++ {
+		if c := value[i]; !(c >= 'a' && c <= 'z') && !(c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	for i := colon + 1; i < len(value); i++ {
+		if c := value[i]; !(c >= 'a' && c <= 'f') && !(c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// packageSpec 包规格"name[extras] specifier"的各个部分
+type packageSpec struct {
+	name      string
+	extras    string
+	hasExtras bool
+	version   string
+	rest      string
+}
+
+// scanPackageSpec 识别包名、extras和版本约束
+//
+// 包名与extras、版本约束之间可以有空白，版本约束本身也可以包含空白，
+// 如"flask [async] >= 2.0, < 3.0"。版本约束在第一个既不以比较运算符或","开头、
+// 前一个字段也不以比较运算符或","结尾的字段处结束，其后的内容（通常是选项）
+// 作为rest返回。
+//
+// 示例:
+//
+//	scanPackageSpec("django[rest]>=3.2 --hash=sha256:ab")
+//	// 返回packageSpec{name: "django", extras: "rest", version: ">=3.2", rest: "--hash=sha256:ab"}
+//
+//	scanPackageSpec("flask >= 1.0, < 2.0")
+//	// 返回packageSpec{name: "flask", version: ">= 1.0, < 2.0"}
+func scanPackageSpec(s string) packageSpec {
+	var spec packageSpec
+
+	i := 0
+	for i < len(s) && !isSpaceByte(s[i]) && s[i] != '[' && s[i] != '(' && !isVersionOp(s[i]) {
+		i++
+	}
+	spec.name = s[:i]
+
+	// extras
+	if j := skipSpace(s, i); j < len(s) && s[j] == '[' {
+		if end := strings.IndexByte(s[j:], ']'); end != -1 {
+			spec.extras = s[j+1 : j+end]
+			spec.hasExtras = true
+			i = j + end + 1
+		} else {
+			// 没有闭合的方括号，跳过该字段
+			for i < len(s) && !isSpaceByte(s[i]) {
+				i++
+			}
+		}
+	}
+
+	// 版本约束
+	j := skipSpace(s, i)
+	if j >= len(s) || (s[j] != '(' && !isVersionOp(s[j])) {
+		spec.rest = strings.TrimSpace(s[i:])
+		return spec
+	}
+
+	start, end := j, j
+	continued := true
+	for j < len(s) {
+		fieldEnd := j
+		for fieldEnd < len(s) && !isSpaceByte(s[fieldEnd]) {
+			fieldEnd++
+		}
+
+		first, last := s[j], s[fieldEnd-1]
+		if !continued && first != ',' && first != '(' && !isVersionOp(first) {
+			break
+		}
+
+		end = fieldEnd
+		continued = last == ',' || isVersionOp(last)
+		j = skipSpace(s, fieldEnd)
+	}
+
+	spec.version = s[start:end]
+	spec.rest = strings.TrimSpace(s[end:])
+	return spec
+}
+
+// splitList 将逗号分隔的列表拆分为去掉空白的非空项
+//
+// 用于解析extras，如"security, socks"。结果切片按项数一次性分配。
+func splitList(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+
+	items := make([]string, 0, strings.Count(s, ",")+1)
+	for {
+		idx := strings.IndexByte(s, ',')
+		item := s
+		if idx != -1 {
+			item = s[:idx]
+		}
+		if trimmed := strings.TrimSpace(item); trimmed != "" {
+			items = append(items, trimmed)
+		}
+		if idx == -1 {
+			break
+		}
+		s = s[idx+1:]
+	}
+
+	if len(items) == 0 {
+		return nil
+	}
+	return items
+}
+
+// skipSpace 返回从i开始跳过空白后的位置
+func skipSpace(s string, i int) int {
+	for i < len(s) && isSpaceByte(s[i]) {
+		i++
+	}
+	return i
+}
+
+// isSpaceByte 检查字节是否为ASCII空白
+func isSpaceByte(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v'
+}
+
+// isAlphaNum 检查字节是否为ASCII字母或数字
+func isAlphaNum(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// isNameByte 检查字节是否可以出现在包名中
+func isNameByte(c byte) bool {
+	return isAlphaNum(c) || c == '.' || c == '_' || c == '-'
+}
+
+// isVersionOp 检查字节是否为版本比较运算符的组成字符
+func isVersionOp(c byte) bool {
+	return c == '<' || c == '>' || c == '=' || c == '~' || c == '!'
+}
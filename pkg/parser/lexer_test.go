@@ -0,0 +1,260 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScanPackageSpec(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected packageSpec
+	}{
+		{
+			input:    "flask",
+			expected: packageSpec{name: "flask"},
+		},
+		{
+			input:    "flask==2.0.1",
+			expected: packageSpec{name: "flask", version: "==2.0.1"},
+		},
+		{
+			input:    "flask >= 1.0",
+			expected: packageSpec{name: "flask", version: ">= 1.0"},
+		},
+		{
+			input:    "flask >= 1.0, < 2.0 --hash=sha256:ab",
+			expected: packageSpec{name: "flask", version: ">= 1.0, < 2.0", rest: "--hash=sha256:ab"},
+		},
+		{
+			input:    "django[rest,auth]>=3.2",
+			expected: packageSpec{name: "django", extras: "rest,auth", hasExtras: true, version: ">=3.2"},
+		},
+		{
+			input:    "uvicorn [standard] ~=0.15",
+			expected: packageSpec{name: "uvicorn", extras: "standard", hasExtras: true, version: "~=0.15"},
+		},
+		{
+			input:    "requests>=2.0 ,<3",
+			expected: packageSpec{name: "requests", version: ">=2.0 ,<3"},
+		},
+		{
+			input:    "pkg (>=1.0)",
+			expected: packageSpec{name: "pkg", version: "(>=1.0)"},
+		},
+		{
+			input:    "flask==1.0 extra words",
+			expected: packageSpec{name: "flask", version: "==1.0", rest: "extra words"},
+		},
+		{
+			input:    "flask --global-option x",
+			expected: packageSpec{name: "flask", rest: "--global-option x"},
+		},
+		{
+			input:    "broken[extra",
+			expected: packageSpec{name: "broken"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			if got := scanPackageSpec(tc.input); got != tc.expected {
+				t.Errorf("scanPackageSpec(%q) = %+v, 期望 %+v", tc.input, got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestParseLineSpacedSpecifiers(t *testing.T) {
+	testCases := []struct {
+		input   string
+		name    string
+		version string
+		extras  []string
+		hashes  []string
+	}{
+		{input: "flask >= 1.0", name: "flask", version: ">= 1.0"},
+		{input: "flask >= 1.0, < 2.0  # web", name: "flask", version: ">= 1.0, < 2.0"},
+		{input: "django [rest] == 3.2 ; python_version > '3'", name: "django", version: "== 3.2", extras: []string{"rest"}},
+		{input: "numpy == 1.21 --hash=sha256:abcd", name: "numpy", version: "== 1.21", hashes: []string{"sha256:abcd"}},
+	}
+
+	p := New()
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			req := p.parseLine(tc.input)
+			if req.Name != tc.name || req.Version != tc.version {
+				t.Errorf("parseLine(%q) = %q %q, 期望 %q %q", tc.input, req.Name, req.Version, tc.name, tc.version)
+			}
+			if !reflect.DeepEqual(req.Extras, tc.extras) {
+				t.Errorf("parseLine(%q).Extras = %v, 期望 %v", tc.input, req.Extras, tc.extras)
+			}
+			if !reflect.DeepEqual(req.Hashes, tc.hashes) {
+				t.Errorf("parseLine(%q).Hashes = %v, 期望 %v", tc.input, req.Hashes, tc.hashes)
+			}
+		})
+	}
+}
+
+func TestScanDirectRef(t *testing.T) {
+	testCases := []struct {
+		input  string
+		name   string
+		extras string
+		ref    string
+		ok     bool
+	}{
+		{"pkg @ https://example.com/pkg.whl", "pkg", "", "https://example.com/pkg.whl", true},
+		{"pkg[a, b]@file:///tmp/pkg", "pkg", "a, b", "file:///tmp/pkg", true},
+		{"pkg [a] @ git+https://github.com/u/p.git --hash=sha256:ab", "pkg", "a", "git+https://github.com/u/p.git --hash=sha256:ab", true},
+		{"pkg @ https://example.com/a;b.whl", "pkg", "", "https://example.com/a;b.whl", true},
+		{"pkg@", "", "", "", false},
+		{"pkg @  ", "", "", "", false},
+		{"pkg[a @ url", "", "", "", false}, // 方括号未闭合
+		{"_pkg @ url", "", "", "", false},
+		{"pkg==1.0", "", "", "", false},
+	}
+
+	for _, tc := range testCases {
+		name, extras, ref, ok := scanDirectRef(tc.input)
+		if name != tc.name || extras != tc.extras || ref != tc.ref || ok != tc.ok {
+			t.Errorf("scanDirectRef(%q) = %q, %q, %q, %v, 期望 %q, %q, %q, %v",
+				tc.input, name, extras, ref, ok, tc.name, tc.extras, tc.ref, tc.ok)
+		}
+	}
+}
+
+func TestSplitVCS(t *testing.T) {
+	testCases := []struct {
+		input   string
+		vcsType string
+		url     string
+		ok      bool
+	}{
+		{"git+https://github.com/user/project.git", "git", "https://github.com/user/project.git", true},
+		{"git+ssh://git@github.com/u/p.git", "git", "ssh://git@github.com/u/p.git", true},
+		{"hg+https://hg.example.com/repo", "hg", "https://hg.example.com/repo", true},
+		{"svn+", "", "", false},
+		{"gitx+https://x", "", "", false},
+		{"GIT+https://x", "", "", false},
+		{"https://github.com/user/project.git", "", "", false},
+	}
+
+	for _, tc := range testCases {
+		vcsType, url, ok := splitVCS(tc.input)
+		if vcsType != tc.vcsType || url != tc.url || ok != tc.ok {
+			t.Errorf("splitVCS(%q) = %q, %q, %v, 期望 %q, %q, %v",
+				tc.input, vcsType, url, ok, tc.vcsType, tc.url, tc.ok)
+		}
+	}
+}
+
+func TestParseHashOption(t *testing.T) {
+	testCases := []struct {
+		input string
+		hash  string
+		ok    bool
+	}{
+		{"--hash=sha256:abcdef0123", "sha256:abcdef0123", true},
+		{"--hash=sha256:ABCDEF", "", false},
+		{"--hash=md5:", "", false},
+		{"--hash=:abcd", "", false},
+		{"--hash=sha-256:abcd", "", false},
+		{"--hash=sha256:ab cd", "", false},
+		{"--hash", "", false},
+	}
+
+	for _, tc := range testCases {
+		hash, ok := parseHashOption(tc.input)
+		if hash != tc.hash || ok != tc.ok {
+			t.Errorf("parseHashOption(%q) = %q, %v, 期望 %q, %v", tc.input, hash, ok, tc.hash, tc.ok)
+		}
+	}
+}
+
+func TestSplitOption(t *testing.T) {
+	testCases := []struct {
+		input string
+		flag  string
+		value string
+	}{
+		{"-r requirements.txt", "-r", "requirements.txt"},
+		{"--requirement   base.txt", "--requirement", "base.txt"},
+		{"-c constraints.txt", "-c", "constraints.txt"},
+		{"-e ./path", "-e", "./path"},
+		{"--editable git+https://github.com/u/p.git", "--editable", "git+https://github.com/u/p.git"},
+		{"--index-url https://pypi.org/simple", "--index-url", "https://pypi.org/simple"},
+		{"-i\thttps://pypi.org/simple", "-i", "https://pypi.org/simple"},
+		{"-r", "-r", ""},
+		{"-rfile.txt", "-rfile.txt", ""},
+		{"--index-url=https://x", "--index-url=https://x", ""},
+		{"--no-index", "--no-index", ""},
+		{"flask", "", ""},
+	}
+
+	for _, tc := range testCases {
+		flag, value := splitOption(tc.input)
+		if flag != tc.flag || value != tc.value {
+			t.Errorf("splitOption(%q) = %q, %q, 期望 %q, %q", tc.input, flag, value, tc.flag, tc.value)
+		}
+	}
+}
+
+// TestParseLineLexerEdgeCases 覆盖引号、分号和未闭合方括号等边界情况
+func TestParseLineLexerEdgeCases(t *testing.T) {
+	testCases := []struct {
+		input   string
+		name    string
+		extras  []string
+		version string
+		markers string
+		url     string
+		comment string
+	}{
+		{`pkg ; extra == "#x" # c`, "pkg", nil, "", `extra == "#x"`, "", "c"},
+		{`pkg ; os_name == "nt # c`, "pkg", nil, "", `os_name == "nt`, "", "c"}, // 引号未闭合时#仍开始注释
+		{`pkg ; os_name == "a;b"`, "pkg", nil, "", `os_name == "a;b"`, "", ""},
+		{`pkg[a,b]==1.0;python_version<"3"`, "pkg", []string{"a", "b"}, "==1.0", `python_version<"3"`, "", ""},
+		{`pkg @ https://example.com/a;b.whl`, "pkg", nil, "", "", "https://example.com/a;b.whl", ""},
+		{`pkg @ https://example.com/a.whl ; os_name == "nt"`, "pkg", nil, "", `os_name == "nt"`, "https://example.com/a.whl", ""},
+		{`pkg[a ; os_name == "nt"`, "pkg", nil, "", `os_name == "nt"`, "", ""}, // 方括号未闭合
+		{`pkg[a # c`, "pkg", nil, "", "", "", "c"},
+	}
+
+	p := New()
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			req := p.parseLine(tc.input)
+			if req.Name != tc.name || req.Version != tc.version || req.Markers != tc.markers ||
+				req.URL != tc.url || req.Comment != tc.comment {
+				t.Errorf("parseLine(%q) = name %q, version %q, markers %q, url %q, comment %q, 期望 %q, %q, %q, %q, %q",
+					tc.input, req.Name, req.Version, req.Markers, req.URL, req.Comment,
+					tc.name, tc.version, tc.markers, tc.url, tc.comment)
+			}
+			if len(req.Extras) != 0 || len(tc.extras) != 0 {
+				if !reflect.DeepEqual(req.Extras, tc.extras) {
+					t.Errorf("parseLine(%q).Extras = %v, 期望 %v", tc.input, req.Extras, tc.extras)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected []string
+	}{
+		{"", nil},
+		{" , ", nil},
+		{"dev", []string{"dev"}},
+		{"security, socks", []string{"security", "socks"}},
+		{"a,,b,", []string{"a", "b"}},
+	}
+
+	for _, tc := range testCases {
+		if got := splitList(tc.input); !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("splitList(%q) = %v, 期望 %v", tc.input, got, tc.expected)
+		}
+	}
+}
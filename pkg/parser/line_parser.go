@@ -1,15 +1,12 @@
 package parser
 
 import (
-	"regexp"
 	"strings"
+	"unicode"
 
 	"github.com/scagogogo/python-requirements-parser/pkg/models"
 )
 
-// 添加正则表达式用来提取egg名称
-var eggFragmentRegex = regexp.MustCompile(`#egg=([^&]+)`)
-
 // parseLine 解析单行内容
 //
 // 此函数是解析器的核心，它将requirements.txt文件中的单行文本转换为结构化的Requirement对象。
@@ -39,11 +36,21 @@ var eggFragmentRegex = regexp.MustCompile(`#egg=([^&]+)`)
 //	req := parseLine("git+https://github.com/user/project.git#egg=project")
 //	// 返回: &models.Requirement{IsVCS: true, VCSType: "git", URL: "https://github.com/user/project.git", Name: "project"}
 func (p *Parser) parseLine(line string) *models.Requirement {
+	return p.parseLineSpans(line, nil)
+}
+
+// parseLineSpans 解析单行内容，spans不为nil时同时记录各部分在行中的位置
+//
+// 解析过程中的子串都从行首开始或延伸到行尾，因此它们在行中的位置
+// 可以由长度直接算出，不需要再次搜索。
+func (p *Parser) parseLineSpans(line string, spans *lineSpans) (req *models.Requirement) {
 	trimmedLine := strings.TrimSpace(line)
+	lead := leadingSpace(line)
 
 	// 空行
 	if trimmedLine == "" {
 		return &models.Requirement{
+			Kind:         models.KindBlank,
 			OriginalLine: line,
 			IsEmpty:      true,
 		}
@@ -51,120 +58,129 @@ func (p *Parser) parseLine(line string) *models.Requirement {
 
 	// 注释行
 	if strings.HasPrefix(strings.TrimSpace(trimmedLine), "#") {
+		if spans != nil {
+			spans.comment = span{lead, lead + len(trimmedLine)}
+		}
 		return &models.Requirement{
+			Kind:         models.KindComment,
 			OriginalLine: line,
 			IsComment:    true,
 			Comment:      strings.TrimSpace(strings.TrimPrefix(trimmedLine, "#")),
 		}
 	}
 
-	// 首先检查是否包含#egg=，如果有则不将#当作注释标记
-	eggFragmentIdx := strings.Index(trimmedLine, "#egg=")
-
 	// 处理行内注释（在继续其他解析前先移除注释）
-	var lineWithoutComment string
-	var comment string
+	// 按照pip的规则，只有位于行首或空白之后、且不在引号内的#才开始注释
+	lineWithoutComment, comment, commentIdx := splitComment(trimmedLine)
+	if spans != nil && commentIdx != -1 {
+		spans.comment = span{lead + commentIdx, lead + len(trimmedLine)}
+	}
 
-	if eggFragmentIdx != -1 {
-		// 有#egg=，不把它当作注释处理
-		lineWithoutComment = trimmedLine
-	} else {
-		commentIdx := strings.Index(trimmedLine, "#")
-		if commentIdx != -1 {
-			lineWithoutComment = strings.TrimSpace(trimmedLine[:commentIdx])
-			comment = strings.TrimSpace(trimmedLine[commentIdx+1:])
-		} else {
-			lineWithoutComment = trimmedLine
+	// 处理环境标记，环境标记之后的选项在解析完成后合并到requirement中
+	var markers, trailing string
+	var trailingOffset int
+	if markerStart, markerEnd := splitMarkers(lineWithoutComment); markerStart != -1 {
+		markers = strings.TrimSpace(lineWithoutComment[markerStart+1 : markerEnd])
+		if spans != nil && markers != "" {
+			start := lead + markerStart + 1 + leadingSpace(lineWithoutComment[markerStart+1:])
+			spans.markers = span{start, start + len(markers)}
 		}
+		trailing = strings.TrimSpace(lineWithoutComment[markerEnd:])
+		trailingOffset = lead + len(lineWithoutComment) - len(trailing)
+		lineWithoutComment = strings.TrimSpace(lineWithoutComment[:markerStart])
 	}
-
-	// 处理环境标记
-	var markers string
-	markerSplitIdx := strings.Index(lineWithoutComment, ";")
-	if markerSplitIdx != -1 {
-		markers = strings.TrimSpace(lineWithoutComment[markerSplitIdx+1:])
-		lineWithoutComment = strings.TrimSpace(lineWithoutComment[:markerSplitIdx])
+	if trailing != "" {
+		defer func() {
+			if !req.IsInstallable() {
+				return
+			}
+			options, hashes := parseRequirementOptions(trailing, spans, trailingOffset)
+			for name, value := range options {
+				if req.RequirementOptions == nil {
+					req.RequirementOptions = make(map[string]string)
+				}
+				req.RequirementOptions[name] = value
+			}
+			req.Hashes = append(req.Hashes, hashes...)
+		}()
 	}
 
-	// 检查是否为全局选项
-	if p.isGlobalOption(lineWithoutComment) {
-		req := p.parseGlobalOption(lineWithoutComment)
-		req.Comment = comment
-		req.Markers = markers
-		req.OriginalLine = line
-		return req
+	// suffixOffset 返回lineWithoutComment的后缀s在行中的位置
+	suffixOffset := func(s string) int {
+		return lead + len(lineWithoutComment) - len(s)
 	}
 
-	// 检查是否为引用其他requirements文件的行
-	if reqFileRegex.MatchString(lineWithoutComment) {
-		matches := reqFileRegex.FindStringSubmatch(lineWithoutComment)
-		return &models.Requirement{
-			OriginalLine: line,
-			IsFileRef:    true,
-			FileRef:      matches[1],
-			Comment:      comment,
-			Markers:      markers,
+	// 以"-"开头的行：全局选项、文件引用、约束文件或可编辑安装
+	if strings.HasPrefix(lineWithoutComment, "-") {
+		if p.isGlobalOption(lineWithoutComment) {
+			req := p.parseGlobalOption(lineWithoutComment)
+			req.Comment = comment
+			req.Markers = markers
+			req.OriginalLine = line
+			return req
 		}
-	}
 
-	// 检查是否为约束文件
-	if constraintRegex.MatchString(lineWithoutComment) {
-		matches := constraintRegex.FindStringSubmatch(lineWithoutComment)
-		return &models.Requirement{
-			OriginalLine:   line,
-			IsConstraint:   true,
-			ConstraintFile: matches[1],
-			Comment:        comment,
-			Markers:        markers,
+		flag, value := splitOption(lineWithoutComment)
+		if value != "" {
+			switch flag {
+			case "-r", "--requirement":
+				// 引用其他requirements文件
+				return &models.Requirement{
+					Kind:         models.KindInclude,
+					OriginalLine: line,
+					IsFileRef:    true,
+					FileRef:      value,
+					Comment:      comment,
+					Markers:      markers,
+				}
+			case "-c", "--constraint":
+				// 约束文件
+				return &models.Requirement{
+					Kind:           models.KindConstraint,
+					OriginalLine:   line,
+					IsConstraint:   true,
+					ConstraintFile: value,
+					Comment:        comment,
+					Markers:        markers,
+				}
+			case "-e", "--editable":
+				// 可编辑安装
+				req := &models.Requirement{
+					OriginalLine: line,
+					IsEditable:   true,
+					Comment:      comment,
+					Markers:      markers,
+				}
+				setEditableTarget(req, value)
+				return req
+			}
 		}
 	}
 
-	// 检查是否为可编辑安装
-	if editableRegex.MatchString(lineWithoutComment) {
-		matches := editableRegex.FindStringSubmatch(lineWithoutComment)
-		path := matches[1]
-		req := &models.Requirement{
-			OriginalLine: line,
-			IsEditable:   true,
-			Comment:      comment,
-			Markers:      markers,
-		}
+	req = &models.Requirement{
+		OriginalLine: line,
+		Comment:      comment,
+		Markers:      markers,
+	}
 
-		// 检查是否为VCS URL
-		if vcsMatches := vcsRegex.FindStringSubmatch(path); vcsMatches != nil {
-			req.IsVCS = true
-			req.VCSType = vcsMatches[1]
-			req.URL = vcsMatches[2]
-
-			// 提取egg名称
-			extractEggName(req)
-		} else if isURL(path) {
-			// 检查是否为URL
-			req.IsURL = true
-			req.URL = path
-
-			// 提取egg名称
-			extractEggName(req)
-		} else {
-			// 否则为本地路径
-			req.IsLocalPath = true
-			req.LocalPath = path
+	// 检查是否为直接引用（name[extras] @ url）
+	if name, extras, ref, ok := scanDirectRef(lineWithoutComment); ok {
+		if spans != nil {
+			spans.name = span{lead, lead + len(name)}
+			if j := skipSpace(lineWithoutComment, len(name)); lineWithoutComment[j] == '[' {
+				spans.extras = span{lead + j, lead + j + len(extras) + 2}
+			}
 		}
-
+		parseDirectRef(req, name, extras, ref, spans, suffixOffset(ref))
 		return req
 	}
 
 	// 检查是否为版本控制系统URL
-	if vcsMatches := vcsRegex.FindStringSubmatch(lineWithoutComment); vcsMatches != nil {
-		vcsUrl := vcsMatches[2]
-		req := &models.Requirement{
-			OriginalLine: line,
-			IsVCS:        true,
-			VCSType:      vcsMatches[1],
-			URL:          vcsUrl,
-			Comment:      comment,
-			Markers:      markers,
-		}
+	if vcsType, vcsURL, ok := splitVCS(lineWithoutComment); ok {
+		req.Kind = models.KindVCS
+		req.IsVCS = true
+		req.VCSType = vcsType
+		req.URL = vcsURL
 
 		// 提取egg名称
 		extractEggName(req)
@@ -174,14 +190,9 @@ func (p *Parser) parseLine(line string) *models.Requirement {
 
 	// 检查是否为URL
 	if isURL(lineWithoutComment) {
-		url := lineWithoutComment
-		req := &models.Requirement{
-			OriginalLine: line,
-			IsURL:        true,
-			URL:          url,
-			Comment:      comment,
-			Markers:      markers,
-		}
+		req.Kind = models.KindURL
+		req.IsURL = true
+		req.URL = lineWithoutComment
 
 		// 提取egg名称
 		extractEggName(req)
@@ -189,109 +200,244 @@ func (p *Parser) parseLine(line string) *models.Requirement {
 		return req
 	}
 
-	// 检查是否为本地路径（以./或../开头，或者以/开头的绝对路径，或者是.whl文件）
-	if strings.HasPrefix(lineWithoutComment, "./") ||
-		strings.HasPrefix(lineWithoutComment, "../") ||
-		strings.HasPrefix(lineWithoutComment, "/") ||
-		strings.HasSuffix(lineWithoutComment, ".whl") {
-		return &models.Requirement{
-			OriginalLine: line,
-			IsLocalPath:  true,
-			LocalPath:    lineWithoutComment,
-			Comment:      comment,
-			Markers:      markers,
+	// 检查是否为本地路径（相对路径、绝对路径、Windows路径、file:// URL或归档文件）
+	// 与pip一致，路径部分为第一个以"-"开头的选项之前的所有内容
+	fs := fieldScanner{s: lineWithoutComment}
+	if first, ok := fs.next(); ok && isLocalPath(first) {
+		pathEnd := fs.pos
+		for {
+			field, ok := fs.peek()
+			if !ok || strings.HasPrefix(field, "-") {
+				break
+			}
+			fs.next()
+			pathEnd = fs.pos
+		}
+
+		setLocalPath(req, lineWithoutComment[:pathEnd])
+		if spans != nil && len(req.Extras) > 0 {
+			path := strings.TrimRightFunc(lineWithoutComment[:pathEnd], unicode.IsSpace)
+			spans.extras = span{lead + strings.LastIndex(path, "["), lead + len(path)}
 		}
+		rest := fs.rest()
+		req.RequirementOptions, req.Hashes = parseRequirementOptions(rest, spans, suffixOffset(rest))
+		return req
+	}
+
+	// 解析包名、extras和版本约束，其后为每个requirement的选项
+	spec := scanPackageSpec(lineWithoutComment)
+	spans.packageSpec(spec, lineWithoutComment, lead)
+	req.Kind = models.KindPackage
+	req.Name = spec.name
+	req.Version = spec.version
+	if spec.hasExtras {
+		req.Extras = splitList(spec.extras)
+	}
+	if spec.rest != "" {
+		req.RequirementOptions, req.Hashes = parseRequirementOptions(spec.rest, spans, suffixOffset(spec.rest))
 	}
 
-	// 收集每个requirement的选项
+	return req
+}
+
+// setEditableTarget 解析可编辑安装的目标
+//
+// 目标可以是直接引用（"pkg[dev] @ file:///src/pkg"）、VCS URL、URL或本地路径。
+//
+// 参数:
+//   - req: 要设置的Requirement对象
+//   - target: "-e"/"--editable"之后的内容
+func setEditableTarget(req *models.Requirement, target string) {
+	// 可编辑安装也可以写成直接引用形式，如"-e pkg[dev] @ file:///src/pkg"
+	if name, extras, ref, ok := scanDirectRef(target); ok {
+		parseDirectRef(req, name, extras, ref, nil, 0)
+		return
+	}
+
+	if vcsType, vcsURL, ok := splitVCS(target); ok {
+		// VCS URL
+		req.Kind = models.KindVCS
+		req.IsVCS = true
+		req.VCSType = vcsType
+		req.URL = vcsURL
+		extractEggName(req)
+	} else if isURL(target) {
+		// URL
+		req.Kind = models.KindURL
+		req.IsURL = true
+		req.URL = target
+		extractEggName(req)
+	} else {
+		// 否则为本地路径
+		setLocalPath(req, target)
+	}
+}
+
+// parseRequirementOptions 解析requirement行中的选项部分
+//
+// 此函数处理位于包规格之后的--xxx选项，--hash选项（"--hash=值"或"--hash 值"）会被收集到哈希列表中，
+// 其他选项（"--选项=值"或"--选项 值"）以名称（去掉"--"前缀）为键保存，无值选项的值为"true"。
+//
+// 参数:
+//   - s: 包规格之后的文本
+//   - spans: 不为nil时记录每个选项和哈希的位置
+//   - offset: s在逻辑行中的位置
+//
+// 返回:
+//   - map[string]string: 选项映射，如果没有选项则为nil
+//   - []string: 哈希值列表，如"sha256:abcdef1234567890"
+//
+// 示例:
+//
+//	opts, hashes := parseRequirementOptions("--hash=sha256:abcd --global-option --no-user-cfg", nil, 0)
+//	// opts = map[string]string{"global-option": "--no-user-cfg"}, hashes = []string{"sha256:abcd"}
+func parseRequirementOptions(s string, spans *lineSpans, offset int) (map[string]string, []string) {
 	reqOptionPrefix := "--"
 	var reqOptions map[string]string
 	var hashes []string
-	parts := strings.Fields(lineWithoutComment)
 
-	// 分离package规格和选项
-	var packageSpec string
-	if len(parts) > 0 {
-		packageSpec = parts[0]
-	}
+	fs := fieldScanner{s: s}
+	for {
+		field, ok := fs.next()
+		if !ok {
+			break
+		}
+		if !strings.HasPrefix(field, reqOptionPrefix) {
+			continue
+		}
 
-	for i := 1; i < len(parts); i++ {
-		if strings.HasPrefix(parts[i], reqOptionPrefix) {
-			if reqOptions == nil {
-				reqOptions = make(map[string]string)
-			}
+		if reqOptions == nil {
+			reqOptions = make(map[string]string)
+		}
 
-			if strings.HasPrefix(parts[i], "--hash=") {
-				// 特殊处理hash选项
-				hashMatch := hashRegex.FindStringSubmatch(parts[i])
-				if len(hashMatch) > 1 {
-					hashes = append(hashes, hashMatch[1])
+		start := offset + fs.pos - len(field)
+		optName := strings.TrimPrefix(field, reqOptionPrefix)
+		if strings.HasPrefix(field, "--hash=") {
+			// 特殊处理hash选项
+			if hash, ok := parseHashOption(field); ok {
+				hashes = append(hashes, hash)
+				spans.addHash(start, offset+fs.pos)
+			}
+		} else if field == "--hash" {
+			// "--hash 算法:摘要"形式，值在下一个字段中
+			if next, ok := fs.peek(); ok && !strings.HasPrefix(next, reqOptionPrefix) {
+				fs.next()
+				if ValidHash(next) {
+					hashes = append(hashes, next)
+					spans.addHash(start, offset+fs.pos)
 				}
-			} else if i+1 < len(parts) && !strings.HasPrefix(parts[i+1], reqOptionPrefix) {
-				// 选项带值
-				optName := strings.TrimPrefix(parts[i], reqOptionPrefix)
-				optValue := parts[i+1]
-				reqOptions[optName] = optValue
-				i++ // 跳过下一个token，因为它是选项的值
-			} else {
-				// 无值选项
-				optName := strings.TrimPrefix(parts[i], reqOptionPrefix)
-				reqOptions[optName] = "true"
 			}
+		} else if name, value, ok := strings.Cut(optName, "="); ok {
+			// "--选项=值"形式
+			reqOptions[name] = value
+			spans.addOption(name, start, offset+fs.pos)
+		} else if next, ok := fs.peek(); ok && !strings.HasPrefix(next, reqOptionPrefix) {
+			// 选项带值
+			reqOptions[optName] = next
+			fs.next() // 跳过下一个字段，因为它是选项的值
+			spans.addOption(optName, start, offset+fs.pos)
+		} else {
+			// 无值选项
+			reqOptions[optName] = "true"
+			spans.addOption(optName, start, offset+fs.pos)
 		}
 	}
 
-	// 解析包名、版本和extras
-	var name, version string
-	var extras []string
-
-	// 检查是否有extras ([dev,test]等)
-	nameParts := strings.SplitN(packageSpec, "[", 2)
-	name = nameParts[0]
-
-	if len(nameParts) > 1 {
-		// 有extras部分
-		extrasEndIdx := strings.Index(nameParts[1], "]")
-		if extrasEndIdx != -1 {
-			extrasStr := nameParts[1][:extrasEndIdx]
-			for _, extra := range strings.Split(extrasStr, ",") {
-				if trimmed := strings.TrimSpace(extra); trimmed != "" {
-					extras = append(extras, trimmed)
-				}
-			}
+	return reqOptions, hashes
+}
 
-			// 获取版本部分
-			if len(nameParts[1]) > extrasEndIdx+1 {
-				version = strings.TrimSpace(nameParts[1][extrasEndIdx+1:])
-			}
-		}
-	} else {
-		// 没有extras，直接检查版本
-		versionStartIdx := -1
-		for i, char := range packageSpec {
-			if char == '>' || char == '<' || char == '=' || char == '~' || char == '!' {
-				versionStartIdx = i
-				break
-			}
-		}
+// setLocalPath 将requirement标记为本地路径引用
+//
+// 路径末尾的[extras]会被解析到Extras字段中，如"./libs/core[fast]"。
+// 对于file:// URL，LocalPath记录转换后的本地路径，原始URL保存在URL字段中，
+// 以便序列化时还原原始写法。
+//
+// 参数:
+//   - req: 要设置的Requirement对象
+//   - ref: 行中的本地引用，如"../lib"、".[dev,test]"或"file:///abs/pkg.tar.gz"
+func setLocalPath(req *models.Requirement, ref string) {
+	ref, extras := splitExtras(ref)
+	if len(extras) > 0 {
+		req.Extras = extras
+	}
 
-		if versionStartIdx != -1 {
-			name = strings.TrimSpace(packageSpec[:versionStartIdx])
-			version = strings.TrimSpace(packageSpec[versionStartIdx:])
-		} else {
-			name = packageSpec
-		}
+	req.Kind = models.KindLocalPath
+	req.IsLocalPath = true
+	if isFileURL(ref) {
+		req.URL = ref
+		req.LocalPath = fileURLToPath(ref)
+		return
 	}
+	req.LocalPath = ref
+}
 
-	return &models.Requirement{
-		Name:               name,
-		Version:            version,
-		Extras:             extras,
-		Markers:            markers,
-		Comment:            comment,
-		OriginalLine:       line,
-		RequirementOptions: reqOptions,
-		Hashes:             hashes,
+// splitExtras 从路径或egg名称末尾分离[extras]部分
+//
+// 参数:
+//   - s: 可能以[extras]结尾的字符串
+//
+// 返回:
+//   - string: 去掉extras后的部分
+//   - []string: extras列表，如果没有则为nil
+//
+// 示例:
+//
+//	splitExtras(".[dev,test]")     // 返回".", []string{"dev", "test"}
+//	splitExtras("project[fast]")   // 返回"project", []string{"fast"}
+//	splitExtras("./libs/core")     // 返回"./libs/core", nil
+func splitExtras(s string) (string, []string) {
+	if !strings.HasSuffix(s, "]") {
+		return s, nil
+	}
+
+	openIdx := strings.LastIndex(s, "[")
+	if openIdx <= 0 {
+		return s, nil
+	}
+
+	return s[:openIdx], splitList(s[openIdx+1 : len(s)-1])
+}
+
+// parseDirectRef 解析PEP 508直接引用
+//
+// 直接引用的形式为"name[extras] @ url"，url可以是普通URL、VCS URL或file:// URL，
+// 其后可以跟随requirement选项。
+//
+// 参数:
+//   - req: 要设置的Requirement对象
+//   - name: 包名
+//   - extras: 方括号内的extras文本
+//   - ref: "@"之后的内容，包括引用地址和其后的选项
+//   - spans: 不为nil时记录选项和哈希的位置
+//   - offset: ref在逻辑行中的位置
+//
+// 示例:
+//
+//	req := &models.Requirement{}
+//	parseDirectRef(req, "pkg", "fast", "https://example.com/pkg.whl", nil, 0)
+//	// 结果: req.Name = "pkg", req.Extras = []string{"fast"}, req.IsURL = true, req.URL = "https://example.com/pkg.whl"
+func parseDirectRef(req *models.Requirement, name, extras, ref string, spans *lineSpans, offset int) {
+	req.Kind = models.KindDirectRef
+	req.IsDirectRef = true
+	req.Name = name
+	req.Extras = splitList(extras)
+
+	fs := fieldScanner{s: ref}
+	ref, _ = fs.next()
+	rest := fs.rest()
+	req.RequirementOptions, req.Hashes = parseRequirementOptions(rest, spans, offset+len(fs.s)-len(rest))
+
+	if vcsType, vcsURL, ok := splitVCS(ref); ok {
+		req.IsVCS = true
+		req.VCSType = vcsType
+		req.URL = vcsURL
+	} else if isFileURL(ref) {
+		req.IsLocalPath = true
+		req.URL = ref
+		req.LocalPath = fileURLToPath(ref)
+	} else {
+		req.IsURL = true
+		req.URL = ref
 	}
 }
 
@@ -330,8 +476,12 @@ func extractEggName(req *models.Requirement) {
 			eggPart = eggPart[:ampIndex]
 		}
 
-		// 设置包名
-		req.Name = eggPart
+		// 设置包名，egg名称中可能带有extras，如"#egg=project[dev]"
+		name, extras := splitExtras(eggPart)
+		req.Name = name
+		if len(extras) > 0 {
+			req.Extras = extras
+		}
 
 		// 清理URL，移除#egg=及其后面的部分
 		cleanURL := url[:eggIndex]
@@ -155,9 +155,9 @@ func TestEditableLineHandling(t *testing.T) {
 			if strings.Contains(tc.input, "#egg=") {
 				t.Logf("URL contains #egg=, req.URL: %q, req.Name: %q", req.URL, req.Name)
 				path := ""
-				if matches := editableRegex.FindStringSubmatch(tc.input); len(matches) > 1 {
-					path = matches[1]
-					t.Logf("Extracted path from editable regex: %q", path)
+				if _, value := splitOption(tc.input); value != "" {
+					path = value
+					t.Logf("Extracted path from editable option: %q", path)
 				}
 			}
 
@@ -404,3 +404,164 @@ func TestURLOrPathHandling(t *testing.T) {
 		})
 	}
 }
+
+func TestReferenceExtrasHandling(t *testing.T) {
+	testCases := []struct {
+		name          string
+		input         string
+		wantName      string
+		wantExtras    []string
+		wantMarkers   string
+		wantLocalPath string
+		wantURL       string
+		wantEditable  bool
+		wantDirectRef bool
+	}{
+		{
+			name:          "可编辑当前目录",
+			input:         "-e .[dev,test]",
+			wantExtras:    []string{"dev", "test"},
+			wantLocalPath: ".",
+			wantEditable:  true,
+		},
+		{
+			name:          "本地目录",
+			input:         "./libs/core[fast]",
+			wantExtras:    []string{"fast"},
+			wantLocalPath: "./libs/core",
+		},
+		{
+			name:          "可编辑本地路径带环境标记",
+			input:         "-e ../pkg ; python_version>'3.8'",
+			wantMarkers:   "python_version>'3.8'",
+			wantLocalPath: "../pkg",
+			wantEditable:  true,
+		},
+		{
+			name:         "VCS egg名称带extras",
+			input:        "-e git+https://github.com/user/project.git#egg=project[cli,docs]",
+			wantName:     "project",
+			wantExtras:   []string{"cli", "docs"},
+			wantURL:      "https://github.com/user/project.git",
+			wantEditable: true,
+		},
+		{
+			name:       "URL egg名称带extras",
+			input:      "https://example.com/pkg-1.0.tar.gz#egg=pkg[fast]",
+			wantName:   "pkg",
+			wantExtras: []string{"fast"},
+			wantURL:    "https://example.com/pkg-1.0.tar.gz",
+		},
+		{
+			name:          "直接引用URL",
+			input:         "pkg[fast,cli] @ https://example.com/pkg-1.0.whl ; python_version >= '3.8'",
+			wantName:      "pkg",
+			wantExtras:    []string{"fast", "cli"},
+			wantMarkers:   "python_version >= '3.8'",
+			wantURL:       "https://example.com/pkg-1.0.whl",
+			wantDirectRef: true,
+		},
+		{
+			name:          "直接引用本地文件",
+			input:         "pkg@file:///wheels/pkg-1.0.whl",
+			wantName:      "pkg",
+			wantLocalPath: "/wheels/pkg-1.0.whl",
+			wantURL:       "file:///wheels/pkg-1.0.whl",
+			wantDirectRef: true,
+		},
+	}
+
+	p := New()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := p.parseLine(tc.input)
+
+			if req.Name != tc.wantName {
+				t.Errorf("parseLine(%q).Name = %q, want %q", tc.input, req.Name, tc.wantName)
+			}
+
+			if strings.Join(req.Extras, ",") != strings.Join(tc.wantExtras, ",") {
+				t.Errorf("parseLine(%q).Extras = %v, want %v", tc.input, req.Extras, tc.wantExtras)
+			}
+
+			if req.Markers != tc.wantMarkers {
+				t.Errorf("parseLine(%q).Markers = %q, want %q", tc.input, req.Markers, tc.wantMarkers)
+			}
+
+			if req.LocalPath != tc.wantLocalPath {
+				t.Errorf("parseLine(%q).LocalPath = %q, want %q", tc.input, req.LocalPath, tc.wantLocalPath)
+			}
+
+			if req.URL != tc.wantURL {
+				t.Errorf("parseLine(%q).URL = %q, want %q", tc.input, req.URL, tc.wantURL)
+			}
+
+			if req.IsEditable != tc.wantEditable {
+				t.Errorf("parseLine(%q).IsEditable = %v, want %v", tc.input, req.IsEditable, tc.wantEditable)
+			}
+
+			if req.IsDirectRef != tc.wantDirectRef {
+				t.Errorf("parseLine(%q).IsDirectRef = %v, want %v", tc.input, req.IsDirectRef, tc.wantDirectRef)
+			}
+		})
+	}
+}
+
+func TestParseRequirementOptionsHashForms(t *testing.T) {
+	testCases := []struct {
+		name        string
+		input       string
+		wantHashes  []string
+		wantOptions map[string]string
+	}{
+		{"等号形式", "--hash=sha256:abcd", []string{"sha256:abcd"}, map[string]string{}},
+		{"空格形式", "--hash sha256:abcd", []string{"sha256:abcd"}, map[string]string{}},
+		{"混合形式", "--hash sha256:aaaa --hash=sha256:bbbb", []string{"sha256:aaaa", "sha256:bbbb"}, map[string]string{}},
+		{"空格形式的无效哈希被丢弃", "--hash sha256:XYZ --no-deps", nil, map[string]string{"no-deps": "true"}},
+		{"缺少值", "--hash --no-deps", nil, map[string]string{"no-deps": "true"}},
+		{"其他选项", "--global-option x --hash sha256:abcd", []string{"sha256:abcd"}, map[string]string{"global-option": "x"}},
+		{"等号形式的其他选项", "--config-settings=a=b --no-deps", nil, map[string]string{"config-settings": "a=b", "no-deps": "true"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			options, hashes := parseRequirementOptions(tc.input, nil, 0)
+			if strings.Join(hashes, ",") != strings.Join(tc.wantHashes, ",") {
+				t.Errorf("hashes = %v, 期望 %v", hashes, tc.wantHashes)
+			}
+			if len(options) != len(tc.wantOptions) {
+				t.Errorf("options = %v, 期望 %v", options, tc.wantOptions)
+			}
+			for key, value := range tc.wantOptions {
+				if options[key] != value {
+					t.Errorf("options[%q] = %q, 期望 %q", key, options[key], value)
+				}
+			}
+		})
+	}
+}
+
+func TestParseHashSpaceFormPositions(t *testing.T) {
+	p := New()
+	p.RecordPositions = true
+	content := "flask==2.0.1 \\\n    --hash sha256:aaaa \\\n    --hash=sha256:bbbb\n"
+	reqs, err := p.ParseString(content)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+
+	req := reqs[0]
+	if strings.Join(req.Hashes, ",") != "sha256:aaaa,sha256:bbbb" {
+		t.Fatalf("Hashes = %v", req.Hashes)
+	}
+	if _, ok := req.RequirementOptions["hash"]; ok {
+		t.Errorf("\"--hash 值\"不应出现在RequirementOptions中: %v", req.RequirementOptions)
+	}
+	spans := req.PositionInfo.Hashes
+	if len(spans) != 2 {
+		t.Fatalf("PositionInfo.Hashes = %+v", spans)
+	}
+	if got := content[spans[0].Start:spans[0].End]; got != "--hash sha256:aaaa" || spans[0].Line != 2 {
+		t.Errorf("第一个哈希的位置 = %q（第%d行）", got, spans[0].Line)
+	}
+}
@@ -0,0 +1,195 @@
+package parser
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// archiveSuffixes pip能直接安装的本地归档文件扩展名
+var archiveSuffixes = []string{
+	".whl",
+	".tar.gz", ".tgz",
+	".tar.bz2", ".tbz",
+	".tar.xz", ".txz",
+	".tar",
+	".zip",
+}
+
+// isLocalPath 检查字符串是否为本地路径引用
+//
+// 识别规则参考pip对本地引用的判断：以./、../、.\、..\、/、~开头的路径，
+// Windows盘符路径（如C:\wheels）和UNC路径，file:// URL，包含路径分隔符的
+// 目录名（如mypkg/、libs/core），以及wheel、sdist等归档文件。
+//
+// 参数:
+//   - s: 要检查的字符串（不包含选项、环境标记和注释）
+//
+// 返回:
+//   - bool: 如果是本地路径引用则返回true，否则返回false
+//
+// 示例:
+//
+//	isLocalPath("./downloads/package.whl")   // 返回true
+//	isLocalPath("C:\\wheels\\pkg.whl")        // 返回true
+//	isLocalPath("file:///abs/pkg.tar.gz")    // 返回true
+//	isLocalPath("mypkg/")                    // 返回true
+//	isLocalPath("flask==2.0.1")              // 返回false
+func isLocalPath(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	if s == "." || s == ".." || s == "~" {
+		return true
+	}
+
+	if isFileURL(s) {
+		return true
+	}
+
+	// 其他带scheme的URL（http、git+https等）不是本地路径
+	if strings.Contains(s, "://") {
+		return false
+	}
+
+	for _, prefix := range []string{"./", "../", ".\\", "..\\", "/", "\\", "~/", "~\\"} {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+
+	if isWindowsAbsPath(s) {
+		return true
+	}
+
+	// 包名和版本约束中不会出现路径分隔符
+	if strings.ContainsAny(s, "/\\") {
+		return true
+	}
+
+	lower := strings.ToLower(s)
+	for _, suffix := range archiveSuffixes {
+		if strings.HasSuffix(lower, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isFileURL 检查字符串是否为file: URL
+func isFileURL(s string) bool {
+	return len(s) >= 5 && strings.EqualFold(s[:5], "file:")
+}
+
+// isWindowsAbsPath 检查字符串是否为Windows绝对路径（盘符路径或UNC路径）
+//
+// 示例:
+//
+//	isWindowsAbsPath("C:\\wheels\\pkg.whl") // 返回true
+//	isWindowsAbsPath("d:/src/pkg")          // 返回true
+//	isWindowsAbsPath("\\\\server\\share")   // 返回true
+//	isWindowsAbsPath("./pkg")               // 返回false
+func isWindowsAbsPath(s string) bool {
+	if strings.HasPrefix(s, `\\`) {
+		return true
+	}
+	if len(s) < 3 || s[1] != ':' || (s[2] != '\\' && s[2] != '/') {
+		return false
+	}
+	c := s[0]
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// fileURLToPath 将file: URL转换为本地文件系统路径
+//
+// 支持file:///abs/path、file://localhost/abs/path、file:///C:/path、
+// file://server/share（UNC）以及file:relative/path等形式，并对百分号编码进行解码。
+//
+// 参数:
+//   - s: file: URL
+//
+// 返回:
+//   - string: 对应的本地路径
+//
+// 示例:
+//
+//	fileURLToPath("file:///abs/pkg.tar.gz")     // 返回"/abs/pkg.tar.gz"
+//	fileURLToPath("file:///C:/wheels/pkg.whl")  // 返回"C:/wheels/pkg.whl"
+//	fileURLToPath("file://server/share/pkg")    // 返回"//server/share/pkg"
+func fileURLToPath(s string) string {
+	rest := s[len("file:"):]
+
+	var host string
+	if strings.HasPrefix(rest, "//") {
+		rest = rest[2:]
+		slash := strings.Index(rest, "/")
+		if slash == -1 {
+			host, rest = rest, ""
+		} else {
+			host, rest = rest[:slash], rest[slash:]
+		}
+	}
+
+	if unescaped, err := url.PathUnescape(rest); err == nil {
+		rest = unescaped
+	}
+
+	// file:///C:/path 形式的Windows路径
+	if len(rest) >= 3 && rest[0] == '/' && isWindowsAbsPath(rest[1:]) {
+		return rest[1:]
+	}
+
+	if host != "" && !strings.EqualFold(host, "localhost") {
+		return "//" + host + rest
+	}
+
+	return rest
+}
+
+// resolveLocalPath 计算本地路径引用的绝对路径
+//
+// 与pip的行为一致，相对路径相对于包含该行的requirements文件所在目录解析；
+// 当baseDir为空（例如从字符串解析）时，相对于当前工作目录解析。
+// 以~开头的路径会展开为用户主目录，Windows绝对路径保持原样。
+//
+// 参数:
+//   - path: LocalPath中记录的原始路径（file: URL已转换为路径）
+//   - baseDir: 包含该行的requirements文件所在目录
+//
+// 返回:
+//   - string: 解析后的绝对路径
+//
+// 示例:
+//
+//	resolveLocalPath("../lib", "/repo/requirements")  // 返回"/repo/lib"
+//	resolveLocalPath(".\\pkg", "/repo")                // 返回"/repo/pkg"
+func resolveLocalPath(path, baseDir string) string {
+	if isWindowsAbsPath(path) {
+		return path
+	}
+
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, `~\`) {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = home + "/" + strings.TrimPrefix(path[1:], "/")
+		}
+	}
+
+	// 统一路径分隔符，使Windows风格的相对路径在所有平台上都能解析
+	path = filepath.FromSlash(strings.ReplaceAll(path, `\`, "/"))
+
+	if !filepath.IsAbs(path) {
+		if baseDir == "" {
+			wd, err := os.Getwd()
+			if err != nil {
+				return filepath.Clean(path)
+			}
+			baseDir = wd
+		}
+		path = filepath.Join(baseDir, path)
+	}
+
+	return filepath.Clean(path)
+}
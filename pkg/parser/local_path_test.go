@@ -0,0 +1,254 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsLocalPath(t *testing.T) {
+	testCases := []struct {
+		input string
+		want  bool
+	}{
+		{".", true},
+		{"./downloads/package.whl", true},
+		{"../lib", true},
+		{".\\pkg", true},
+		{"..\\pkg", true},
+		{"/absolute/path/package", true},
+		{"~/src/pkg", true},
+		{"C:\\wheels\\pkg.whl", true},
+		{"d:/src/pkg", true},
+		{"\\\\server\\share\\pkg", true},
+		{"file:///abs/pkg.tar.gz", true},
+		{"FILE:///abs/pkg.tar.gz", true},
+		{"mypkg/", true},
+		{"libs/core", true},
+		{"package-1.0.0.tar.gz", true},
+		{"package-1.0.0-py3-none-any.whl", true},
+		{"package.ZIP", true},
+		{"flask", false},
+		{"flask==2.0.1", false},
+		{"requests[security]>=2.25.0", false},
+		{"https://example.com/package.whl", false},
+		{"git+https://github.com/user/project.git", false},
+		{"", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			if got := isLocalPath(tc.input); got != tc.want {
+				t.Errorf("isLocalPath(%q) = %v, want %v", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFileURLToPath(t *testing.T) {
+	testCases := []struct {
+		input string
+		want  string
+	}{
+		{"file:///abs/pkg.tar.gz", "/abs/pkg.tar.gz"},
+		{"file://localhost/abs/pkg.whl", "/abs/pkg.whl"},
+		{"file:///C:/wheels/pkg.whl", "C:/wheels/pkg.whl"},
+		{"file://server/share/pkg", "//server/share/pkg"},
+		{"file:///path%20with%20space/pkg", "/path with space/pkg"},
+		{"file:../relative/pkg", "../relative/pkg"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			if got := fileURLToPath(tc.input); got != tc.want {
+				t.Errorf("fileURLToPath(%q) = %q, want %q", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestResolveLocalPath(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skipf("无法获取用户主目录: %v", err)
+	}
+
+	base := filepath.FromSlash("/repo/requirements")
+
+	testCases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"相对路径", "../lib", filepath.FromSlash("/repo/lib")},
+		{"当前目录", ".", base},
+		{"Windows风格相对路径", ".\\pkg", filepath.Join(base, "pkg")},
+		{"目录名", "mypkg/", filepath.Join(base, "mypkg")},
+		{"绝对路径", "/opt/pkg", filepath.FromSlash("/opt/pkg")},
+		{"用户主目录", "~/src/pkg", filepath.Join(home, "src", "pkg")},
+		{"Windows绝对路径", "C:\\wheels\\pkg.whl", "C:\\wheels\\pkg.whl"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := resolveLocalPath(tc.input, base); got != tc.want {
+				t.Errorf("resolveLocalPath(%q, %q) = %q, want %q", tc.input, base, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseLineLocalReferences(t *testing.T) {
+	testCases := []struct {
+		name          string
+		input         string
+		wantEditable  bool
+		wantLocalPath string
+		wantURL       string
+		wantOptions   map[string]string
+	}{
+		{
+			name:          "file URL",
+			input:         "file:///abs/pkg.tar.gz",
+			wantLocalPath: "/abs/pkg.tar.gz",
+			wantURL:       "file:///abs/pkg.tar.gz",
+		},
+		{
+			name:          "可编辑file URL",
+			input:         "-e file:///abs/project",
+			wantEditable:  true,
+			wantLocalPath: "/abs/project",
+			wantURL:       "file:///abs/project",
+		},
+		{
+			name:          "Windows绝对路径",
+			input:         "C:\\wheels\\pkg.whl",
+			wantLocalPath: "C:\\wheels\\pkg.whl",
+		},
+		{
+			name:          "Windows相对路径",
+			input:         ".\\pkg",
+			wantLocalPath: ".\\pkg",
+		},
+		{
+			name:          "目录名",
+			input:         "mypkg/",
+			wantLocalPath: "mypkg/",
+		},
+		{
+			name:          "用户主目录",
+			input:         "~/src/pkg",
+			wantLocalPath: "~/src/pkg",
+		},
+		{
+			name:          "sdist归档",
+			input:         "package-1.0.0.tar.gz",
+			wantLocalPath: "package-1.0.0.tar.gz",
+		},
+		{
+			name:          "带选项的本地路径",
+			input:         "./pkg --config-settings key=value",
+			wantLocalPath: "./pkg",
+			wantOptions:   map[string]string{"config-settings": "key=value"},
+		},
+	}
+
+	p := New()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := p.parseLine(tc.input)
+
+			if !req.IsLocalPath {
+				t.Fatalf("parseLine(%q).IsLocalPath = false, want true", tc.input)
+			}
+
+			if req.IsEditable != tc.wantEditable {
+				t.Errorf("parseLine(%q).IsEditable = %v, want %v", tc.input, req.IsEditable, tc.wantEditable)
+			}
+
+			if req.LocalPath != tc.wantLocalPath {
+				t.Errorf("parseLine(%q).LocalPath = %q, want %q", tc.input, req.LocalPath, tc.wantLocalPath)
+			}
+
+			if req.URL != tc.wantURL {
+				t.Errorf("parseLine(%q).URL = %q, want %q", tc.input, req.URL, tc.wantURL)
+			}
+
+			for key, want := range tc.wantOptions {
+				if got := req.RequirementOptions[key]; got != want {
+					t.Errorf("parseLine(%q).RequirementOptions[%q] = %q, want %q", tc.input, key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseFileResolvesLocalPaths(t *testing.T) {
+	tempDir := t.TempDir()
+	reqDir := filepath.Join(tempDir, "requirements")
+	if err := os.MkdirAll(reqDir, 0755); err != nil {
+		t.Fatalf("创建目录失败: %v", err)
+	}
+
+	content := "-e ../lib\n./vendor/pkg.whl\nfile:///abs/pkg.tar.gz\nflask==2.0.1\n"
+	reqFile := filepath.Join(reqDir, "dev.txt")
+	if err := os.WriteFile(reqFile, []byte(content), 0644); err != nil {
+		t.Fatalf("写入文件失败: %v", err)
+	}
+
+	p := New()
+	reqs, err := p.ParseFile(reqFile)
+	if err != nil {
+		t.Fatalf("解析文件失败: %v", err)
+	}
+
+	if len(reqs) != 4 {
+		t.Fatalf("期望4个依赖项，实际得到%d个", len(reqs))
+	}
+
+	want := []string{
+		filepath.Join(tempDir, "lib"),
+		filepath.Join(reqDir, "vendor", "pkg.whl"),
+		filepath.FromSlash("/abs/pkg.tar.gz"),
+		"",
+	}
+	for i, req := range reqs {
+		if req.ResolvedPath != want[i] {
+			t.Errorf("reqs[%d].ResolvedPath = %q, want %q", i, req.ResolvedPath, want[i])
+		}
+	}
+}
+
+func TestParseStringResolvesAgainstWorkingDir(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("获取工作目录失败: %v", err)
+	}
+
+	p := New()
+	reqs, err := p.ParseString("-e ./project")
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+
+	if want := filepath.Join(wd, "project"); reqs[0].ResolvedPath != want {
+		t.Errorf("ResolvedPath = %q, want %q", reqs[0].ResolvedPath, want)
+	}
+}
+
+func TestParseStringInDir(t *testing.T) {
+	baseDir := filepath.Join(t.TempDir(), "requirements")
+
+	p := New()
+	reqs, err := p.ParseStringInDir("\ufeff-e ../lib\nflask==2.0.1\n", baseDir)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+
+	if len(reqs) != 2 {
+		t.Fatalf("期望2个依赖项，实际得到%d个", len(reqs))
+	}
+	if want := filepath.Join(filepath.Dir(baseDir), "lib"); reqs[0].ResolvedPath != want {
+		t.Errorf("ResolvedPath = %q, want %q", reqs[0].ResolvedPath, want)
+	}
+}
@@ -0,0 +1,118 @@
+package parser
+
+import (
+	"strings"
+)
+
+// splitMarkers 按照pip的规则分离环境标记
+//
+// 环境标记以";"开始，到第一个以"-"开头的选项（如"--hash"）为止，与pip先分离选项、
+// 再分离环境标记的顺序一致。引号内的";"不会开始环境标记；URL中的";"见IsMarkerSeparator。
+//
+// 参数:
+//   - line: 去掉注释后的行
+//
+// 返回:
+//   - int: 分隔环境标记的";"的位置，没有环境标记时为-1
+//   - int: 环境标记的结束位置，之后为选项；没有环境标记时为len(line)
+//
+// 示例:
+//
+//	splitMarkers(`pkg ; os_name == "a;b" --hash=sha256:abcd`)
+//	// 返回: 4, 22
+//
+//	splitMarkers("https://example.com/a;b.whl")
+//	// 返回: -1, 27
+func splitMarkers(line string) (int, int) {
+	start := scanMarkerStart(line, true)
+	if start == -1 {
+		// 引号未闭合时退回到不考虑引号的规则
+		start = scanMarkerStart(line, false)
+	}
+	if start < 0 {
+		return -1, len(line)
+	}
+
+	// 环境标记在空白之后以"-"开头的字段处结束，引号内的内容属于环境标记
+	var quote byte
+	for i := start + 1; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			if strings.IndexByte(line[i+1:], c) != -1 {
+				quote = c
+			}
+		case isMarkerSpace(c):
+			j := i
+			for j < len(line) && isMarkerSpace(line[j]) {
+				j++
+			}
+			if j < len(line) && line[j] == '-' {
+				return start, i
+			}
+			i = j - 1
+		}
+	}
+	return start, len(line)
+}
+
+// scanMarkerStart 查找分隔环境标记的";"的位置
+//
+// 当respectQuotes为true时，引号内的";"会被忽略；如果扫描结束时引号仍未闭合，
+// 返回-1表示需要按不考虑引号的规则重新扫描。没有环境标记时返回-2。
+func scanMarkerStart(line string, respectQuotes bool) int {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case respectQuotes && (c == '\'' || c == '"'):
+			quote = c
+		case c == ';' && IsMarkerSeparator(line, i):
+			return i
+		}
+	}
+
+	if quote != 0 {
+		return -1
+	}
+	return -2
+}
+
+// IsMarkerSeparator 检查line[i]处的";"是否分隔环境标记
+//
+// URL中可以包含";"，因此与pip一致，";"所在的字段在它之前含有"://"时（位于URL中），
+// 只有与空白相邻的";"才分隔环境标记，即"url ; marker"或"url; marker"。
+// 其他位置的";"都分隔环境标记。供cst的词法分析器使用相同的规则。
+//
+// 示例:
+//
+//	IsMarkerSeparator("pkg;python_version<'3'", 3)                    // 返回true
+//	IsMarkerSeparator("https://example.com/a;b.whl", 21)              // 返回false
+//	IsMarkerSeparator("https://example.com/a.whl; os_name=='nt'", 25) // 返回true
+func IsMarkerSeparator(line string, i int) bool {
+	if i < 0 || i >= len(line) || line[i] != ';' {
+		return false
+	}
+
+	start := i
+	for start > 0 && !isMarkerSpace(line[start-1]) {
+		start--
+	}
+	if !strings.Contains(line[start:i], "://") {
+		return true
+	}
+	return i+1 == len(line) || isMarkerSpace(line[i+1]) || line[i+1] == '\\'
+}
+
+// isMarkerSpace 检查是否为分隔字段的空白，包括行继续符中的换行
+func isMarkerSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
@@ -0,0 +1,122 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseLineMarkerRules(t *testing.T) {
+	testCases := []struct {
+		name        string
+		input       string
+		wantURL     string
+		wantMarkers string
+		wantHashes  string
+		wantOptions map[string]string
+	}{
+		{
+			name:        "引号内的分号",
+			input:       `pkg; platform_release == "5;x"`,
+			wantMarkers: `platform_release == "5;x"`,
+		},
+		{
+			name:    "URL中的分号",
+			input:   "https://example.com/a;b/pkg-1.0.whl",
+			wantURL: "https://example.com/a;b/pkg-1.0.whl",
+		},
+		{
+			name:        "URL之后空白前的分号",
+			input:       "https://example.com/a;b/pkg-1.0.whl ; os_name == 'nt'",
+			wantURL:     "https://example.com/a;b/pkg-1.0.whl",
+			wantMarkers: "os_name == 'nt'",
+		},
+		{
+			name:        "URL之后空白后的分号",
+			input:       "https://example.com/pkg-1.0.whl; os_name == 'nt'",
+			wantURL:     "https://example.com/pkg-1.0.whl",
+			wantMarkers: "os_name == 'nt'",
+		},
+		{
+			name:        "直接引用中的分号",
+			input:       "pkg @ https://example.com/a;b/pkg-1.0.whl ; os_name == 'nt'",
+			wantURL:     "https://example.com/a;b/pkg-1.0.whl",
+			wantMarkers: "os_name == 'nt'",
+		},
+		{
+			name:        "环境标记之后的选项",
+			input:       "pkg==1.0 ; python_version >= '3.8' --no-deps --hash=sha256:abcd",
+			wantMarkers: "python_version >= '3.8'",
+			wantHashes:  "sha256:abcd",
+			wantOptions: map[string]string{"no-deps": "true"},
+		},
+		{
+			name:        "引号内以-开头的值属于环境标记",
+			input:       "pkg ; platform_release == ' -rc'",
+			wantMarkers: "platform_release == ' -rc'",
+		},
+	}
+
+	p := New()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := p.parseLine(tc.input)
+
+			if req.URL != tc.wantURL {
+				t.Errorf("URL = %q, 期望 %q", req.URL, tc.wantURL)
+			}
+			if req.Markers != tc.wantMarkers {
+				t.Errorf("Markers = %q, 期望 %q", req.Markers, tc.wantMarkers)
+			}
+			if got := strings.Join(req.Hashes, ","); got != tc.wantHashes {
+				t.Errorf("Hashes = %q, 期望 %q", got, tc.wantHashes)
+			}
+			if len(req.RequirementOptions) != len(tc.wantOptions) {
+				t.Errorf("RequirementOptions = %v, 期望 %v", req.RequirementOptions, tc.wantOptions)
+			}
+			for key, value := range tc.wantOptions {
+				if req.RequirementOptions[key] != value {
+					t.Errorf("RequirementOptions[%q] = %q, 期望 %q", key, req.RequirementOptions[key], value)
+				}
+			}
+		})
+	}
+}
+
+func TestIsMarkerSeparator(t *testing.T) {
+	testCases := []struct {
+		line string
+		i    int
+		want bool
+	}{
+		{"pkg;python_version<'3'", 3, true},
+		{"https://example.com/a;b.whl", 21, false},
+		{"https://example.com/a.whl; os_name=='nt'", 25, true},
+		{"https://example.com/a.whl ;os_name=='nt'", 26, true},
+		{"https://example.com/a.whl;", 25, true},
+		{"./a;b", 3, true},
+		{"pkg", 1, false},
+	}
+
+	for _, tc := range testCases {
+		if got := IsMarkerSeparator(tc.line, tc.i); got != tc.want {
+			t.Errorf("IsMarkerSeparator(%q, %d) = %v, 期望 %v", tc.line, tc.i, got, tc.want)
+		}
+	}
+}
+
+func TestParseMarkerOptionPositions(t *testing.T) {
+	p := New()
+	p.RecordPositions = true
+	line := "pkg ; os_name == 'nt' --hash=sha256:abcd"
+	reqs, err := p.ParseString(line)
+	if err != nil || len(reqs) != 1 {
+		t.Fatalf("解析失败: %v", err)
+	}
+	info := reqs[0].PositionInfo
+	if len(info.Hashes) != 1 || line[info.Hashes[0].Start:info.Hashes[0].End] != "--hash=sha256:abcd" {
+		t.Errorf("哈希的位置 = %+v", info.Hashes)
+	}
+	if info.Markers == nil || line[info.Markers.Start:info.Markers.End] != "os_name == 'nt'" {
+		t.Errorf("环境标记的位置 = %+v", info.Markers)
+	}
+}
@@ -1,8 +1,6 @@
 package parser
 
 import (
-	"strings"
-
 	"github.com/scagogogo/python-requirements-parser/pkg/models"
 )
 
@@ -23,8 +21,9 @@ import (
 //	isGlobalOption("-i https://pypi.org/simple")          // 返回true
 //	isGlobalOption("flask==1.0.0")                        // 返回false
 func (p *Parser) isGlobalOption(line string) bool {
+	flag, _ := splitOption(line)
 	for _, opt := range globalOptions {
-		if strings.HasPrefix(line, opt+" ") || line == opt {
+		if flag == opt {
 			return true
 		}
 	}
@@ -53,49 +52,45 @@ func (p *Parser) isGlobalOption(line string) bool {
 //	// 返回: &models.Requirement{IsFileRef: true, FileRef: "other-requirements.txt"}
 func (p *Parser) parseGlobalOption(line string) *models.Requirement {
 	req := &models.Requirement{
+		Kind:          models.KindGlobalOption,
 		OriginalLine:  line,
 		GlobalOptions: make(map[string]string),
 	}
 
-	// 处理各种全局选项
+	// 处理各种全局选项，带值的选项要求选项名称后有值，开关选项要求没有值
+	flag, value := splitOption(line)
+	hasValue := value != ""
 	switch {
-	case indexURLRegex.MatchString(line):
-		matches := indexURLRegex.FindStringSubmatch(line)
-		req.GlobalOptions["index-url"] = matches[1]
-	case extraIndexURLRegex.MatchString(line):
-		matches := extraIndexURLRegex.FindStringSubmatch(line)
-		req.GlobalOptions["extra-index-url"] = matches[1]
-	case noIndexRegex.MatchString(line):
+	case (flag == "-i" || flag == "--index-url") && hasValue:
+		req.GlobalOptions["index-url"] = value
+	case flag == "--extra-index-url" && hasValue:
+		req.GlobalOptions["extra-index-url"] = value
+	case flag == "--no-index" && !hasValue:
 		req.GlobalOptions["no-index"] = "true"
-	case reqFileRegex.MatchString(line):
-		matches := reqFileRegex.FindStringSubmatch(line)
+	case (flag == "-r" || flag == "--requirement") && hasValue:
+		req.Kind = models.KindInclude
 		req.IsFileRef = true
-		req.FileRef = matches[1]
-	case constraintRegex.MatchString(line):
-		matches := constraintRegex.FindStringSubmatch(line)
+		req.FileRef = value
+	case (flag == "-c" || flag == "--constraint") && hasValue:
+		req.Kind = models.KindConstraint
 		req.IsConstraint = true
-		req.ConstraintFile = matches[1]
-	case findLinksRegex.MatchString(line):
-		matches := findLinksRegex.FindStringSubmatch(line)
-		req.GlobalOptions["find-links"] = matches[1]
-	case noBinaryRegex.MatchString(line):
-		matches := noBinaryRegex.FindStringSubmatch(line)
-		req.GlobalOptions["no-binary"] = matches[1]
-	case onlyBinaryRegex.MatchString(line):
-		matches := onlyBinaryRegex.FindStringSubmatch(line)
-		req.GlobalOptions["only-binary"] = matches[1]
-	case preferBinaryRegex.MatchString(line):
+		req.ConstraintFile = value
+	case (flag == "-f" || flag == "--find-links") && hasValue:
+		req.GlobalOptions["find-links"] = value
+	case flag == "--no-binary" && hasValue:
+		req.GlobalOptions["no-binary"] = value
+	case flag == "--only-binary" && hasValue:
+		req.GlobalOptions["only-binary"] = value
+	case flag == "--prefer-binary" && !hasValue:
 		req.GlobalOptions["prefer-binary"] = "true"
-	case requireHashesRegex.MatchString(line):
+	case flag == "--require-hashes" && !hasValue:
 		req.GlobalOptions["require-hashes"] = "true"
-	case preRegex.MatchString(line):
+	case flag == "--pre" && !hasValue:
 		req.GlobalOptions["pre"] = "true"
-	case trustedHostRegex.MatchString(line):
-		matches := trustedHostRegex.FindStringSubmatch(line)
-		req.GlobalOptions["trusted-host"] = matches[1]
-	case useFeatureRegex.MatchString(line):
-		matches := useFeatureRegex.FindStringSubmatch(line)
-		req.GlobalOptions["use-feature"] = matches[1]
+	case flag == "--trusted-host" && hasValue:
+		req.GlobalOptions["trusted-host"] = value
+	case flag == "--use-feature" && hasValue:
+		req.GlobalOptions["use-feature"] = value
 	}
 
 	return req
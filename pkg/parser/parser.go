@@ -1,7 +1,6 @@
 package parser
 
 import (
-	"bufio"
 	"io"
 	"os"
 	"path/filepath"
@@ -39,6 +38,12 @@ type Parser struct {
 	// ProcessEnvVars 是否处理环境变量
 	// 当设置为true时，环境变量如${ENV_VAR}会被替换为其实际值
 	ProcessEnvVars bool
+
+	// RecordPositions 是否记录位置信息
+	// 当设置为true时，每个requirement的PositionInfo记录其行号以及包名、extras、
+	// 版本约束、环境标记、每个选项、每个哈希和注释在文本中的位置。
+	// 位置总是对应替换环境变量之前的原始文本。
+	RecordPositions bool
 }
 
 // New 创建一个新的Parser实例，使用默认设置
@@ -114,7 +119,8 @@ func NewWithOptions(recursiveResolve bool, processEnvVars bool) *Parser {
 // Parse 从一个io.Reader解析requirements.txt文件内容
 //
 // 此方法读取io.Reader提供的内容，按行解析，并处理特殊格式如行继续符和环境变量。
-// 它是其他解析方法（如ParseString和ParseFile）的基础方法。
+// 内容的编码按照pip的规则检测：支持UTF-8/UTF-16/UTF-32的BOM以及
+// PEP 263编码声明（如"# -*- coding: latin-1 -*-"），默认为UTF-8。
 //
 // 参数:
 //   - reader: 提供requirements.txt内容的io.Reader接口
@@ -134,35 +140,108 @@ func NewWithOptions(recursiveResolve bool, processEnvVars bool) *Parser {
 //	    // 处理错误
 //	}
 func (p *Parser) Parse(reader io.Reader) ([]*models.Requirement, error) {
-	scanner := bufio.NewScanner(reader)
-	var requirements []*models.Requirement
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.parseBytes(data, "")
+}
+
+// parseBytes 检测编码、解码并解析requirements内容
+//
+// 与pip一致，内容的编码由BOM或PEP 263编码声明决定，默认为UTF-8。
+func (p *Parser) parseBytes(data []byte, baseDir string) ([]*models.Requirement, error) {
+	text, _, err := Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.parseText(text, baseDir)
+}
+
+// parseText 按行解析已解码的requirements内容
+//
+// baseDir为包含这些内容的requirements文件所在目录，用于解析本地路径引用的绝对路径；
+// 为空时相对于当前工作目录解析。
+func (p *Parser) parseText(text string, baseDir string) ([]*models.Requirement, error) {
+	requirements := make([]*models.Requirement, 0, strings.Count(text, "\n")+1)
 	var continuationLine string
 	var isContinuation bool
 
-	for scanner.Scan() {
-		line := scanner.Text()
+	// 当前逻辑行包含的物理行，用于把行内位置转换为文本中的位置
+	var positions positionMap
+	offset, lineNumber := 0, 0
+
+	addLine := func(line string) {
+		raw := line
+
+		// 处理环境变量
+		if p.ProcessEnvVars {
+			line = p.processEnvironmentVariables(line)
+		}
+
+		var spans *lineSpans
+		if p.RecordPositions {
+			spans = &lineSpans{}
+		}
+		req := p.parseLineSpans(line, spans)
+		if spans != nil {
+			if line != raw {
+				// 替换环境变量改变了文本，位置按原始文本重新计算
+				*spans = lineSpans{}
+				p.parseLineSpans(raw, spans)
+			}
+			req.PositionInfo = positions.positionInfo(spans)
+		}
+
+		if req.IsLocalPath {
+			req.ResolvedPath = resolveLocalPath(req.LocalPath, baseDir)
+		}
+		requirements = append(requirements, req)
+	}
 
-		// 处理行继续符
+	// 逐行切分文本，行是原文本的子串，不会为每行分配内存
+	for len(text) > 0 {
+		line := text
+		lineStart := offset
+		if idx := strings.IndexByte(text, '\n'); idx != -1 {
+			line, text = text[:idx], text[idx+1:]
+			offset += idx + 1
+		} else {
+			text = ""
+			offset += len(line)
+		}
+		line = strings.TrimSuffix(line, "\r")
+		lineNumber++
+
+		// 整行注释不会继续到下一行，与pip一致
+		continues := strings.HasSuffix(line, "\\") && !IsCommentLine(line)
+
+		// 处理行继续符，续行本身也可以继续（如pip-compile生成的多行--hash）
+		start := 0
 		if isContinuation {
+			start = len(continuationLine)
 			line = continuationLine + line
 			isContinuation = false
-		} else if strings.HasSuffix(line, "\\") {
+		} else {
+			positions = positions[:0]
+		}
+		if p.RecordPositions {
+			positions = append(positions, segment{start: start, offset: lineStart, line: lineNumber, length: len(line) - start})
+		}
+		if continues {
 			continuationLine = strings.TrimSuffix(line, "\\")
 			isContinuation = true
 			continue
 		}
 
-		// 处理环境变量
-		if p.ProcessEnvVars {
-			line = p.processEnvironmentVariables(line)
-		}
-
-		req := p.parseLine(line)
-		requirements = append(requirements, req)
+		addLine(line)
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, err
+	// 文件以行继续符结尾时，最后一个逻辑行同样需要解析
+	if isContinuation {
+		addLine(continuationLine)
 	}
 
 	return requirements, nil
@@ -196,12 +275,36 @@ func (p *Parser) Parse(reader io.Reader) ([]*models.Requirement, error) {
 //
 //	// reqs包含4个项目: flask依赖、requests依赖、注释行和文件引用
 func (p *Parser) ParseString(content string) ([]*models.Requirement, error) {
-	return p.Parse(strings.NewReader(content))
+	return p.ParseStringInDir(content, "")
+}
+
+// ParseStringInDir 从字符串解析requirements内容，本地路径相对于baseDir解析
+//
+// 用于内容来自某个requirements文件、但已经单独读取或解码的情况（如编辑器逐行重新解析），
+// 使ResolvedPath与ParseFile的结果一致。baseDir为空时与ParseString相同。
+//
+// 参数:
+//   - content: 要解析的requirements.txt格式的字符串内容
+//   - baseDir: 包含这些内容的requirements文件所在目录
+//
+// 返回:
+//   - []*models.Requirement: 解析出的依赖项数组
+//   - error: 解析过程中遇到的错误
+//
+// 示例:
+//
+//	p := parser.New()
+//	reqs, _ := p.ParseStringInDir("-e ../lib", "/repo/requirements")
+//	// reqs[0].ResolvedPath 为 "/repo/lib"
+func (p *Parser) ParseStringInDir(content, baseDir string) ([]*models.Requirement, error) {
+	// 字符串已经是解码后的文本，只需去掉可能存在的BOM
+	return p.parseText(strings.TrimPrefix(content, "\ufeff"), baseDir)
 }
 
 // ParseFile 从文件路径解析requirements.txt内容
 //
-// 此方法打开指定路径的文件并解析其内容。如果启用了递归解析，还会处理文件中引用的其他文件。
+// 此方法打开指定路径的文件并解析其内容，文件编码的检测规则与Parse相同。
+// 如果启用了递归解析，还会处理文件中引用的其他文件。
 //
 // 参数:
 //   - filePath: 要解析的requirements.txt文件路径
@@ -223,20 +326,24 @@ func (p *Parser) ParseString(content string) ([]*models.Requirement, error) {
 //	reqs, err := p.ParseFile("requirements.txt")
 //	// reqs将包括所有引用文件中的依赖项
 func (p *Parser) ParseFile(filePath string) ([]*models.Requirement, error) {
-	file, err := os.Open(filePath)
+	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
 
-	requirements, err := p.Parse(file)
+	// 本地路径引用相对于requirements文件所在目录解析
+	baseDir := filepath.Dir(filePath)
+	if absPath, err := filepath.Abs(filePath); err == nil {
+		baseDir = filepath.Dir(absPath)
+	}
+
+	requirements, err := p.parseBytes(data, baseDir)
 	if err != nil {
 		return nil, err
 	}
 
 	// 如果启用了递归解析，处理引用的文件
 	if p.RecursiveResolve {
-		baseDir := filepath.Dir(filePath)
 		allRequirements := []*models.Requirement{}
 
 		for _, req := range requirements {
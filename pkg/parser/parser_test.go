@@ -917,3 +917,144 @@ func TestProcessEnvVars(t *testing.T) {
 		})
 	}
 }
+
+func TestParserMultiLineContinuation(t *testing.T) {
+	p := New()
+	content := "flask==2.0.1 \\\n    --hash=sha256:aaaa \\\n    --hash=sha256:bbbb\nrequests==2.25.0 \\"
+	result, err := p.ParseString(content)
+	if err != nil {
+		t.Fatalf("解析多行继续符时出错: %v", err)
+	}
+
+	if len(result) != 2 {
+		t.Fatalf("Expected 2 requirements, got %d", len(result))
+	}
+
+	if result[0].Name != "flask" || len(result[0].Hashes) != 2 {
+		t.Errorf("Expected flask with 2 hashes, got %q with %v", result[0].Name, result[0].Hashes)
+	}
+
+	// 文件末尾的行继续符不应导致最后一个requirement丢失
+	if result[1].Name != "requests" || result[1].Version != "==2.25.0" {
+		t.Errorf("Expected requests==2.25.0, got %q%q", result[1].Name, result[1].Version)
+	}
+}
+
+// TestParserCommentedContinuation 整行注释以"\"结尾时不与下一行合并，行内注释则与pip一样合并
+func TestParserCommentedContinuation(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{
+			name:     "行尾注释吞掉下一行",
+			content:  "flask==1.0 # c \\\nrequests\n",
+			expected: []string{"flask"},
+		},
+		{
+			name:     "注释行",
+			content:  "# note \\\nflask==1.0\n",
+			expected: []string{"", "flask"},
+		},
+		{
+			name:     "缩进的注释行",
+			content:  "  # note \\\nflask==1.0\n",
+			expected: []string{"", "flask"},
+		},
+		{
+			name:     "引号内的#不是注释",
+			content:  "pkg ; os_name == 'a #x' \\\n    --hash=sha256:aaaa\n",
+			expected: []string{"pkg"},
+		},
+	}
+
+	p := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := p.ParseString(tt.content)
+			if err != nil {
+				t.Fatalf("解析出错: %v", err)
+			}
+			if len(result) != len(tt.expected) {
+				t.Fatalf("期望%d个requirement，实际%d个", len(tt.expected), len(result))
+			}
+			for i, name := range tt.expected {
+				if result[i].Name != name {
+					t.Errorf("第%d个requirement的包名为%q，期望%q", i, result[i].Name, name)
+				}
+			}
+		})
+	}
+
+	result, _ := p.ParseString("flask==1.0 # c \\\nrequests\n")
+	if result[0].Comment != "c requests" || result[0].Version != "==1.0" {
+		t.Errorf("行尾注释 = %q, 版本 = %q", result[0].Comment, result[0].Version)
+	}
+}
+
+func TestRequirementStringRoundTrip(t *testing.T) {
+	lines := []string{
+		"flask==2.0.1",
+		"django[rest,auth]>=3.2,<4.0 ; python_version >= '3.8' # Web framework",
+		"numpy==1.21.0 --global-option=x --hash=sha256:aaaa --hash=sha256:bbbb",
+		"-r base.txt",
+		"-c constraints.txt",
+		"--index-url https://pypi.example.com/simple",
+		"--require-hashes",
+		"-e git+https://github.com/user/project.git#egg=project[dev]",
+		"https://example.com/pkg.zip#egg=pkg",
+		"requests[socks] @ https://example.com/requests.whl ; os_name == 'nt'",
+		"-e .[dev,test]",
+		"./libs/core[fast]",
+		"# comment",
+	}
+
+	p := New()
+	for _, line := range lines {
+		t.Run(line, func(t *testing.T) {
+			req := p.parseLine(line)
+			if got := req.String(); got != line {
+				t.Errorf("parseLine(%q).String() = %q", line, got)
+			}
+		})
+	}
+}
+
+func TestParserKind(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected models.Kind
+	}{
+		{"", models.KindBlank},
+		{"# comment", models.KindComment},
+		{"flask==2.0.1", models.KindPackage},
+		{"requests[socks] @ https://example.com/requests.whl", models.KindDirectRef},
+		{"pkg @ git+https://github.com/user/pkg.git", models.KindDirectRef},
+		{"https://example.com/pkg.zip#egg=pkg", models.KindURL},
+		{"./libs/core", models.KindLocalPath},
+		{"-e .", models.KindLocalPath},
+		{"git+https://github.com/user/project.git#egg=project", models.KindVCS},
+		{"-e git+https://github.com/user/project.git#egg=project", models.KindVCS},
+		{"-r base.txt", models.KindInclude},
+		{"--requirement base.txt", models.KindInclude},
+		{"-c constraints.txt", models.KindConstraint},
+		{"--index-url https://pypi.example.com/simple", models.KindGlobalOption},
+	}
+
+	p := New()
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			req := p.parseLine(tt.line)
+			if req.Kind != tt.expected {
+				t.Errorf("parseLine(%q).Kind = %q, 期望 %q", tt.line, req.Kind, tt.expected)
+			}
+			// 显式Kind应与从布尔字段推断的结果一致
+			inferred := *req
+			inferred.Kind = ""
+			if got := inferred.EffectiveKind(); got != tt.expected {
+				t.Errorf("parseLine(%q)推断的Kind = %q, 期望 %q", tt.line, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package parser
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/scagogogo/python-requirements-parser/pkg/models"
+)
+
+// 本文件实现解析时的位置记录
+//
+// parseLine在扫描一个逻辑行时把各部分的位置记录到lineSpans中，偏移量相对于逻辑行；
+// parseText再根据每个物理行在文本中的位置，把它们转换为models.Span。
+// 只有启用RecordPositions时才会记录，默认的解析路径不会因此分配内存。
+
+// span 逻辑行中的一段区间[start, end)，end为0表示不存在
+type span struct {
+	start, end int
+}
+
+// lineSpans 一个逻辑行中各部分的位置
+type lineSpans struct {
+	name, extras, specifier, markers, comment span
+	options                                   []namedSpan
+	hashes                                    []span
+}
+
+// namedSpan 带选项名称的区间
+type namedSpan struct {
+	name string
+	span
+}
+
+// addOption 记录一个requirement选项的位置，spans为nil时不做任何事
+func (s *lineSpans) addOption(name string, start, end int) {
+	if s != nil {
+		s.options = append(s.options, namedSpan{name, span{start, end}})
+	}
+}
+
+// addHash 记录一个哈希选项的位置，spans为nil时不做任何事
+func (s *lineSpans) addHash(start, end int) {
+	if s != nil {
+		s.hashes = append(s.hashes, span{start, end})
+	}
+}
+
+// packageSpec 记录scanPackageSpec识别出的包名、extras和版本约束的位置
+//
+// s为传给scanPackageSpec的文本，offset为s在逻辑行中的位置。
+func (s *lineSpans) packageSpec(spec packageSpec, text string, offset int) {
+	if s == nil {
+		return
+	}
+
+	end := len(spec.name)
+	if spec.name != "" {
+		s.name = span{offset, offset + end}
+	}
+	if spec.hasExtras {
+		start := skipSpace(text, end)
+		end = start + len(spec.extras) + 2
+		s.extras = span{offset + start, offset + end}
+	}
+	if spec.version != "" {
+		start := skipSpace(text, end)
+		s.specifier = span{offset + start, offset + start + len(spec.version)}
+	}
+}
+
+// leadingSpace 返回s开头空白的长度，与strings.TrimSpace的规则一致
+func leadingSpace(s string) int {
+	return len(s) - len(strings.TrimLeftFunc(s, unicode.IsSpace))
+}
+
+// segment 逻辑行中的一个物理行
+type segment struct {
+	// start 该物理行在逻辑行中的起始位置
+	start int
+
+	// offset 该物理行在文本中的起始字节偏移
+	offset int
+
+	// line 物理行号（从1开始）
+	line int
+
+	// length 物理行的长度（包括行继续符，不包括行终止符）
+	length int
+}
+
+// positionMap 逻辑行到源文本位置的映射
+//
+// 逻辑行由物理行去掉末尾的行继续符后拼接而成，因此逻辑行中的每个位置
+// 都对应某个物理行中的一列。
+type positionMap []segment
+
+// locate 返回逻辑行中位置pos对应的行号、列号和字节偏移
+//
+// end为true时pos是区间的结束位置，恰好位于两个物理行交界处时映射到前一个物理行的末尾。
+func (m positionMap) locate(pos int, end bool) (line, column, offset int) {
+	seg := m[0]
+	for _, next := range m[1:] {
+		if pos < next.start || (end && pos == next.start) {
+			break
+		}
+		seg = next
+	}
+	column = pos - seg.start
+	return seg.line, column, seg.offset + column
+}
+
+// span 将逻辑行中的区间转换为models.Span
+func (m positionMap) span(s span) *models.Span {
+	if s.end == 0 {
+		return nil
+	}
+	result := &models.Span{}
+	result.Line, result.Column, result.Start = m.locate(s.start, false)
+	result.EndLine, result.EndColumn, result.End = m.locate(s.end, true)
+	return result
+}
+
+// positionInfo 根据记录的位置创建models.PositionInfo
+func (m positionMap) positionInfo(spans *lineSpans) *models.PositionInfo {
+	first := m[0]
+	info := &models.PositionInfo{
+		LineNumber: first.line,
+		EndColumn:  first.length,
+		Offset:     first.offset,
+		Name:       m.span(spans.name),
+		Extras:     m.span(spans.extras),
+		Specifier:  m.span(spans.specifier),
+		Markers:    m.span(spans.markers),
+		Comment:    m.span(spans.comment),
+	}
+
+	if info.Specifier != nil {
+		info.VersionStartColumn = info.Specifier.Column
+		info.VersionEndColumn = info.Specifier.EndColumn
+	}
+	if info.Comment != nil {
+		info.CommentStartColumn = info.Comment.Column
+	}
+
+	for _, option := range spans.options {
+		if info.Options == nil {
+			info.Options = make(map[string]models.Span, len(spans.options))
+		}
+		info.Options[option.name] = *m.span(option.span)
+	}
+	for _, hash := range spans.hashes {
+		info.Hashes = append(info.Hashes, *m.span(hash))
+	}
+
+	return info
+}
@@ -0,0 +1,216 @@
+package parser
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/scagogogo/python-requirements-parser/pkg/models"
+)
+
+// spanText 返回span在text中对应的文本，span为nil时返回空字符串
+func spanText(text string, span *models.Span) string {
+	if span == nil {
+		return ""
+	}
+	return text[span.Start:span.End]
+}
+
+func TestRecordPositions_Parts(t *testing.T) {
+	testCases := []struct {
+		name      string
+		input     string
+		wantName  string
+		extras    string
+		specifier string
+		markers   string
+		comment   string
+		options   map[string]string
+		hashes    []string
+	}{
+		{
+			name:      "带空白的完整包规格",
+			input:     "  flask [async] >= 1.0, <2 ; python_version >= '3.8'  # web",
+			wantName:  "flask",
+			extras:    "[async]",
+			specifier: ">= 1.0, <2",
+			markers:   "python_version >= '3.8'",
+			comment:   "# web",
+		},
+		{
+			name:      "选项和哈希",
+			input:     "numpy==1.21.0 --global-option build --no-deps --hash=sha256:abcd\t# math",
+			wantName:  "numpy",
+			specifier: "==1.21.0",
+			comment:   "# math",
+			options:   map[string]string{"global-option": "--global-option build", "no-deps": "--no-deps"},
+			hashes:    []string{"--hash=sha256:abcd"},
+		},
+		{
+			name:     "直接引用",
+			input:    "pkg [fast] @ https://example.com/pkg.whl --hash=sha256:aa",
+			wantName: "pkg",
+			extras:   "[fast]",
+			hashes:   []string{"--hash=sha256:aa"},
+		},
+		{
+			name:    "本地路径",
+			input:   "./libs/core[fast] --no-deps",
+			extras:  "[fast]",
+			options: map[string]string{"no-deps": "--no-deps"},
+		},
+		{
+			name:    "注释行",
+			input:   "   # section",
+			comment: "# section",
+		},
+		{
+			name:    "全局选项",
+			input:   "--index-url https://pypi.example.com/simple # mirror",
+			comment: "# mirror",
+		},
+		{
+			name:     "无效的哈希不记录位置",
+			input:    "flask --hash=sha256:XYZ",
+			wantName: "flask",
+		},
+	}
+
+	p := New()
+	p.RecordPositions = true
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reqs, err := p.ParseString(tc.input)
+			if err != nil || len(reqs) != 1 {
+				t.Fatalf("ParseString(%q) = %v, %v", tc.input, reqs, err)
+			}
+			info := reqs[0].PositionInfo
+			if info == nil {
+				t.Fatalf("PositionInfo不应为nil")
+			}
+
+			checks := []struct {
+				part string
+				span *models.Span
+				want string
+			}{
+				{"name", info.Name, tc.wantName},
+				{"extras", info.Extras, tc.extras},
+				{"specifier", info.Specifier, tc.specifier},
+				{"markers", info.Markers, tc.markers},
+				{"comment", info.Comment, tc.comment},
+			}
+			for _, c := range checks {
+				if got := spanText(tc.input, c.span); got != c.want {
+					t.Errorf("%s = %q, 期望 %q", c.part, got, c.want)
+				}
+			}
+
+			if len(info.Options) != len(tc.options) {
+				t.Errorf("options = %+v, 期望 %v", info.Options, tc.options)
+			}
+			for name, want := range tc.options {
+				span := info.Options[name]
+				if got := spanText(tc.input, &span); got != want {
+					t.Errorf("option %s = %q, 期望 %q", name, got, want)
+				}
+			}
+
+			if len(info.Hashes) != len(tc.hashes) || len(info.Hashes) != len(reqs[0].Hashes) {
+				t.Fatalf("hashes = %+v, 期望 %v", info.Hashes, tc.hashes)
+			}
+			for i, want := range tc.hashes {
+				if got := spanText(tc.input, &info.Hashes[i]); got != want {
+					t.Errorf("hash[%d] = %q, 期望 %q", i, got, want)
+				}
+			}
+
+			if info.Specifier != nil && (info.VersionStartColumn != info.Specifier.Column ||
+				info.VersionEndColumn != info.Specifier.EndColumn) {
+				t.Errorf("版本列号与specifier不一致: %+v", info)
+			}
+			if info.Comment != nil && info.CommentStartColumn != info.Comment.Column {
+				t.Errorf("注释列号与comment不一致: %+v", info)
+			}
+		})
+	}
+}
+
+func TestRecordPositions_Lines(t *testing.T) {
+	text := "flask==2.0.1\r\n" +
+		"flask==2.0.1\r\n" +
+		"django==3.2.0 \\\r\n" +
+		"    --hash=sha256:aaaa \\\r\n" +
+		"    --hash=sha256:bbbb\r\n" +
+		"pywin32 ; sys_platform == 'win32' \\\n" +
+		"    and python_version >= '3.8'"
+
+	p := New()
+	p.RecordPositions = true
+	reqs, err := p.ParseString(text)
+	if err != nil {
+		t.Fatalf("ParseString失败: %v", err)
+	}
+	if len(reqs) != 4 {
+		t.Fatalf("requirement数量 = %d, 期望 4", len(reqs))
+	}
+
+	// 相同的行各自记录自己的行号
+	for i, want := range []int{1, 2, 3, 6} {
+		if got := reqs[i].PositionInfo.LineNumber; got != want {
+			t.Errorf("reqs[%d]的行号 = %d, 期望 %d", i, got, want)
+		}
+	}
+	if reqs[1].PositionInfo.Offset != len("flask==2.0.1\r\n") {
+		t.Errorf("第二行的偏移 = %d", reqs[1].PositionInfo.Offset)
+	}
+	if reqs[0].PositionInfo.EndColumn != len("flask==2.0.1") {
+		t.Errorf("EndColumn不应包含行终止符: %d", reqs[0].PositionInfo.EndColumn)
+	}
+
+	// 续行中的哈希映射到各自的物理行
+	django := reqs[2].PositionInfo
+	for i, hash := range []string{"--hash=sha256:aaaa", "--hash=sha256:bbbb"} {
+		span := django.Hashes[i]
+		if text[span.Start:span.End] != hash || span.Line != 4+i || span.Column != 4 || span.EndColumn != 4+len(hash) {
+			t.Errorf("hash[%d]的位置不正确: %+v", i, span)
+		}
+	}
+
+	// 跨越行继续符的环境标记
+	markers := reqs[3].PositionInfo.Markers
+	if markers == nil || markers.Line != 6 || markers.EndLine != 7 ||
+		markers.EndColumn != len("    and python_version >= '3.8'") ||
+		!strings.HasPrefix(text[markers.Start:], "sys_platform") || markers.End != len(text) {
+		t.Errorf("环境标记的位置不正确: %+v", markers)
+	}
+}
+
+func TestRecordPositions_EnvironmentVariables(t *testing.T) {
+	os.Setenv("TEST_POSITION_VERSION", "2.0.1")
+	defer os.Unsetenv("TEST_POSITION_VERSION")
+
+	text := "flask==${TEST_POSITION_VERSION}  # web"
+	p := New()
+	p.RecordPositions = true
+	reqs, _ := p.ParseString(text)
+
+	req := reqs[0]
+	if req.Version != "==2.0.1" {
+		t.Errorf("Version = %q, 期望 %q", req.Version, "==2.0.1")
+	}
+	// 位置对应替换前的原始文本
+	if got := spanText(text, req.PositionInfo.Specifier); got != "==${TEST_POSITION_VERSION}" {
+		t.Errorf("specifier = %q", got)
+	}
+	if got := spanText(text, req.PositionInfo.Comment); got != "# web" {
+		t.Errorf("comment = %q", got)
+	}
+}
+
+func TestRecordPositions_Disabled(t *testing.T) {
+	reqs, _ := New().ParseString("flask==2.0.1")
+	if reqs[0].PositionInfo != nil {
+		t.Errorf("未启用RecordPositions时不应记录位置: %+v", reqs[0].PositionInfo)
+	}
+}
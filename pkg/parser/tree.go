@@ -0,0 +1,128 @@
+package parser
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/scagogogo/python-requirements-parser/pkg/models"
+)
+
+// IncludedFile 引用树中的一个requirements文件
+type IncludedFile struct {
+	// Path 文件路径，引用的文件为引用它的文件所在目录与"-r"路径的拼接
+	Path string
+
+	// Requirements 文件自身的解析结果，不包括引用文件中的内容
+	Requirements []*models.Requirement
+
+	// IncludedBy 引用该文件的"-r"行，根文件为nil
+	IncludedBy *models.Requirement
+}
+
+// IncludeError 引用树中"-r"引用的文件无法读取或解析
+type IncludeError struct {
+	// File 包含"-r"引用行的文件路径
+	File string
+
+	// Requirement "-r"引用行
+	Requirement *models.Requirement
+
+	// Err 读取或解析引用文件时的错误
+	Err error
+}
+
+// Error 返回错误描述
+func (e *IncludeError) Error() string {
+	return fmt.Sprintf("无法读取引用的文件 %s: %v", e.Requirement.FileRef, e.Err)
+}
+
+// Unwrap 返回底层错误
+func (e *IncludeError) Unwrap() error {
+	return e.Err
+}
+
+// ParseTree 解析path及其通过"-r"引用的所有文件
+//
+// 与RecursiveResolve把引用文件的内容合并到一个列表中不同，ParseTree分别返回每个文件的
+// 解析结果，调用方可以知道每个requirement来自哪个文件。文件按深度优先的顺序返回，
+// 与pip处理引用的顺序一致；每个文件只解析一次，因此循环引用不会导致无限递归。
+// URL形式的引用不会被下载，"-c"约束文件不会被加载。RecursiveResolve设置被忽略，
+// 其他设置（如RecordPositions）对每个文件都有效。
+//
+// 参数:
+//   - path: 根requirements文件的路径
+//
+// 返回:
+//   - []IncludedFile: 引用树中成功解析的文件，第一个为根文件
+//   - []*IncludeError: 无法读取或解析的引用文件，按遇到的顺序排列
+//   - error: 根文件无法读取或解析时返回错误
+//
+// 示例:
+//
+//	p := parser.New()
+//	files, missing, err := p.ParseTree("requirements/dev.txt")
+//	if err != nil {
+//	    // 处理错误
+//	}
+//	for _, file := range files {
+//	    fmt.Printf("%s: %d行\n", file.Path, len(file.Requirements))
+//	}
+//	for _, e := range missing {
+//	    fmt.Printf("%s: %v\n", e.File, e)
+//	}
+func (p *Parser) ParseTree(path string) ([]IncludedFile, []*IncludeError, error) {
+	single := *p
+	single.RecursiveResolve = false
+
+	root, err := single.ParseFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var files []IncludedFile
+	var missing []*IncludeError
+	visited := map[string]bool{treePath(path): true}
+
+	var walk func(file IncludedFile)
+	walk = func(file IncludedFile) {
+		files = append(files, file)
+		for _, req := range file.Requirements {
+			if !req.IsFileRef || isRemoteRef(req.FileRef) {
+				continue
+			}
+
+			ref := req.FileRef
+			if !filepath.IsAbs(ref) {
+				ref = filepath.Join(filepath.Dir(file.Path), ref)
+			}
+			if visited[treePath(ref)] {
+				continue
+			}
+			visited[treePath(ref)] = true
+
+			included, err := single.ParseFile(ref)
+			if err != nil {
+				missing = append(missing, &IncludeError{File: file.Path, Requirement: req, Err: err})
+				continue
+			}
+			walk(IncludedFile{Path: ref, Requirements: included, IncludedBy: req})
+		}
+	}
+	walk(IncludedFile{Path: path, Requirements: root})
+
+	return files, missing, nil
+}
+
+// treePath 返回用于判断是否为同一文件的绝对路径
+func treePath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+// isRemoteRef 检查"-r"引用是否为远程URL
+func isRemoteRef(ref string) bool {
+	return strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://")
+}
@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseTree(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"requirements.txt": "-r common/base.txt\n-r https://example.com/remote.txt\n-c constraints.txt\nflask==2.0.1\n-r missing.txt\n",
+		"common/base.txt":  "requests\n-r ../requirements.txt\n-r extra.txt\n",
+		"common/extra.txt": "six\n",
+		"constraints.txt":  "six==1.16.0\n",
+		"unreferenced.txt": "numpy\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := NewWithRecursiveResolve()
+	p.RecordPositions = true
+	root := filepath.Join(dir, "requirements.txt")
+	tree, missing, err := p.ParseTree(root)
+	if err != nil {
+		t.Fatalf("ParseTree失败: %v", err)
+	}
+
+	var paths []string
+	for _, file := range tree {
+		rel, _ := filepath.Rel(dir, file.Path)
+		paths = append(paths, filepath.ToSlash(rel))
+	}
+	if got := strings.Join(paths, ","); got != "requirements.txt,common/base.txt,common/extra.txt" {
+		t.Errorf("文件顺序 = %s", got)
+	}
+
+	// RecursiveResolve被忽略，每个文件只包含自身的内容
+	if len(tree[0].Requirements) != 5 || tree[0].IncludedBy != nil {
+		t.Errorf("根文件 = %d行, IncludedBy = %v", len(tree[0].Requirements), tree[0].IncludedBy)
+	}
+	if tree[1].IncludedBy != tree[0].Requirements[0] || tree[2].IncludedBy != tree[1].Requirements[2] {
+		t.Errorf("IncludedBy没有指向引用行")
+	}
+	if tree[2].Requirements[0].PositionInfo == nil {
+		t.Errorf("引用文件没有使用解析器的RecordPositions设置")
+	}
+
+	if len(missing) != 1 {
+		t.Fatalf("missing = %v", missing)
+	}
+	if e := missing[0]; e.File != root || e.Requirement.FileRef != "missing.txt" || !errors.Is(e, os.ErrNotExist) {
+		t.Errorf("missing[0] = %+v", e)
+	}
+}
+
+func TestParseTreeMissingRoot(t *testing.T) {
+	if _, _, err := New().ParseTree(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Errorf("根文件不存在时应返回错误")
+	}
+}
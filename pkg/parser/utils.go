@@ -23,6 +23,9 @@ import (
 //	result := processEnvironmentVariables("flask==${VERSION}")
 //	// 返回: "flask==1.2.3"
 func (p *Parser) processEnvironmentVariables(input string) string {
+	if !strings.Contains(input, "${") {
+		return input
+	}
 	return envVarRegex.ReplaceAllStringFunc(input, func(match string) string {
 		// 提取变量名（去掉${}）
 		varName := match[2 : len(match)-1]
@@ -0,0 +1,187 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/scagogogo/python-requirements-parser/pkg/cst"
+	"github.com/scagogogo/python-requirements-parser/pkg/models"
+	"github.com/scagogogo/python-requirements-parser/pkg/parser"
+)
+
+// digestLengths pip接受的哈希算法及其十六进制摘要的长度
+var digestLengths = map[string]int{
+	"sha256": 64,
+	"sha384": 96,
+	"sha512": 128,
+}
+
+// CheckHashes 检查一个requirements文件的解析结果是否满足pip的哈希检查模式
+//
+// 文件中出现"--require-hashes"，或任一requirement带有哈希时，pip进入哈希检查模式，
+// 此时每个可安装的requirement都必须：
+//   - 不是可编辑安装（"hash-editable"）；
+//   - 不是VCS引用或本地目录，它们无法计算哈希（"hash-unhashable"）；
+//   - 按包名引用时用==或===固定到精确版本（"hash-unpinned"）；
+//   - 至少带有一个哈希（"hash-missing"）。
+//
+// 无论是否处于哈希检查模式，每个"--hash"选项都会被检查：
+//   - 格式必须为"算法:小写十六进制摘要"（"invalid-hash"）；
+//   - 算法必须是sha256、sha384或sha512（"invalid-hash-algorithm"）；
+//   - 摘要长度必须与算法一致（"invalid-hash-length"）。
+//
+// 所有问题都报告为错误。行号来自PositionInfo，需要时使用启用了RecordPositions的解析器；
+// 续行中的哈希报告其所在的物理行。
+//
+// 示例:
+//
+//	p := parser.New()
+//	p.RecordPositions = true
+//	reqs, _ := p.ParseString("--require-hashes\nflask==2.3.2\n")
+//	diagnostics := validator.CheckHashes(reqs)
+//	// diagnostics[0].Code == "hash-missing", diagnostics[0].Line == 2
+func CheckHashes(reqs []*models.Requirement) []models.Diagnostic {
+	return checkHashes([]parser.IncludedFile{{Requirements: reqs}})
+}
+
+// CheckHashesFile 检查requirements文件及其通过"-r"引用的所有文件是否满足哈希检查模式
+//
+// 与pip一致，任一文件中的"--require-hashes"或哈希都会使整个引用树进入哈希检查模式。
+// 诊断信息的File字段为问题所在文件的路径，引用的文件路径相对于引用它的文件所在目录。
+// 约束文件（"-c"）中的requirement不会被安装，因此不做检查。
+//
+// 参数:
+//   - path: 根requirements文件的路径
+//
+// 返回:
+//   - []models.Diagnostic: 发现的问题，按文件和行的顺序排列；引用的文件无法读取时为"include-not-found"
+//   - error: 根文件无法读取或解析时返回错误
+func CheckHashesFile(path string) ([]models.Diagnostic, error) {
+	files, diagnostics, err := loadTree(path)
+	if err != nil {
+		return nil, err
+	}
+	return append(diagnostics, checkHashes(files)...), nil
+}
+
+// checkHashes 检查引用树中所有文件的哈希
+func checkHashes(files []parser.IncludedFile) []models.Diagnostic {
+	reason := hashModeReason(files)
+
+	var diagnostics []models.Diagnostic
+	for _, file := range files {
+		report := func(line int, code, message string) {
+			diagnostics = append(diagnostics, models.Diagnostic{
+				Severity: models.SeverityError,
+				Code:     code,
+				Message:  message,
+				Line:     line,
+				File:     file.Path,
+			})
+		}
+
+		for _, req := range file.Requirements {
+			checkHashOptions(req, report)
+			if reason != "" && req.IsInstallable() {
+				checkHashMode(req, reason, report)
+			}
+		}
+	}
+	return diagnostics
+}
+
+// hashModeReason 返回进入哈希检查模式的原因，不需要哈希检查时返回空字符串
+func hashModeReason(files []parser.IncludedFile) string {
+	var hashed string
+	for _, file := range files {
+		for _, req := range file.Requirements {
+			if req.EffectiveKind() == models.KindGlobalOption && req.GlobalOptions["require-hashes"] == "true" {
+				return "--require-hashes模式下"
+			}
+			if hashed == "" && len(req.Hashes) > 0 {
+				hashed = fmt.Sprintf("%s 带有哈希，哈希检查模式下", requirementName(req))
+			}
+		}
+	}
+	return hashed
+}
+
+// checkHashMode 按哈希检查模式的规则检查一个可安装的requirement
+func checkHashMode(req *models.Requirement, reason string, report func(line int, code, message string)) {
+	line := lineNumber(req)
+	name := requirementName(req)
+
+	switch {
+	case req.IsEditable:
+		report(line, "hash-editable", fmt.Sprintf("%s不能使用可编辑安装: %s", reason, name))
+		return
+	case req.IsVCS:
+		report(line, "hash-unhashable", fmt.Sprintf("%s无法校验VCS引用的哈希: %s", reason, name))
+		return
+	case req.IsLocalPath && isDir(localPath(req)):
+		report(line, "hash-unhashable", fmt.Sprintf("%s无法校验本地目录的哈希: %s", reason, name))
+		return
+	}
+
+	if !req.IsURL && !req.IsLocalPath && !models.IsExactPin(req.Version) {
+		report(line, "hash-unpinned", fmt.Sprintf("%s所有requirement都必须用==固定版本: %s", reason, name))
+	}
+	if len(req.Hashes) == 0 {
+		report(line, "hash-missing", fmt.Sprintf("%s所有requirement都必须带有哈希: %s", reason, name))
+	}
+}
+
+// checkHashOptions 检查requirement中每个"--hash"选项的格式、算法和摘要长度
+//
+// 解析器会丢弃格式错误的哈希，因此这里用cst从原始行中重新提取全部"--hash"选项
+// （包括"--hash=值"和"--hash 值"两种写法），注释的识别规则与解析器一致。
+func checkHashOptions(req *models.Requirement, report func(line int, code, message string)) {
+	if !req.IsInstallable() {
+		return
+	}
+
+	valid := 0
+	for _, hash := range cst.ParseLine(req.OriginalLine).Hashes() {
+		line := lineNumber(req)
+		if !parser.ValidHash(hash.Value) {
+			report(line, "invalid-hash", fmt.Sprintf("无效的哈希 %q，格式应为\"算法:小写十六进制摘要\"", hash.Value))
+			continue
+		}
+
+		// 格式正确的哈希与PositionInfo.Hashes一一对应
+		if info := req.PositionInfo; info != nil && valid < len(info.Hashes) {
+			line = info.Hashes[valid].Line
+		}
+		valid++
+
+		algorithm, digest := hash.Algorithm(), hash.Digest()
+		length, ok := digestLengths[algorithm]
+		switch {
+		case !ok:
+			report(line, "invalid-hash-algorithm", fmt.Sprintf("不支持的哈希算法 %q，pip只接受sha256、sha384和sha512", algorithm))
+		case len(digest) != length:
+			report(line, "invalid-hash-length", fmt.Sprintf("%s摘要的长度应为%d，实际为%d: %s", algorithm, length, len(digest), hash.Value))
+		}
+	}
+}
+
+// localPath 返回本地路径requirement的实际路径
+func localPath(req *models.Requirement) string {
+	if req.ResolvedPath != "" {
+		return req.ResolvedPath
+	}
+	return req.LocalPath
+}
+
+// requirementName 返回用于诊断信息的requirement名称
+func requirementName(req *models.Requirement) string {
+	switch {
+	case req.Name != "":
+		return req.Name
+	case req.IsLocalPath && req.LocalPath != "":
+		return req.LocalPath
+	case req.URL != "":
+		return req.URL
+	}
+	return strings.TrimSpace(req.OriginalLine)
+}
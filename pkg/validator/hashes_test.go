@@ -0,0 +1,153 @@
+package validator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/scagogogo/python-requirements-parser/pkg/models"
+	"github.com/scagogogo/python-requirements-parser/pkg/parser"
+)
+
+var (
+	sha256Hash = "sha256:" + strings.Repeat("a", 64)
+	sha512Hash = "sha512:" + strings.Repeat("b", 128)
+)
+
+// summarize 返回诊断信息的"文件:行号:代码"列表，文件只保留文件名
+func summarize(diagnostics []models.Diagnostic) string {
+	var result []string
+	for _, d := range diagnostics {
+		if d.Severity != models.SeverityError {
+			result = append(result, "non-error")
+		}
+		entry := fmt.Sprintf("%d:%s", d.Line, d.Code)
+		if d.File != "" {
+			entry = filepath.Base(d.File) + ":" + entry
+		}
+		result = append(result, entry)
+	}
+	return strings.Join(result, ",")
+}
+
+func TestCheckHashes(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "没有哈希时不检查",
+			input:    "flask>=2.0\n-e ./src\n",
+			expected: "",
+		},
+		{
+			name:     "完整的哈希文件",
+			input:    "--require-hashes\nflask==2.3.2 --hash=" + sha256Hash + "\npkg @ https://example.com/pkg-1.0.tar.gz --hash=" + sha512Hash + "\n",
+			expected: "",
+		},
+		{
+			name: "require-hashes模式",
+			input: "--require-hashes\n" +
+				"# comment\n" +
+				"flask==2.3.2\n" +
+				"requests>=2.31 --hash=" + sha256Hash + "\n" +
+				"numpy==1.* --hash=" + sha256Hash + "\n" +
+				"-e ./src\n" +
+				"git+https://github.com/user/project.git\n" +
+				dir + "\n" +
+				"six===1.16.0 --hash=" + sha256Hash + "\n",
+			expected: "3:hash-missing,4:hash-unpinned,5:hash-unpinned,6:hash-editable,7:hash-unhashable,8:hash-unhashable",
+		},
+		{
+			name:     "任一哈希启用哈希检查模式",
+			input:    "flask==2.3.2 --hash=" + sha256Hash + "\nrequests==2.31.0\n",
+			expected: "2:hash-missing",
+		},
+		{
+			name: "哈希的格式、算法和长度",
+			input: "flask==2.3.2 \\\n" +
+				"    --hash=sha256:ABCD \\\n" +
+				"    --hash=md5:0123456789abcdef0123456789abcdef \\\n" +
+				"    --hash=sha256:abcd \\\n" +
+				"    --hash=" + sha256Hash + "  # --hash=sha1:ignored\n",
+			expected: "1:invalid-hash,3:invalid-hash-algorithm,4:invalid-hash-length",
+		},
+		{
+			name: "空格分隔的哈希",
+			input: "--require-hashes\n" +
+				"flask==2.3.2 --hash " + sha256Hash + "\n" +
+				"requests==2.31.0 \\\n" +
+				"    --hash sha256:abcd\n",
+			expected: "4:invalid-hash-length",
+		},
+		{
+			name:     "引号中的#不是注释",
+			input:    "flask==2.3.2 --global-option=\"--x #y\" --hash=sha256:abcd --hash=" + sha256Hash + "\n",
+			expected: "1:invalid-hash-length",
+		},
+	}
+
+	p := parser.New()
+	p.RecordPositions = true
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reqs, err := p.ParseString(tt.input)
+			if err != nil {
+				t.Fatalf("解析失败: %v", err)
+			}
+			if got := summarize(CheckHashes(reqs)); got != tt.expected {
+				t.Errorf("CheckHashes = %s, 期望 %s", got, tt.expected)
+			}
+		})
+	}
+
+	reqs, _ := parser.New().ParseString("--require-hashes\nflask==2.3.2\n")
+	if got := summarize(CheckHashes(reqs)); got != "0:hash-missing" {
+		t.Errorf("未记录位置时 = %s", got)
+	}
+}
+
+func TestCheckHashesFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	root := write("requirements.txt", "-r reqs/base.txt\n"+
+		"-r missing.txt\n"+
+		"-c constraints.txt\n"+
+		"flask==2.3.2 --hash="+sha256Hash+"\n")
+	write("reqs/base.txt", "# base\n--require-hashes\n-r ../requirements.txt\n-r common.txt\nrequests==2.31.0\n")
+	write("reqs/common.txt", "six>=1.16\n")
+	write("constraints.txt", "urllib3<2\n")
+
+	diagnostics, err := CheckHashesFile(root)
+	if err != nil {
+		t.Fatalf("CheckHashesFile失败: %v", err)
+	}
+	expected := "requirements.txt:2:include-not-found," +
+		"base.txt:5:hash-missing," +
+		"common.txt:1:hash-unpinned,common.txt:1:hash-missing"
+	if got := summarize(diagnostics); got != expected {
+		t.Errorf("CheckHashesFile = %s, 期望 %s", got, expected)
+	}
+	if !strings.Contains(diagnostics[1].Message, "--require-hashes") {
+		t.Errorf("诊断信息应说明哈希检查模式的来源: %s", diagnostics[1].Message)
+	}
+
+	if _, err := CheckHashesFile(filepath.Join(dir, "nope.txt")); err == nil {
+		t.Errorf("根文件不存在时应返回错误")
+	}
+}
@@ -0,0 +1,52 @@
+// Package validator 检查requirements文件是否符合pip的规则
+//
+// 校验器在解析结果上工作，报告带有行号的models.Diagnostic。
+// 按文件校验时会沿"-r"引用遍历整个引用树，诊断信息的File字段给出问题所在的文件。
+package validator
+
+import (
+	"os"
+
+	"github.com/scagogogo/python-requirements-parser/pkg/models"
+	"github.com/scagogogo/python-requirements-parser/pkg/parser"
+)
+
+// loadTree 解析path及其通过"-r"引用的所有文件
+//
+// 文件的遍历规则见parser.Parser.ParseTree。引用的文件无法读取时在引用行上报告
+// "include-not-found"错误。
+func loadTree(path string) ([]parser.IncludedFile, []models.Diagnostic, error) {
+	p := parser.New()
+	p.RecordPositions = true
+
+	files, missing, err := p.ParseTree(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var diagnostics []models.Diagnostic
+	for _, e := range missing {
+		diagnostics = append(diagnostics, models.Diagnostic{
+			Severity: models.SeverityError,
+			Code:     "include-not-found",
+			Message:  e.Error(),
+			Line:     lineNumber(e.Requirement),
+			File:     e.File,
+		})
+	}
+	return files, diagnostics, nil
+}
+
+// lineNumber 返回requirement所在的行号，没有位置信息时返回0
+func lineNumber(req *models.Requirement) int {
+	if req.PositionInfo != nil {
+		return req.PositionInfo.LineNumber
+	}
+	return 0
+}
+
+// isDir 检查路径是否为已存在的目录
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}